@@ -0,0 +1,402 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+const (
+	// defaultAdminPageSize is the number of sessions listed when no limit is given
+	defaultAdminPageSize = 50
+	// maxAdminPageSize caps the number of sessions listed at once
+	maxAdminPageSize = 500
+)
+
+// setupAdminRoutes registers the admin API and page. They are only
+// available when an admin token is configured.
+func (s *Server) setupAdminRoutes() {
+	if s.config.AdminToken == "" {
+		return
+	}
+
+	s.router.Handle("/admin", http.RedirectHandler("/admin/", http.StatusMovedPermanently))
+
+	admin := s.router.PathPrefix("/admin").Subrouter()
+	admin.Use(adminMiddleware(s.config.AdminToken))
+
+	// JSON API
+	admin.HandleFunc("/api/sessions", s.AdminListSessionsHandler).Methods("GET")
+	admin.HandleFunc("/api/sessions/{id}", s.AdminInspectSessionHandler).Methods("GET")
+	admin.HandleFunc("/api/sessions/{id}", s.AdminDeleteSessionHandler).Methods("DELETE")
+	admin.HandleFunc("/api/sessions/{id}/end", s.AdminEndSessionHandler).Methods("POST")
+	admin.HandleFunc("/api/cleanup", s.AdminCleanupHandler).Methods("POST")
+	admin.HandleFunc("/api/export", s.AdminExportHandler).Methods("GET")
+
+	// HTML page
+	admin.HandleFunc("/", s.AdminPageHandler).Methods("GET")
+	admin.HandleFunc("/sessions/{id}", s.AdminSessionPageHandler).Methods("GET")
+	admin.HandleFunc("/sessions/{id}/end", s.AdminPageActionHandler).Methods("POST")
+	admin.HandleFunc("/sessions/{id}/delete", s.AdminPageActionHandler).Methods("POST")
+	admin.HandleFunc("/cleanup", s.AdminPageActionHandler).Methods("POST")
+}
+
+// adminMiddleware requires the admin token, either as a bearer token or as
+// the password of HTTP basic auth so the page can be used from a browser.
+// Form posts from other origins are rejected.
+func adminMiddleware(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			supplied, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				_, supplied, _ = r.BasicAuth()
+			}
+			if supplied == "" || subtle.ConstantTimeCompare([]byte(supplied), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="scoundrel admin"`)
+				http.Error(w, "Admin token required", http.StatusUnauthorized)
+				return
+			}
+
+			if r.Method != http.MethodGet && !sameOrigin(r) {
+				http.Error(w, "Cross-origin admin request rejected", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// sameOrigin reports whether a request carries no Origin header or one
+// matching its host
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// parseSessionFilter reads the session filter and page from query parameters
+func parseSessionFilter(q url.Values) (game.SessionFilter, int, int, error) {
+	filter := game.SessionFilter{
+		State:   q.Get("state"),
+		OwnerID: q.Get("owner"),
+	}
+
+	var err error
+	if v := q.Get("min_age"); v != "" {
+		if filter.MinAge, err = time.ParseDuration(v); err != nil {
+			return filter, 0, 0, fmt.Errorf("invalid min_age %q", v)
+		}
+	}
+	if v := q.Get("max_age"); v != "" {
+		if filter.MaxAge, err = time.ParseDuration(v); err != nil {
+			return filter, 0, 0, fmt.Errorf("invalid max_age %q", v)
+		}
+	}
+
+	limit := defaultAdminPageSize
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxAdminPageSize {
+			return filter, 0, 0, fmt.Errorf("limit must be between 1 and %d", maxAdminPageSize)
+		}
+	}
+	offset := 0
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return filter, 0, 0, fmt.Errorf("invalid offset %q", v)
+		}
+	}
+
+	return filter, offset, limit, nil
+}
+
+// AdminListSessionsHandler lists sessions filtered by state, owner and age, a page at a time
+func (s *Server) AdminListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, offset, limit, err := parseSessionFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sessions, total := s.sessionManager.ListSessions(filter, offset, limit)
+
+	response := map[string]interface{}{
+		"total":    total,
+		"offset":   offset,
+		"limit":    limit,
+		"sessions": sessions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// AdminInspectSessionHandler returns the full internal state of a session, including the deck order
+func (s *Server) AdminInspectSessionHandler(w http.ResponseWriter, r *http.Request) {
+	inspection, err := s.sessionManager.Inspect(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inspection)
+}
+
+// AdminEndSessionHandler forces a game in progress to end as lost
+func (s *Server) AdminEndSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := s.endSession(sessionID); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"game_id": sessionID, "ended": true})
+}
+
+// AdminDeleteSessionHandler removes a session
+func (s *Server) AdminDeleteSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := s.deleteSession(sessionID); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminCleanupHandler runs a reaper sweep immediately
+func (s *Server) AdminCleanupHandler(w http.ResponseWriter, r *http.Request) {
+	removed := s.cleanup()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"removed": removed})
+}
+
+// endSession forces a game to end and logs it
+func (s *Server) endSession(sessionID string) error {
+	if err := s.sessionManager.EndSession(sessionID); err != nil {
+		return err
+	}
+	s.metrics.recordForcedEnd()
+	s.logger.Info("admin ended session", "game_id", sessionID)
+	return nil
+}
+
+// deleteSession removes a session and logs it
+func (s *Server) deleteSession(sessionID string) error {
+	if _, err := s.sessionManager.GetSession(sessionID); err != nil {
+		return err
+	}
+	s.sessionManager.DeleteSession(sessionID)
+	s.logger.Info("admin deleted session", "game_id", sessionID)
+	return nil
+}
+
+// cleanup runs a reaper sweep and counts the evictions
+func (s *Server) cleanup() int {
+	removed := s.sessionManager.CleanupSessions(s.config.SessionMaxAge)
+	s.metrics.recordEvictions(removed)
+	s.logger.Info("admin triggered cleanup", "removed", removed)
+	return removed
+}
+
+// writeAdminError maps a session manager error to a response
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, game.ErrSessionNotFound):
+		http.Error(w, "Game session not found", http.StatusNotFound)
+	case errors.Is(err, game.ErrGameOver):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// adminPageData is rendered by the admin list page
+type adminPageData struct {
+	Sessions []game.SessionSummary
+	Total    int
+	States   []string
+	Query    url.Values
+	Prev     string
+	Next     string
+	Message  string
+}
+
+// adminSessionPageData is rendered by the admin session page
+type adminSessionPageData struct {
+	Inspection game.Inspection
+	JSON       string
+}
+
+// AdminPageHandler renders the session list
+func (s *Server) AdminPageHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, offset, limit, err := parseSessionFilter(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sessions, total := s.sessionManager.ListSessions(filter, offset, limit)
+	data := adminPageData{
+		Sessions: sessions,
+		Total:    total,
+		States:   []string{"Initial", "InProgress", "Won", "Lost"},
+		Query:    q,
+		Message:  q.Get("message"),
+	}
+	if offset > 0 {
+		data.Prev = pageURL(q, max(offset-limit, 0))
+	}
+	if offset+limit < total {
+		data.Next = pageURL(q, offset+limit)
+	}
+
+	renderAdmin(w, adminListTemplate, data)
+}
+
+// AdminSessionPageHandler renders the full state of one session
+func (s *Server) AdminSessionPageHandler(w http.ResponseWriter, r *http.Request) {
+	inspection, err := s.sessionManager.Inspect(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
+
+	raw, err := json.MarshalIndent(inspection, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	renderAdmin(w, adminSessionTemplate, adminSessionPageData{Inspection: inspection, JSON: string(raw)})
+}
+
+// AdminPageActionHandler performs a form action from the admin page and
+// redirects back to the list
+func (s *Server) AdminPageActionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+
+	var message string
+	var err error
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/end"):
+		err = s.endSession(sessionID)
+		message = "Ended " + sessionID
+	case strings.HasSuffix(r.URL.Path, "/delete"):
+		err = s.deleteSession(sessionID)
+		message = "Deleted " + sessionID
+	default:
+		message = fmt.Sprintf("Cleanup removed %d sessions", s.cleanup())
+	}
+	if err != nil {
+		message = err.Error()
+	}
+
+	http.Redirect(w, r, "/admin/?message="+url.QueryEscape(message), http.StatusSeeOther)
+}
+
+// pageURL returns the admin list URL for another page of the same query
+func pageURL(q url.Values, offset int) string {
+	page := url.Values{}
+	for key, values := range q {
+		if key != "message" {
+			page[key] = values
+		}
+	}
+	page.Set("offset", strconv.Itoa(offset))
+	return "/admin/?" + page.Encode()
+}
+
+// renderAdmin writes an admin page
+func renderAdmin(w http.ResponseWriter, tmpl *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+const adminLayout = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Scoundrel admin</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+form.inline { display: inline; }
+pre { background: #f4f4f4; padding: 1em; }
+</style>
+</head>
+<body>
+<h1><a href="/admin/">Scoundrel admin</a></h1>
+{{template "content" .}}
+</body>
+</html>`
+
+var adminListTemplate = template.Must(template.Must(template.New("layout").Parse(adminLayout)).Parse(`{{define "content"}}
+{{with .Message}}<p><strong>{{.}}</strong></p>{{end}}
+<form method="get" action="/admin/">
+  State <select name="state">
+    <option value="">any</option>
+    {{range $s := .States}}
+    <option value="{{$s}}" {{if eq ($.Query.Get "state") $s}}selected{{end}}>{{$s}}</option>
+    {{end}}
+  </select>
+  Owner <input name="owner" value="{{.Query.Get "owner"}}">
+  Min age <input name="min_age" value="{{.Query.Get "min_age"}}" placeholder="30m" size="6">
+  Max age <input name="max_age" value="{{.Query.Get "max_age"}}" placeholder="2h" size="6">
+  <button type="submit">Filter</button>
+</form>
+<form method="post" action="/admin/cleanup"><button type="submit">Run cleanup now</button></form>
+<p>{{.Total}} sessions</p>
+<table>
+<tr><th>Game</th><th>State</th><th>Owner</th><th>Health</th><th>Cards left</th><th>Moves</th><th>Started</th><th>Last active</th><th></th></tr>
+{{range .Sessions}}
+<tr>
+  <td><a href="/admin/sessions/{{.GameID}}">{{.GameID}}</a></td>
+  <td>{{.State}}</td>
+  <td>{{.OwnerID}}</td>
+  <td>{{.Health}}</td>
+  <td>{{.RemainingCards}}</td>
+  <td>{{.Moves}}</td>
+  <td>{{.StartedAt.Format "2006-01-02 15:04:05"}}</td>
+  <td>{{.LastActiveAt.Format "2006-01-02 15:04:05"}}</td>
+  <td>
+    <form class="inline" method="post" action="/admin/sessions/{{.GameID}}/end"><button type="submit">End</button></form>
+    <form class="inline" method="post" action="/admin/sessions/{{.GameID}}/delete"><button type="submit">Delete</button></form>
+  </td>
+</tr>
+{{end}}
+</table>
+<p>{{with .Prev}}<a href="{{.}}">Previous</a>{{end}} {{with .Next}}<a href="{{.}}">Next</a>{{end}}</p>
+{{end}}`))
+
+var adminSessionTemplate = template.Must(template.Must(template.New("layout").Parse(adminLayout)).Parse(`{{define "content"}}
+<h2>Game {{.Inspection.GameID}}</h2>
+<p>{{.Inspection.State}}, score {{.Inspection.Score}}, {{len .Inspection.Actions}} moves, {{len .Inspection.DeckOrder}} cards in the deck</p>
+<form class="inline" method="post" action="/admin/sessions/{{.Inspection.GameID}}/end"><button type="submit">End</button></form>
+<form class="inline" method="post" action="/admin/sessions/{{.Inspection.GameID}}/delete"><button type="submit">Delete</button></form>
+<h3>Deck order</h3>
+<p>{{range .Inspection.DeckOrder}}{{.Display}} {{end}}</p>
+<h3>Full state</h3>
+<pre>{{.JSON}}</pre>
+{{end}}`))
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testAdminToken = "admin-token-for-tests"
+
+func TestAdminSessions(t *testing.T) {
+	config := testConfig("")
+	config.AdminToken = testAdminToken
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var created, other struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+	doRequest(t, s, "POST", "/api/games", "", "", &other)
+
+	// The admin token is required, and player tokens are not enough
+	if code := doRequest(t, s, "GET", "/admin/api/sessions", "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without admin token, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", "/admin/api/sessions", "wrong", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with wrong admin token, got %d", code)
+	}
+
+	var list struct {
+		Total    int `json:"total"`
+		Sessions []struct {
+			GameID string `json:"game_id"`
+		} `json:"sessions"`
+	}
+	if code := doRequest(t, s, "GET", "/admin/api/sessions?state=InProgress&limit=1", testAdminToken, "", &list); code != http.StatusOK {
+		t.Fatalf("Expected 200 listing sessions, got %d", code)
+	}
+	if list.Total != 2 || len(list.Sessions) != 1 {
+		t.Errorf("Expected a page of 1 out of 2 sessions, got %d of %d", len(list.Sessions), list.Total)
+	}
+
+	var inspection struct {
+		DeckOrder []struct {
+			Display string `json:"display"`
+		} `json:"deck_order"`
+	}
+	doRequest(t, s, "GET", "/admin/api/sessions/"+created.GameID, testAdminToken, "", &inspection)
+	if len(inspection.DeckOrder) == 0 {
+		t.Error("Expected inspection to include the deck order")
+	}
+
+	if code := doRequest(t, s, "POST", "/admin/api/sessions/"+created.GameID+"/end", testAdminToken, "", nil); code != http.StatusOK {
+		t.Errorf("Expected 200 ending session, got %d", code)
+	}
+	if code := doRequest(t, s, "POST", "/admin/api/sessions/"+created.GameID+"/end", testAdminToken, "", nil); code != http.StatusConflict {
+		t.Errorf("Expected 409 ending a finished session, got %d", code)
+	}
+
+	var cleanup struct {
+		Removed int `json:"removed"`
+	}
+	doRequest(t, s, "POST", "/admin/api/cleanup", testAdminToken, "", &cleanup)
+	if cleanup.Removed != 1 {
+		t.Errorf("Expected cleanup to remove the ended session, got %d", cleanup.Removed)
+	}
+
+	if code := doRequest(t, s, "DELETE", "/admin/api/sessions/"+other.GameID, testAdminToken, "", nil); code != http.StatusNoContent {
+		t.Errorf("Expected 204 deleting session, got %d", code)
+	}
+	if code := doRequest(t, s, "DELETE", "/admin/api/sessions/"+other.GameID, testAdminToken, "", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting a deleted session, got %d", code)
+	}
+}
+
+func TestAdminPage(t *testing.T) {
+	config := testConfig("")
+	config.AdminToken = testAdminToken
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+
+	req := httptest.NewRequest("GET", "/admin/", nil)
+	req.SetBasicAuth("support", testAdminToken)
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for admin page, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), created.GameID) {
+		t.Error("Expected admin page to list the game")
+	}
+
+	// Form posts from another origin are rejected
+	req = httptest.NewRequest("POST", "/admin/cleanup", nil)
+	req.SetBasicAuth("support", testAdminToken)
+	req.Header.Set("Origin", "https://evil.example")
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for cross-origin admin post, got %d", rec.Code)
+	}
+}
+
+func TestAdminDisabledWithoutToken(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	if code := doRequest(t, s, "GET", "/admin/api/sessions", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 when admin is disabled, got %d", code)
+	}
+}
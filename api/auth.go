@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/auth"
+)
+
+// contextKey is the type of request context keys set by this package
+type contextKey int
+
+const (
+	playerContextKey contextKey = iota
+	requestIDContextKey
+)
+
+// credentialsRequest is the body of register and login requests
+type credentialsRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// authMiddleware resolves the bearer token of a request to a player.
+// Requests without a token pass through anonymously; requests with an
+// unknown token are rejected.
+func authMiddleware(players *auth.Store) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(r)
+			if !ok {
+				http.Error(w, "Authorization header must use the Bearer scheme", http.StatusUnauthorized)
+				return
+			}
+
+			player, err := players.Authenticate(token)
+			if err != nil {
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), playerContextKey, player)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bearerToken returns the token of a request's Bearer authorization header
+func bearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token), ok
+}
+
+// playerFromContext returns the authenticated player of a request, if any
+func playerFromContext(ctx context.Context) *auth.Player {
+	player, _ := ctx.Value(playerContextKey).(*auth.Player)
+	return player
+}
+
+// playerIDFromContext returns the ID of the authenticated player of a request, or ""
+func playerIDFromContext(ctx context.Context) string {
+	if player := playerFromContext(ctx); player != nil {
+		return player.ID
+	}
+	return ""
+}
+
+// RegisterHandler creates a player account and returns an API token
+func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	player, token, err := h.players.Register(req.Name, req.Password)
+	switch {
+	case errors.Is(err, auth.ErrNameTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, auth.ErrInvalidName), errors.Is(err, auth.ErrWeakPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to register player", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, http.StatusCreated, player, token)
+}
+
+// LoginHandler exchanges a name and password for a new API token
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req credentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	player, token, err := h.players.Login(req.Name, req.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to log in", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, http.StatusOK, player, token)
+}
+
+// LogoutHandler revokes the bearer token of the request
+func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	token, ok := bearerToken(r)
+	if !ok || playerFromContext(r.Context()) == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.players.Logout(token); err != nil {
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MyGamesHandler lists the games owned by the authenticated player
+func (h *Handler) MyGamesHandler(w http.ResponseWriter, r *http.Request) {
+	player := playerFromContext(r.Context())
+	if player == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	response := map[string]interface{}{
+		"player_id": player.ID,
+		"games":     h.sessionManager.SessionsByOwner(player.ID),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// authorizeMove checks that the requester may make moves in a game and writes
+// an error response if not
+func (h *Handler) authorizeMove(w http.ResponseWriter, r *http.Request, sessionID string) bool {
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return false
+	}
+
+	playerID := playerIDFromContext(r.Context())
+	if session.CanPlay(playerID) {
+		return true
+	}
+
+	if playerID == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+	} else {
+		http.Error(w, "Game belongs to another player", http.StatusForbidden)
+	}
+	return false
+}
+
+// writeTokenResponse writes a player and API token as JSON
+func writeTokenResponse(w http.ResponseWriter, status int, player *auth.Player, token string) {
+	response := map[string]interface{}{
+		"player_id": player.ID,
+		"name":      player.Name,
+		"token":     token,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
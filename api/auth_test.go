@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// doRequest sends a request to the server and decodes a JSON response into out
+func doRequest(t *testing.T, s *Server, method, path, token, body string, out interface{}) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if out != nil && rec.Code < 300 {
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatalf("Error decoding response of %s %s: %v", method, path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestGameOwnership(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var alice, bob struct {
+		PlayerID string `json:"player_id"`
+		Token    string `json:"token"`
+	}
+	if code := doRequest(t, s, "POST", "/api/register", "", `{"name":"alice","password":"correct horse"}`, &alice); code != http.StatusCreated {
+		t.Fatalf("Expected 201 registering alice, got %d", code)
+	}
+	if code := doRequest(t, s, "POST", "/api/register", "", `{"name":"bob","password":"correct horse"}`, &bob); code != http.StatusCreated {
+		t.Fatalf("Expected 201 registering bob, got %d", code)
+	}
+
+	var created struct {
+		GameID         string `json:"game_id"`
+		OwnerID        string `json:"owner_id"`
+		SpectatorToken string `json:"spectator_token"`
+	}
+	doRequest(t, s, "POST", "/api/games", alice.Token, "", &created)
+	if created.OwnerID != alice.PlayerID {
+		t.Fatalf("Expected game to be owned by alice, got %q", created.OwnerID)
+	}
+
+	skip := "/api/games/" + created.GameID + "/skip"
+	if code := doRequest(t, s, "POST", skip, "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for anonymous move in owned game, got %d", code)
+	}
+	if code := doRequest(t, s, "POST", skip, bob.Token, "", nil); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another player's move, got %d", code)
+	}
+	if code := doRequest(t, s, "POST", skip, "bogus", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an invalid token, got %d", code)
+	}
+	if code := doRequest(t, s, "POST", skip, alice.Token, "", nil); code != http.StatusOK {
+		t.Errorf("Expected 200 for the owner's move, got %d", code)
+	}
+
+	// The state of a private owned game needs the owner or its spectator token
+	state := "/api/games/" + created.GameID
+	if code := doRequest(t, s, "GET", state, "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for anonymous state of an owned game, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", state, bob.Token, "", nil); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another player's state, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", state+"?token=wrong", "", "", nil); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a wrong spectator token, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", state+"?token="+created.SpectatorToken, "", "", nil); code != http.StatusOK {
+		t.Errorf("Expected 200 with the spectator token, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", state, alice.Token, "", nil); code != http.StatusOK {
+		t.Errorf("Expected 200 for the owner, got %d", code)
+	}
+
+	var mine struct {
+		Games []struct {
+			GameID string `json:"game_id"`
+		} `json:"games"`
+	}
+	doRequest(t, s, "GET", "/api/me/games", alice.Token, "", &mine)
+	if len(mine.Games) != 1 || mine.Games[0].GameID != created.GameID {
+		t.Errorf("Expected alice to own exactly game %s, got %+v", created.GameID, mine.Games)
+	}
+	if code := doRequest(t, s, "GET", "/api/me/games", "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 listing games anonymously, got %d", code)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var alice struct {
+		Token string `json:"token"`
+	}
+	if code := doRequest(t, s, "POST", "/api/register", "", `{"name":"alice","password":"correct horse"}`, &alice); code != http.StatusCreated {
+		t.Fatalf("Expected 201 registering alice, got %d", code)
+	}
+
+	if code := doRequest(t, s, "POST", "/api/logout", "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 logging out without a token, got %d", code)
+	}
+	if code := doRequest(t, s, "POST", "/api/logout", alice.Token, "", nil); code != http.StatusNoContent {
+		t.Fatalf("Expected 204 logging out, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", "/api/me/games", alice.Token, "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a revoked token, got %d", code)
+	}
+}
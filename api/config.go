@@ -0,0 +1,505 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minAdminTokenLength is the shortest admin token accepted
+const minAdminTokenLength = 16
+
+// Config holds the settings of the API server
+type Config struct {
+	// Addr is the address to listen on, e.g. ":8080"
+	Addr string
+	// Dev serves the web interface from StaticDir on disk instead of the
+	// copy embedded in the binary, for live editing
+	Dev bool
+	// StaticDir is the directory the web interface is served from in dev mode
+	StaticDir string
+	// DataDir holds player accounts, leaderboards, replays and saved sessions;
+	// empty keeps everything in memory
+	DataDir string
+
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+
+	// CORSOrigins lists the origins allowed to call the API; "*" allows any
+	CORSOrigins []string
+
+	// MaxSessions caps the number of live sessions; zero means no limit
+	MaxSessions int
+	// SessionMaxAge is how long a session may go without a move before it is evicted
+	SessionMaxAge time.Duration
+	// ReaperInterval is how often finished and abandoned sessions are evicted
+	ReaperInterval time.Duration
+
+	// MaxBodyBytes caps the size of request bodies
+	MaxBodyBytes int64
+
+	// CreateRate and CreateBurst limit game creation per client, in requests
+	// per second; a zero rate disables the limit
+	CreateRate  float64
+	CreateBurst int
+	// MoveRate and MoveBurst limit moves per client, in requests per second;
+	// a zero rate disables the limit
+	MoveRate  float64
+	MoveBurst int
+	// HintRate and HintBurst limit hints per client, in requests per
+	// second, since each runs a search; a zero rate disables the limit
+	HintRate  float64
+	HintBurst int
+	// AuthRate and AuthBurst limit registrations and logins per IP, in
+	// requests per second; a zero rate disables the limit
+	AuthRate  float64
+	AuthBurst int
+	// TrustProxy takes client IPs from X-Forwarded-For, for servers behind a reverse proxy
+	TrustProxy bool
+
+	// AdminToken protects the admin API and page; empty disables them
+	AdminToken string
+
+	// LogLevel is one of debug, info, warn or error
+	LogLevel string
+}
+
+// DefaultConfig returns the settings used when nothing is configured
+func DefaultConfig() Config {
+	return Config{
+		Addr:            ":8080",
+		StaticDir:       "./web",
+		DataDir:         "",
+		ReadTimeout:     15 * time.Second,
+		WriteTimeout:    15 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 10 * time.Second,
+		CORSOrigins:     []string{"*"},
+		MaxSessions:     10000,
+		SessionMaxAge:   2 * time.Hour,
+		ReaperInterval:  time.Minute,
+		MaxBodyBytes:    64 << 10,
+		CreateRate:      0.2,
+		CreateBurst:     5,
+		MoveRate:        5,
+		MoveBurst:       20,
+		HintRate:        0.5,
+		HintBurst:       5,
+		AuthRate:        0.1,
+		AuthBurst:       10,
+		LogLevel:        "info",
+	}
+}
+
+// fileConfig is the JSON representation of a config file. Unset fields keep
+// their current value.
+type fileConfig struct {
+	Addr            *string  `json:"addr"`
+	Dev             *bool    `json:"dev"`
+	StaticDir       *string  `json:"static_dir"`
+	DataDir         *string  `json:"data_dir"`
+	ReadTimeout     *string  `json:"read_timeout"`
+	WriteTimeout    *string  `json:"write_timeout"`
+	IdleTimeout     *string  `json:"idle_timeout"`
+	ShutdownTimeout *string  `json:"shutdown_timeout"`
+	CORSOrigins     []string `json:"cors_origins"`
+	MaxSessions     *int     `json:"max_sessions"`
+	SessionMaxAge   *string  `json:"session_max_age"`
+	ReaperInterval  *string  `json:"reaper_interval"`
+	MaxBodyBytes    *int64   `json:"max_body_bytes"`
+	CreateRate      *float64 `json:"create_rate"`
+	CreateBurst     *int     `json:"create_burst"`
+	MoveRate        *float64 `json:"move_rate"`
+	MoveBurst       *int     `json:"move_burst"`
+	HintRate        *float64 `json:"hint_rate"`
+	HintBurst       *int     `json:"hint_burst"`
+	AuthRate        *float64 `json:"auth_rate"`
+	AuthBurst       *int     `json:"auth_burst"`
+	TrustProxy      *bool    `json:"trust_proxy"`
+	AdminToken      *string  `json:"admin_token"`
+	LogLevel        *string  `json:"log_level"`
+}
+
+// LoadConfig builds the server config from, in increasing order of
+// precedence, the defaults, a JSON config file, SCOUNDREL_* environment
+// variables and command-line flags. The config file is named by the -config
+// flag or the SCOUNDREL_CONFIG variable.
+func LoadConfig(args []string, getenv func(string) string) (Config, error) {
+	// Find the config file before anything else is applied. The scan is
+	// silent; on -h or a bad flag, the full flag set reports instead, with
+	// the defaults in its usage.
+	var path string
+	scan := newFlagSet(&Config{}, &path)
+	scan.SetOutput(&bytes.Buffer{})
+	if err := scan.Parse(args); err != nil {
+		cfg := DefaultConfig()
+		return Config{}, newFlagSet(&cfg, new(string)).Parse(args)
+	}
+	if path == "" {
+		path = getenv("SCOUNDREL_CONFIG")
+	}
+
+	cfg := DefaultConfig()
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := cfg.loadEnv(getenv); err != nil {
+		return Config{}, err
+	}
+
+	// Flags override everything
+	if err := newFlagSet(&cfg, &path).Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// newFlagSet binds the command-line flags to cfg
+func newFlagSet(cfg *Config, path *string) *flag.FlagSet {
+	fs := flag.NewFlagSet("scoundrel-api", flag.ContinueOnError)
+	fs.StringVar(path, "config", *path, "JSON config file")
+	fs.StringVar(&cfg.Addr, "addr", cfg.Addr, "address to listen on")
+	fs.BoolVar(&cfg.Dev, "dev", cfg.Dev, "serve the web interface from -static-dir instead of the embedded copy")
+	fs.StringVar(&cfg.StaticDir, "static-dir", cfg.StaticDir, "directory of the web interface in dev mode")
+	fs.StringVar(&cfg.DataDir, "data-dir", cfg.DataDir, "directory for persistent data; empty keeps everything in memory")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", cfg.ReadTimeout, "maximum duration for reading a request")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "maximum duration for writing a response")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "maximum time to keep an idle connection open")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "maximum time to drain requests on shutdown")
+	fs.Func("cors-origins", "comma-separated origins allowed to call the API, or *", func(v string) error {
+		cfg.CORSOrigins = splitList(v)
+		return nil
+	})
+	fs.IntVar(&cfg.MaxSessions, "max-sessions", cfg.MaxSessions, "maximum number of live sessions; 0 means no limit")
+	fs.DurationVar(&cfg.SessionMaxAge, "session-max-age", cfg.SessionMaxAge, "how long a session may go without a move")
+	fs.DurationVar(&cfg.ReaperInterval, "reaper-interval", cfg.ReaperInterval, "how often abandoned sessions are evicted")
+	fs.Int64Var(&cfg.MaxBodyBytes, "max-body-bytes", cfg.MaxBodyBytes, "maximum size of a request body in bytes")
+	fs.Float64Var(&cfg.CreateRate, "create-rate", cfg.CreateRate, "games each client may create per second; 0 disables the limit")
+	fs.IntVar(&cfg.CreateBurst, "create-burst", cfg.CreateBurst, "games each client may create in a burst")
+	fs.Float64Var(&cfg.MoveRate, "move-rate", cfg.MoveRate, "moves each client may make per second; 0 disables the limit")
+	fs.IntVar(&cfg.MoveBurst, "move-burst", cfg.MoveBurst, "moves each client may make in a burst")
+	fs.Float64Var(&cfg.HintRate, "hint-rate", cfg.HintRate, "hints each client may ask for per second; 0 disables the limit")
+	fs.IntVar(&cfg.HintBurst, "hint-burst", cfg.HintBurst, "hints each client may ask for in a burst")
+	fs.Float64Var(&cfg.AuthRate, "auth-rate", cfg.AuthRate, "registrations and logins each IP may make per second; 0 disables the limit")
+	fs.IntVar(&cfg.AuthBurst, "auth-burst", cfg.AuthBurst, "registrations and logins each IP may make in a burst")
+	fs.BoolVar(&cfg.TrustProxy, "trust-proxy", cfg.TrustProxy, "take client IPs from X-Forwarded-For")
+	fs.StringVar(&cfg.AdminToken, "admin-token", cfg.AdminToken, "token for the admin API and page; empty disables them")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level: debug, info, warn or error")
+	return fs
+}
+
+// loadFile applies the settings of a JSON config file
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	var fc fileConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fc); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	setString(&c.Addr, fc.Addr)
+	setString(&c.StaticDir, fc.StaticDir)
+	setString(&c.DataDir, fc.DataDir)
+	setString(&c.AdminToken, fc.AdminToken)
+	setString(&c.LogLevel, fc.LogLevel)
+	if fc.Dev != nil {
+		c.Dev = *fc.Dev
+	}
+	if fc.CORSOrigins != nil {
+		c.CORSOrigins = fc.CORSOrigins
+	}
+	if fc.MaxSessions != nil {
+		c.MaxSessions = *fc.MaxSessions
+	}
+	if fc.MaxBodyBytes != nil {
+		c.MaxBodyBytes = *fc.MaxBodyBytes
+	}
+	if fc.CreateRate != nil {
+		c.CreateRate = *fc.CreateRate
+	}
+	if fc.CreateBurst != nil {
+		c.CreateBurst = *fc.CreateBurst
+	}
+	if fc.MoveRate != nil {
+		c.MoveRate = *fc.MoveRate
+	}
+	if fc.MoveBurst != nil {
+		c.MoveBurst = *fc.MoveBurst
+	}
+	if fc.HintRate != nil {
+		c.HintRate = *fc.HintRate
+	}
+	if fc.HintBurst != nil {
+		c.HintBurst = *fc.HintBurst
+	}
+	if fc.AuthRate != nil {
+		c.AuthRate = *fc.AuthRate
+	}
+	if fc.AuthBurst != nil {
+		c.AuthBurst = *fc.AuthBurst
+	}
+	if fc.TrustProxy != nil {
+		c.TrustProxy = *fc.TrustProxy
+	}
+
+	var errs []error
+	for _, d := range []struct {
+		name  string
+		value *string
+		dst   *time.Duration
+	}{
+		{"read_timeout", fc.ReadTimeout, &c.ReadTimeout},
+		{"write_timeout", fc.WriteTimeout, &c.WriteTimeout},
+		{"idle_timeout", fc.IdleTimeout, &c.IdleTimeout},
+		{"shutdown_timeout", fc.ShutdownTimeout, &c.ShutdownTimeout},
+		{"session_max_age", fc.SessionMaxAge, &c.SessionMaxAge},
+		{"reaper_interval", fc.ReaperInterval, &c.ReaperInterval},
+	} {
+		if d.value == nil {
+			continue
+		}
+		if err := setDuration(d.dst, *d.value); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %s: %w", path, d.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadEnv applies the SCOUNDREL_* environment variables. PORT is honoured
+// for hosting platforms that assign one.
+func (c *Config) loadEnv(getenv func(string) string) error {
+	if port := getenv("PORT"); port != "" {
+		c.Addr = ":" + port
+	}
+	if v := getenv("SCOUNDREL_ADDR"); v != "" {
+		c.Addr = v
+	}
+	if v := getenv("SCOUNDREL_STATIC_DIR"); v != "" {
+		c.StaticDir = v
+	}
+	if v := getenv("SCOUNDREL_DATA_DIR"); v != "" {
+		c.DataDir = v
+	}
+	if v := getenv("SCOUNDREL_CORS_ORIGINS"); v != "" {
+		c.CORSOrigins = splitList(v)
+	}
+	if v := getenv("SCOUNDREL_ADMIN_TOKEN"); v != "" {
+		c.AdminToken = v
+	}
+	if v := getenv("SCOUNDREL_LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+	}
+
+	var errs []error
+	if v := getenv("SCOUNDREL_MAX_SESSIONS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SCOUNDREL_MAX_SESSIONS: %q is not a number", v))
+		}
+		c.MaxSessions = n
+	}
+	if v := getenv("SCOUNDREL_MAX_BODY_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SCOUNDREL_MAX_BODY_BYTES: %q is not a number", v))
+		}
+		c.MaxBodyBytes = n
+	}
+	for _, r := range []struct {
+		name string
+		dst  *float64
+	}{
+		{"SCOUNDREL_CREATE_RATE", &c.CreateRate},
+		{"SCOUNDREL_MOVE_RATE", &c.MoveRate},
+		{"SCOUNDREL_HINT_RATE", &c.HintRate},
+		{"SCOUNDREL_AUTH_RATE", &c.AuthRate},
+	} {
+		if v := getenv(r.name); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", r.name, v))
+			}
+			*r.dst = n
+		}
+	}
+	for _, b := range []struct {
+		name string
+		dst  *int
+	}{
+		{"SCOUNDREL_CREATE_BURST", &c.CreateBurst},
+		{"SCOUNDREL_MOVE_BURST", &c.MoveBurst},
+		{"SCOUNDREL_HINT_BURST", &c.HintBurst},
+		{"SCOUNDREL_AUTH_BURST", &c.AuthBurst},
+	} {
+		if v := getenv(b.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", b.name, v))
+			}
+			*b.dst = n
+		}
+	}
+	if v := getenv("SCOUNDREL_DEV"); v != "" {
+		dev, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SCOUNDREL_DEV: %q is not true or false", v))
+		}
+		c.Dev = dev
+	}
+	if v := getenv("SCOUNDREL_TRUST_PROXY"); v != "" {
+		trust, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("SCOUNDREL_TRUST_PROXY: %q is not true or false", v))
+		}
+		c.TrustProxy = trust
+	}
+	for _, d := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"SCOUNDREL_READ_TIMEOUT", &c.ReadTimeout},
+		{"SCOUNDREL_WRITE_TIMEOUT", &c.WriteTimeout},
+		{"SCOUNDREL_IDLE_TIMEOUT", &c.IdleTimeout},
+		{"SCOUNDREL_SHUTDOWN_TIMEOUT", &c.ShutdownTimeout},
+		{"SCOUNDREL_SESSION_MAX_AGE", &c.SessionMaxAge},
+		{"SCOUNDREL_REAPER_INTERVAL", &c.ReaperInterval},
+	} {
+		v := getenv(d.name)
+		if v == "" {
+			continue
+		}
+		if err := setDuration(d.dst, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid setting
+func (c Config) Validate() error {
+	var errs []error
+	if c.Addr == "" {
+		errs = append(errs, errors.New("addr must not be empty"))
+	}
+	if c.Dev && c.StaticDir == "" {
+		errs = append(errs, errors.New("static dir must not be empty in dev mode"))
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"read timeout", c.ReadTimeout},
+		{"write timeout", c.WriteTimeout},
+		{"idle timeout", c.IdleTimeout},
+		{"shutdown timeout", c.ShutdownTimeout},
+		{"session max age", c.SessionMaxAge},
+		{"reaper interval", c.ReaperInterval},
+	} {
+		if d.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", d.name, d.value))
+		}
+	}
+	if len(c.CORSOrigins) == 0 {
+		errs = append(errs, errors.New(`cors origins must list at least one origin, or "*"`))
+	}
+	for _, origin := range c.CORSOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs = append(errs, fmt.Errorf("cors origin %q must start with http:// or https://", origin))
+		}
+	}
+	if c.MaxSessions < 0 {
+		errs = append(errs, fmt.Errorf("max sessions must not be negative, got %d", c.MaxSessions))
+	}
+	if c.MaxBodyBytes <= 0 {
+		errs = append(errs, fmt.Errorf("max body bytes must be positive, got %d", c.MaxBodyBytes))
+	}
+	for _, l := range []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"create", c.CreateRate, c.CreateBurst},
+		{"move", c.MoveRate, c.MoveBurst},
+		{"hint", c.HintRate, c.HintBurst},
+		{"auth", c.AuthRate, c.AuthBurst},
+	} {
+		if l.rate < 0 {
+			errs = append(errs, fmt.Errorf("%s rate must not be negative, got %g", l.name, l.rate))
+		}
+		if l.rate > 0 && l.burst < 1 {
+			errs = append(errs, fmt.Errorf("%s burst must be at least 1, got %d", l.name, l.burst))
+		}
+	}
+	if c.AdminToken != "" && len(c.AdminToken) < minAdminTokenLength {
+		errs = append(errs, fmt.Errorf("admin token must be at least %d characters", minAdminTokenLength))
+	}
+	if _, err := c.slogLevel(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+}
+
+// slogLevel parses the log level
+func (c Config) slogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return 0, fmt.Errorf("log level %q must be debug, info, warn or error", c.LogLevel)
+	}
+	return level, nil
+}
+
+// Logger returns a JSON logger writing to w at the configured level
+func (c Config) Logger(w io.Writer) *slog.Logger {
+	level, _ := c.slogLevel()
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// setString overwrites dst with v if it is set
+func setString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// setDuration parses v into dst
+func setDuration(dst *time.Duration, v string) error {
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 30s or 2h", v)
+	}
+	*dst = d
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envMap returns a getenv function reading from a map
+func envMap(env map[string]string) func(string) string {
+	return func(name string) string {
+		return env[name]
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	file := `{"addr": ":9000", "static_dir": "/srv/web", "read_timeout": "5s", "cors_origins": ["https://example.com"], "max_sessions": 50}`
+	if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+		t.Fatalf("Error writing config file: %v", err)
+	}
+
+	env := map[string]string{
+		"SCOUNDREL_CONFIG":       path,
+		"SCOUNDREL_ADDR":         ":9100",
+		"SCOUNDREL_READ_TIMEOUT": "7s",
+	}
+	config, err := LoadConfig([]string{"-addr", ":9200"}, envMap(env))
+	if err != nil {
+		t.Fatalf("Error loading config: %v", err)
+	}
+
+	if config.Addr != ":9200" {
+		t.Errorf("Expected flag to override addr, got %q", config.Addr)
+	}
+	if config.ReadTimeout != 7*time.Second {
+		t.Errorf("Expected environment to override read timeout, got %s", config.ReadTimeout)
+	}
+	if config.StaticDir != "/srv/web" || config.MaxSessions != 50 {
+		t.Errorf("Expected file settings to apply, got static dir %q and max sessions %d", config.StaticDir, config.MaxSessions)
+	}
+	if len(config.CORSOrigins) != 1 || config.CORSOrigins[0] != "https://example.com" {
+		t.Errorf("Expected CORS origins from file, got %v", config.CORSOrigins)
+	}
+	if config.WriteTimeout != DefaultConfig().WriteTimeout {
+		t.Errorf("Expected default write timeout, got %s", config.WriteTimeout)
+	}
+	if config.DataDir != "" {
+		t.Errorf("Expected nothing to be persisted by default, got data dir %q", config.DataDir)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	args := []string{"-read-timeout", "0s", "-cors-origins", "example.com", "-log-level", "loud"}
+	_, err := LoadConfig(args, envMap(nil))
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+
+	// Every problem is reported at once
+	for _, want := range []string{"read timeout", "example.com", "loud"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %q, got %v", want, err)
+		}
+	}
+
+	if _, err := LoadConfig(nil, envMap(map[string]string{"SCOUNDREL_SESSION_MAX_AGE": "forever"})); err == nil {
+		t.Error("Expected error for invalid duration in environment")
+	}
+}
+
+func TestLoadConfigHelp(t *testing.T) {
+	if _, err := LoadConfig([]string{"-h"}, envMap(nil)); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Expected flag.ErrHelp for -h, got %v", err)
+	}
+	if _, err := LoadConfig([]string{"-config", "missing.json", "-nope"}, envMap(nil)); err == nil || !strings.Contains(err.Error(), "-nope") {
+		t.Errorf("Expected error naming the unknown flag, got %v", err)
+	}
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	config := testConfig("")
+	config.CORSOrigins = []string{"https://example.com"}
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	for origin, want := range map[string]string{
+		"https://example.com": "https://example.com",
+		"https://evil.com":    "",
+	} {
+		req := httptest.NewRequest("GET", "/api/games/live", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("Expected Access-Control-Allow-Origin %q for %s, got %q", want, origin, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/leaderboard"
+)
+
+// DailyHandler creates the authenticated player's game for today's daily
+// challenge. Every player gets the same deal and one scored attempt per day.
+func (h *Handler) DailyHandler(w http.ResponseWriter, r *http.Request) {
+	player := playerFromContext(r.Context())
+	if player == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	challenge := game.DailyDate(now)
+
+	if gameID, attempted := h.leaderboard.DailyAttempt(challenge, player.ID); attempted {
+		writeDailyConflict(w, challenge, gameID)
+		return
+	}
+
+	if h.atCapacity(w) {
+		return
+	}
+
+	// Create the session on the day's seed
+	sessionID := h.sessionManager.CreateSessionWithOptions(game.SessionOptions{
+		OwnerID:   player.ID,
+		Seed:      game.DailySeed(now),
+		Challenge: challenge,
+	})
+
+	gameID, err := h.leaderboard.ClaimDailyAttempt(challenge, player.ID, sessionID)
+	if errors.Is(err, leaderboard.ErrAttemptUsed) {
+		// Another request claimed the attempt first
+		h.sessionManager.DeleteSession(sessionID)
+		writeDailyConflict(w, challenge, gameID)
+		return
+	}
+	if err != nil {
+		h.sessionManager.DeleteSession(sessionID)
+		http.Error(w, "Failed to start daily challenge", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"game_id":   sessionID,
+		"challenge": challenge,
+		"owner_id":  player.ID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeDailyConflict reports that a player has already attempted a daily challenge
+func writeDailyConflict(w http.ResponseWriter, challenge, gameID string) {
+	response := map[string]interface{}{
+		"error":     "Daily challenge already attempted",
+		"challenge": challenge,
+		"game_id":   gameID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDailyChallengeSingleAttempt(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	if code := doRequest(t, s, "POST", "/api/daily", "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for anonymous daily challenge, got %d", code)
+	}
+
+	var player struct {
+		Token string `json:"token"`
+	}
+	doRequest(t, s, "POST", "/api/register", "", `{"name":"alice","password":"correct horse"}`, &player)
+
+	var daily struct {
+		GameID    string `json:"game_id"`
+		Challenge string `json:"challenge"`
+	}
+	if code := doRequest(t, s, "POST", "/api/daily", player.Token, "", &daily); code != http.StatusCreated {
+		t.Fatalf("Expected 201 starting daily challenge, got %d", code)
+	}
+
+	session, err := s.sessionManager.GetSession(daily.GameID)
+	if err != nil {
+		t.Fatalf("Expected daily game to exist: %v", err)
+	}
+	if session.GetChallenge() != daily.Challenge {
+		t.Errorf("Expected game to belong to challenge %s, got %s", daily.Challenge, session.GetChallenge())
+	}
+
+	if code := doRequest(t, s, "POST", "/api/daily", player.Token, "", nil); code != http.StatusConflict {
+		t.Errorf("Expected 409 for a second attempt, got %d", code)
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tippi-fifestarr/scoundrel/dataset"
+)
+
+// AdminExportHandler exports training data from the finished games stored
+// on the server: one sample per move with the visible state, the action
+// chosen and the game's outcome and score. Query parameters pick the format
+// (jsonl or csv), filter by outcome and rule set, and dedup by state hash.
+func (s *Server) AdminExportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	opts := dataset.Options{
+		Outcome: q.Get("outcome"),
+		Rules:   q.Get("rules"),
+	}
+	if v := q.Get("dedup"); v != "" {
+		dedup, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "Invalid dedup", http.StatusBadRequest)
+			return
+		}
+		opts.Dedup = dedup
+	}
+
+	writer, err := dataset.NewWriter(w, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	replays, err := s.replays.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "application/x-ndjson"
+	if format == "csv" {
+		contentType = "text/csv"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=scoundrel-training."+format)
+
+	// Once samples are streaming, errors can only be logged
+	exporter := dataset.NewExporter(writer, opts)
+	for _, rep := range replays {
+		if err := exporter.AddReplay(rep, dataset.SourceLive, rep.OwnerID); err != nil {
+			s.logger.Error("failed to export replay", "game_id", rep.GameID, "error", err)
+		}
+	}
+	if err := exporter.Flush(); err != nil {
+		s.logger.Error("failed to export training data", "error", err)
+	}
+}
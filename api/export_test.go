@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/dataset"
+)
+
+func TestAdminExport(t *testing.T) {
+	config := testConfig("")
+	config.AdminToken = testAdminToken
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	// Play two games to the end through the session manager, and leave one unfinished
+	greedy := bot.NewGreedy()
+	moves := 0
+	for i := 0; i < 2; i++ {
+		var created struct {
+			GameID string `json:"game_id"`
+		}
+		doRequest(t, s, "POST", "/api/games", "", "", &created)
+		for {
+			session, err := s.sessionManager.GetSession(created.GameID)
+			if err != nil {
+				t.Fatalf("Error getting session: %v", err)
+			}
+			if session.IsGameOver() {
+				break
+			}
+			if _, err := s.sessionManager.Apply(created.GameID, greedy.Move(session.View())); err != nil {
+				t.Fatalf("Error playing: %v", err)
+			}
+			moves++
+		}
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", nil)
+
+	if code := doRequest(t, s, "GET", "/admin/api/export", "", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without admin token, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", "/admin/api/export?format=xml", testAdminToken, "", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown format, got %d", code)
+	}
+
+	export := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/admin/api/export"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200 exporting %s, got %d", query, rec.Code)
+		}
+		return rec
+	}
+
+	rec := export("")
+	samples := 0
+	scanner := bufio.NewScanner(rec.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var sample dataset.Sample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			t.Fatalf("Error decoding sample: %v", err)
+		}
+		if sample.Source != dataset.SourceLive || sample.Outcome == "" {
+			t.Errorf("Expected a live sample with its outcome, got %+v", sample)
+		}
+		samples++
+	}
+	if samples != moves {
+		t.Errorf("Expected a sample per move of the finished games (%d), got %d", moves, samples)
+	}
+
+	rec = export("?format=csv&dedup=true&rules=standard")
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("Expected CSV, got %s", rec.Header().Get("Content-Type"))
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Error reading CSV: %v", err)
+	}
+	if len(rows) < 2 || len(rows)-1 > moves {
+		t.Errorf("Expected at most %d rows after the header, got %d", moves, len(rows)-1)
+	}
+}
@@ -2,33 +2,109 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/auth"
 	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/leaderboard"
+	"github.com/tippi-fifestarr/scoundrel/replay"
 )
 
 // Handler handles API requests for the game
 type Handler struct {
 	sessionManager *game.SessionManager
+	players        *auth.Store
+	leaderboard    *leaderboard.Leaderboard
+	replays        *replay.Store
+
+	// maxSessions caps the number of live sessions; zero means no limit
+	maxSessions int
+
+	// streamsDone is closed when the server shuts down, ending open streams
+	streamsDone chan struct{}
+	closeOnce   sync.Once
 }
 
 // NewHandler creates a new Handler
-func NewHandler(sessionManager *game.SessionManager) *Handler {
+func NewHandler(sessionManager *game.SessionManager, players *auth.Store, lb *leaderboard.Leaderboard, replays *replay.Store) *Handler {
 	return &Handler{
 		sessionManager: sessionManager,
+		players:        players,
+		leaderboard:    lb,
+		replays:        replays,
+		streamsDone:    make(chan struct{}),
+	}
+}
+
+// closeStreams ends every open spectator stream
+func (h *Handler) closeStreams() {
+	h.closeOnce.Do(func() {
+		close(h.streamsDone)
+	})
+}
+
+// atCapacity reports whether the session limit has been reached and writes
+// an error response if so
+func (h *Handler) atCapacity(w http.ResponseWriter) bool {
+	if h.maxSessions > 0 && h.sessionManager.ActiveSessionCount() >= h.maxSessions {
+		http.Error(w, "Too many games in progress, try again later", http.StatusServiceUnavailable)
+		return true
 	}
+	return false
+}
+
+// createGameRequest is the optional body of a create game request
+type createGameRequest struct {
+	Public bool `json:"public"`
+	// Tutorial starts the named tutorial lesson instead of a regular game
+	Tutorial string `json:"tutorial"`
 }
 
 // CreateGameHandler creates a new game session
 func (h *Handler) CreateGameHandler(w http.ResponseWriter, r *http.Request) {
+	// Parse optional game options
+	var req createGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, ok := game.LessonByName(req.Tutorial); req.Tutorial != "" && !ok {
+		http.Error(w, "Unknown tutorial lesson", http.StatusBadRequest)
+		return
+	}
+
+	if h.atCapacity(w) {
+		return
+	}
+
 	// Create new game session
-	sessionID := h.sessionManager.CreateSession()
+	sessionID := h.sessionManager.CreateSessionWithOptions(game.SessionOptions{
+		Public:   req.Public,
+		OwnerID:  playerIDFromContext(r.Context()),
+		Tutorial: req.Tutorial,
+	})
+	session, err := h.sessionManager.GetSession(sessionID)
+	if err != nil {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
 
 	// Build response
 	response := map[string]interface{}{
-		"game_id": sessionID,
+		"game_id":         sessionID,
+		"public":          session.IsPublic(),
+		"spectator_token": session.GetSpectatorToken(),
+		"owner_id":        session.GetOwnerID(),
+	}
+	if req.Tutorial != "" {
+		response["tutorial"] = req.Tutorial
 	}
 
 	// Write response
@@ -36,11 +112,13 @@ func (h *Handler) CreateGameHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetGameHandler returns the current state of a game
+// GetGameHandler returns the current state of a game to a player who may
+// play it, or to a spectator of a public game or holding its spectator token
 func (h *Handler) GetGameHandler(w http.ResponseWriter, r *http.Request) {
-	// Get session ID from URL
+	// Get session ID from URL and spectator token from query
 	vars := mux.Vars(r)
 	sessionID := vars["id"]
+	token := r.URL.Query().Get("token")
 
 	// Get game session
 	session, err := h.sessionManager.GetSession(sessionID)
@@ -49,9 +127,25 @@ func (h *Handler) GetGameHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Owned games are only shown to their owner and their spectators
+	playerID := playerIDFromContext(r.Context())
+	if !session.CanPlay(playerID) && !session.CanSpectate(token) {
+		if playerID == "" && token == "" {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+		} else {
+			http.Error(w, "Game belongs to another player", http.StatusForbidden)
+		}
+		return
+	}
+
 	// Return game state
+	state, err := h.sessionManager.GetGameState(sessionID)
+	if err != nil {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(session.GetGameState())
+	json.NewEncoder(w).Encode(state)
 }
 
 // PlayCardHandler plays a card from the current room
@@ -65,15 +159,17 @@ func (h *Handler) PlayCardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get game session
-	session, err := h.sessionManager.GetSession(sessionID)
-	if err != nil {
-		http.Error(w, "Game session not found", http.StatusNotFound)
+	// Only the owner may move in an owned game
+	if !h.authorizeMove(w, r, sessionID) {
 		return
 	}
 
 	// Play the card
-	err = session.PlayCard(cardIndex)
+	state, err := h.sessionManager.PlayCard(sessionID, cardIndex)
+	if errors.Is(err, game.ErrSessionNotFound) {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -81,7 +177,7 @@ func (h *Handler) PlayCardHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Return updated game state
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(session.GetGameState())
+	json.NewEncoder(w).Encode(state)
 }
 
 // PlayCardWithoutWeaponHandler plays a monster card without using an equipped weapon
@@ -95,15 +191,17 @@ func (h *Handler) PlayCardWithoutWeaponHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Get game session
-	session, err := h.sessionManager.GetSession(sessionID)
-	if err != nil {
-		http.Error(w, "Game session not found", http.StatusNotFound)
+	// Only the owner may move in an owned game
+	if !h.authorizeMove(w, r, sessionID) {
 		return
 	}
 
 	// Play the card without weapon
-	err = session.PlayCardWithoutWeapon(cardIndex)
+	state, err := h.sessionManager.PlayCardWithoutWeapon(sessionID, cardIndex)
+	if errors.Is(err, game.ErrSessionNotFound) {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -111,7 +209,7 @@ func (h *Handler) PlayCardWithoutWeaponHandler(w http.ResponseWriter, r *http.Re
 
 	// Return updated game state
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(session.GetGameState())
+	json.NewEncoder(w).Encode(state)
 }
 
 // SkipRoomHandler skips the current room
@@ -120,15 +218,17 @@ func (h *Handler) SkipRoomHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sessionID := vars["id"]
 
-	// Get game session
-	session, err := h.sessionManager.GetSession(sessionID)
-	if err != nil {
-		http.Error(w, "Game session not found", http.StatusNotFound)
+	// Only the owner may move in an owned game
+	if !h.authorizeMove(w, r, sessionID) {
 		return
 	}
 
 	// Skip the room
-	err = session.SkipRoom()
+	state, err := h.sessionManager.SkipRoom(sessionID)
+	if errors.Is(err, game.ErrSessionNotFound) {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -136,5 +236,115 @@ func (h *Handler) SkipRoomHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Return updated game state
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(session.GetGameState())
+	json.NewEncoder(w).Encode(state)
+}
+
+// LiveGamesHandler lists the public games currently in progress
+func (h *Handler) LiveGamesHandler(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"games": h.sessionManager.LiveGames(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// SpectateGameHandler returns the game as the player sees it, without the hidden deck order
+func (h *Handler) SpectateGameHandler(w http.ResponseWriter, r *http.Request) {
+	// Get session ID from URL and spectator token from query
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+	token := r.URL.Query().Get("token")
+
+	view, err := h.sessionManager.Spectate(sessionID, token)
+	if err != nil {
+		writeSpectateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+// SpectateStreamHandler streams the visible game state as server-sent events,
+// sending a "state" event after every move until the game ends
+func (h *Handler) SpectateStreamHandler(w http.ResponseWriter, r *http.Request) {
+	// Get session ID from URL and spectator token from query
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+	token := r.URL.Query().Get("token")
+
+	// Register before taking the first view so that no move made in
+	// between goes unstreamed
+	updates, stop, err := h.sessionManager.Watch(sessionID)
+	if err != nil {
+		writeSpectateError(w, err)
+		return
+	}
+	defer stop()
+
+	view, err := h.sessionManager.Spectate(sessionID, token)
+	if err != nil {
+		writeSpectateError(w, err)
+		return
+	}
+
+	// Streams outlive the server write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		if err := writeEvent(w, "state", view); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		if view.State == game.GameStateWon.String() || view.State == game.GameStateLost.String() {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.streamsDone:
+			return
+		case _, open := <-updates:
+			if !open {
+				return
+			}
+		}
+
+		view, err = h.sessionManager.Spectate(sessionID, token)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeSpectateError maps a spectate error to an HTTP response
+func writeSpectateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, game.ErrSessionNotFound):
+		http.Error(w, "Game session not found", http.StatusNotFound)
+	case errors.Is(err, game.ErrSpectateForbidden):
+		http.Error(w, "Invalid spectator token", http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeEvent writes a single server-sent event with a JSON payload
+func writeEvent(w io.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
 }
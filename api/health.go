@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// storeCheckTimeout bounds how long the readiness check waits for the session store
+	storeCheckTimeout = time.Second
+	// dataDirWriteInterval is how often the readiness check tries writing to the data directory
+	dataDirWriteInterval = time.Minute
+)
+
+// check is the result of one readiness check
+type check struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// HealthzHandler reports that the process is alive
+func (s *Server) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok"})
+}
+
+// ReadyzHandler reports whether the server can take traffic: the session
+// store answers, the data directory is writable, background workers are
+// running and the server is not draining for shutdown
+func (s *Server) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]check{
+		"sessions": s.checkSessionStore(),
+		"data_dir": s.checkDataDir(),
+		"workers":  s.checkWorkers(),
+		"draining": s.checkDraining(),
+	}
+
+	status := http.StatusOK
+	response := map[string]interface{}{
+		"status": "ready",
+		"checks": checks,
+	}
+	for _, c := range checks {
+		if !c.OK {
+			status = http.StatusServiceUnavailable
+			response["status"] = "not ready"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkSessionStore verifies the session manager answers within the timeout,
+// which it would not if its lock were stuck
+func (s *Server) checkSessionStore() check {
+	n, ok := s.sessionManager.TryActiveSessionCount(storeCheckTimeout)
+	if !ok {
+		return check{OK: false, Detail: "session store did not respond"}
+	}
+	return check{OK: true, Detail: fmt.Sprintf("%d sessions", n)}
+}
+
+// checkDataDir verifies the data directory, created at startup, still
+// exists. Whether a file can be created in it is checked at most once per
+// dataDirWriteInterval, so that frequent probes do not churn the directory.
+func (s *Server) checkDataDir() check {
+	if s.config.DataDir == "" {
+		return check{OK: true, Detail: "in memory"}
+	}
+
+	info, err := os.Stat(s.config.DataDir)
+	if err != nil {
+		return check{OK: false, Detail: err.Error()}
+	}
+	if !info.IsDir() {
+		return check{OK: false, Detail: s.config.DataDir + " is not a directory"}
+	}
+
+	s.dataDirMutex.Lock()
+	defer s.dataDirMutex.Unlock()
+	if time.Since(s.dataDirCheckedAt) >= dataDirWriteInterval {
+		s.dataDirErr = writeProbe(s.config.DataDir)
+		s.dataDirCheckedAt = time.Now()
+	}
+	if s.dataDirErr != nil {
+		return check{OK: false, Detail: s.dataDirErr.Error()}
+	}
+	return check{OK: true, Detail: s.config.DataDir + " is writable"}
+}
+
+// writeProbe creates and removes a file in dir
+func writeProbe(dir string) error {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkWorkers verifies the background workers are running
+func (s *Server) checkWorkers() check {
+	if !s.reaperRunning.Load() {
+		return check{OK: false, Detail: "session reaper is not running"}
+	}
+	return check{OK: true, Detail: "session reaper running"}
+}
+
+// checkDraining fails once shutdown has begun
+func (s *Server) checkDraining() check {
+	if s.draining.Load() {
+		return check{OK: false, Detail: "draining for shutdown"}
+	}
+	return check{OK: true, Detail: "accepting traffic"}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readiness is the body of a /readyz response
+type readiness struct {
+	Status string           `json:"status"`
+	Checks map[string]check `json:"checks"`
+}
+
+// getReadiness fetches /readyz from a running server
+func getReadiness(t *testing.T, url string) (int, readiness) {
+	t.Helper()
+
+	resp, err := http.Get(url + "/readyz")
+	if err != nil {
+		t.Fatalf("Error fetching /readyz: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body readiness
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding /readyz: %v", err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestHealthChecks(t *testing.T) {
+	s, err := NewServer(testConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	// Not ready until the background workers run
+	if code := doRequest(t, s, "GET", "/readyz", "", "", nil); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before serving, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", "/healthz", "", "", nil); code != http.StatusOK {
+		t.Errorf("Expected 200 from /healthz, got %d", code)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(ctx, ln)
+	}()
+	url := "http://" + ln.Addr().String()
+
+	code, body := getReadiness(t, url)
+	if code != http.StatusOK || body.Status != "ready" {
+		t.Errorf("Expected ready while serving, got %d %+v", code, body)
+	}
+	for _, name := range []string{"sessions", "data_dir", "workers", "draining"} {
+		if !body.Checks[name].OK {
+			t.Errorf("Expected check %s to pass, got %+v", name, body.Checks[name])
+		}
+	}
+
+	// Draining servers report not ready
+	s.draining.Store(true)
+	code, body = getReadiness(t, url)
+	if code != http.StatusServiceUnavailable || body.Checks["draining"].OK {
+		t.Errorf("Expected 503 while draining, got %d %+v", code, body)
+	}
+
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+}
+
+func TestDataDirCheckWritesOncePerInterval(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	s, err := NewServer(testConfig(dir))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("Expected the server to create the data directory, got %v", err)
+	}
+
+	if c := s.checkDataDir(); !c.OK {
+		t.Fatalf("Expected the data directory check to pass, got %+v", c)
+	}
+	checkedAt := s.dataDirCheckedAt
+	if c := s.checkDataDir(); !c.OK {
+		t.Fatalf("Expected the data directory check to pass again, got %+v", c)
+	}
+	if !s.dataDirCheckedAt.Equal(checkedAt) {
+		t.Errorf("Expected a second probe within the interval not to write to the data directory")
+	}
+
+	// A missing directory fails, and is not created again by the probe
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Error removing data directory: %v", err)
+	}
+	if c := s.checkDataDir(); c.OK {
+		t.Errorf("Expected the check to fail without the data directory, got %+v", c)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the probe not to create the data directory, got %v", err)
+	}
+
+	// A file in place of the directory fails without waiting for the interval
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+	if c := s.checkDataDir(); c.OK {
+		t.Errorf("Expected the check to fail when the data directory is a file, got %+v", c)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/bot"
+)
+
+// HintHandler suggests a move for a game in progress, valuing every legal
+// move with the expectimax search. The search sees only what the player has
+// seen. Hints are not given in ranked or tutorial games.
+func (h *Handler) HintHandler(w http.ResponseWriter, r *http.Request) {
+	// Get session ID from URL
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+
+	// Only the owner may ask for hints in an owned game
+	if !h.authorizeMove(w, r, sessionID) {
+		return
+	}
+
+	// Search a copy, so that moves are not held up while it runs
+	pos, err := h.sessionManager.GetPosition(sessionID)
+	if err != nil {
+		http.Error(w, "Game session not found", http.StatusNotFound)
+		return
+	}
+	if pos.Over {
+		http.Error(w, "Game is over", http.StatusBadRequest)
+		return
+	}
+	rep := pos.Replay
+	if rep.Tutorial != "" {
+		http.Error(w, "Hints are not available in tutorial games", http.StatusBadRequest)
+		return
+	}
+	if pos.OwnerID != "" && !pos.Practice {
+		http.Error(w, "Hints are not available in ranked games", http.StatusForbidden)
+		return
+	}
+
+	// Recall the cards the player has seen leave the dungeon
+	memory, err := bot.MemoryOf(rep)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	moves := bot.NewExpectimax(bot.DefaultDepth).Evaluate(pos.View, memory)
+
+	response := map[string]interface{}{
+		"game_id":  sessionID,
+		"strategy": "expectimax",
+		"move":     moves[0].Move,
+		"moves":    moves,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
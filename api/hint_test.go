@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestHint(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+
+	var hint struct {
+		Move  game.Action     `json:"move"`
+		Moves []bot.MoveValue `json:"moves"`
+	}
+	if code := doRequest(t, s, "GET", "/api/games/"+created.GameID+"/hint", "", "", &hint); code != http.StatusOK {
+		t.Fatalf("Expected 200 for a hint, got %d", code)
+	}
+	var view game.View
+	doRequest(t, s, "GET", "/api/games/"+created.GameID, "", "", &view)
+	if len(hint.Moves) != len(bot.LegalMoves(view)) || hint.Moves[0].Move != hint.Move {
+		t.Errorf("Expected every legal move valued, best first, got %+v", hint)
+	}
+
+	// The hinted move is legal
+	path := fmt.Sprintf("/api/games/%s/play/%d", created.GameID, hint.Move.Index)
+	switch hint.Move.Type {
+	case game.ActionPlayBarehanded:
+		path = fmt.Sprintf("/api/games/%s/play-without-weapon/%d", created.GameID, hint.Move.Index)
+	case game.ActionSkip:
+		path = fmt.Sprintf("/api/games/%s/skip", created.GameID)
+	}
+	if code := doRequest(t, s, "POST", path, "", "", nil); code != http.StatusOK {
+		t.Errorf("Expected the hinted move to be accepted, got %d", code)
+	}
+
+	if code := doRequest(t, s, "GET", "/api/games/nope/hint", "", "", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown game, got %d", code)
+	}
+
+	// Ranked games get no hints
+	var player struct {
+		Token string `json:"token"`
+	}
+	doRequest(t, s, "POST", "/api/register", "", `{"name":"alice","password":"correct horse"}`, &player)
+	doRequest(t, s, "POST", "/api/games", player.Token, "", &created)
+	if code := doRequest(t, s, "GET", "/api/games/"+created.GameID+"/hint", player.Token, "", nil); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a ranked game, got %d", code)
+	}
+
+	doRequest(t, s, "POST", "/api/games", "", `{"tutorial":"weapons"}`, &created)
+	if code := doRequest(t, s, "GET", "/api/games/"+created.GameID+"/hint", "", "", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a tutorial game, got %d", code)
+	}
+}
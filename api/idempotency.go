@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyHeader carries the client's key for a retryable request
+	idempotencyHeader = "Idempotency-Key"
+	// idempotencyTTL is how long a response is kept for replay
+	idempotencyTTL = 10 * time.Minute
+	// maxIdempotencyEntries bounds the number of responses kept
+	maxIdempotencyEntries = 10000
+	// maxIdempotencyKeyLength bounds client-supplied keys
+	maxIdempotencyKeyLength = 128
+)
+
+// idempotentResponse is a recorded response, or a request still in flight
+type idempotentResponse struct {
+	done    chan struct{}
+	ok      bool
+	status  int
+	header  http.Header
+	body    []byte
+	created time.Time
+}
+
+// idempotencyCache keeps responses by idempotency key so that a retried
+// request returns the original response instead of being applied twice
+type idempotencyCache struct {
+	entries map[string]*idempotentResponse
+	mutex   sync.Mutex
+}
+
+// newIdempotencyCache creates an empty cache
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotentResponse)}
+}
+
+// idempotent replays the recorded response of a request whose
+// Idempotency-Key was seen before from the same client. Requests without a
+// key are served normally. Server errors and rate limit responses are not
+// recorded, so they can be retried with the same key.
+func (s *Server) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key too long", http.StatusBadRequest)
+			return
+		}
+
+		cacheKey := s.clientKey(r) + "\x00" + r.Method + " " + r.URL.Path + "\x00" + key
+		for {
+			entry, owner := s.idempotency.claim(cacheKey)
+			if owner {
+				rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+				served := false
+				defer func() {
+					// A handler that panicked counts as a server error, so
+					// waiting retries are released and run again
+					if !served {
+						rec.status = http.StatusInternalServerError
+					}
+					s.idempotency.finish(cacheKey, entry, rec)
+				}()
+				next.ServeHTTP(rec, r)
+				served = true
+				return
+			}
+
+			// Wait for the original request, then replay it
+			select {
+			case <-entry.done:
+			case <-r.Context().Done():
+				return
+			}
+			if entry.ok {
+				entry.replay(w)
+				return
+			}
+		}
+	})
+}
+
+// claim returns the entry for key. The caller owns a new entry and must
+// finish it; otherwise it waits for the entry to be done.
+func (c *idempotencyCache) claim(key string) (*idempotentResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if entry, exists := c.entries[key]; exists && now.Sub(entry.created) < idempotencyTTL {
+		return entry, false
+	}
+
+	if len(c.entries) >= maxIdempotencyEntries {
+		c.prune(now)
+	}
+	entry := &idempotentResponse{done: make(chan struct{}), created: now}
+	c.entries[key] = entry
+	return entry, true
+}
+
+// finish records the response of an owned entry, or drops it if the response must not be replayed
+func (c *idempotencyCache) finish(key string, entry *idempotentResponse, rec *recordingWriter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if rec.status >= 500 || rec.status == http.StatusTooManyRequests {
+		delete(c.entries, key)
+	} else {
+		entry.ok = true
+		entry.status = rec.status
+		entry.header = rec.Header().Clone()
+		entry.body = rec.body.Bytes()
+	}
+	close(entry.done)
+}
+
+// prune drops expired entries, then the oldest if the cache is still full.
+// Must be called with the lock held.
+func (c *idempotencyCache) prune(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if now.Sub(entry.created) >= idempotencyTTL {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.created.Before(oldest) {
+			oldestKey, oldest = key, entry.created
+		}
+	}
+	if len(c.entries) >= maxIdempotencyEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// replay writes a recorded response
+func (e *idempotentResponse) replay(w http.ResponseWriter) {
+	for name, values := range e.header {
+		if name == requestIDHeader {
+			continue
+		}
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(e.status)
+	w.Write(e.body)
+}
+
+// recordingWriter copies a response while writing it to the client
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status code before writing it
+func (r *recordingWriter) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body before writing it
+func (r *recordingWriter) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdempotentMove(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+
+	skip := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/games/"+created.GameID+"/skip", nil)
+		if key != "" {
+			req.Header.Set(idempotencyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := skip("key-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for first skip, got %d", first.Code)
+	}
+
+	// A retry with the same key replays the response instead of skipping twice
+	retry := skip("key-1")
+	if retry.Code != http.StatusOK || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Expected replayed 200 for retried skip, got %d", retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Error("Expected replayed response to match the original")
+	}
+
+	session, _ := s.sessionManager.GetSession(created.GameID)
+	if n := len(session.Actions()); n != 1 {
+		t.Errorf("Expected the skip to be applied once, got %d actions", n)
+	}
+
+	// A new key is a new request: skipping twice in a row is illegal
+	if rec := skip("key-2"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a second skip, got %d", rec.Code)
+	}
+}
+
+func TestIdempotentPanicIsRetried(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	calls := 0
+	handler := s.recoveryMiddleware(s.idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("first attempt fails")
+		}
+		w.WriteHeader(http.StatusOK)
+	})))
+	send := func() int {
+		req := httptest.NewRequest("POST", "/api/games", nil)
+		req.Header.Set(idempotencyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send(); code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500 for a panicking handler, got %d", code)
+	}
+
+	// The retry runs the handler again instead of waiting for the failed attempt
+	done := make(chan int)
+	go func() { done <- send() }()
+	select {
+	case code := <-done:
+		if code != http.StatusOK || calls != 2 {
+			t.Errorf("Expected the retry to run the handler and get 200, got %d after %d calls", code, calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the retry not to block after a panic")
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/leaderboard"
+)
+
+// LeaderboardHandler returns the ranked rows of a leaderboard
+func (h *Handler) LeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	// Get board name from URL
+	vars := mux.Vars(r)
+	board := vars["board"]
+
+	query := leaderboard.Query{
+		RulePreset: r.URL.Query().Get("rules"),
+		Challenge:  r.URL.Query().Get("date"),
+	}
+
+	if board == leaderboard.BoardSeed {
+		seed, err := strconv.ParseInt(r.URL.Query().Get("seed"), 10, 64)
+		if err != nil {
+			http.Error(w, "The seed board requires a numeric seed parameter", http.StatusBadRequest)
+			return
+		}
+		query.Seed = seed
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = n
+	}
+
+	rows, err := h.leaderboard.Board(board, query)
+	if errors.Is(err, leaderboard.ErrUnknownBoard) {
+		http.Error(w, "Leaderboard not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"board":   board,
+		"entries": rows,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// recordGame submits a finished game to the leaderboard. Anonymous and
+// practice games are not ranked.
+func (s *Server) recordGame(session *game.GameSession) {
+	ownerID := session.GetOwnerID()
+	if ownerID == "" || session.IsPractice() {
+		return
+	}
+
+	name := ""
+	if player, ok := s.players.GetPlayer(ownerID); ok {
+		name = player.Name
+	}
+
+	entry := leaderboard.Entry{
+		GameID:       session.GetID(),
+		PlayerID:     ownerID,
+		PlayerName:   name,
+		Seed:         session.GetSeed(),
+		Score:        session.Score(),
+		Won:          session.GetState() == game.GameStateWon,
+		RulePreset:   game.StandardRules,
+		RoomsCleared: session.RoomsCleared(),
+		DurationMS:   session.Duration().Milliseconds(),
+		FinishedAt:   session.FinishedAt().UTC(),
+		Challenge:    session.GetChallenge(),
+	}
+
+	if err := s.leaderboard.Record(entry); err != nil {
+		s.logger.Error("failed to record game on leaderboard", "game_id", entry.GameID, "error", err)
+	}
+}
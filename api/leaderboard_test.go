@@ -0,0 +1,33 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/leaderboard"
+)
+
+func TestRecordGameUsesFinishTime(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	session := game.NewGameSessionWithOptions(game.SessionOptions{OwnerID: "alice"})
+	if err := session.Resign(); err != nil {
+		t.Fatalf("Error resigning: %v", err)
+	}
+
+	// Recording late must not move the game into a later day or week
+	time.Sleep(10 * time.Millisecond)
+	s.recordGame(session)
+
+	rows, _ := s.leaderboard.Board(leaderboard.BoardAllTime, leaderboard.Query{})
+	if len(rows) != 1 {
+		t.Fatalf("Expected the game on the leaderboard, got %d rows", len(rows))
+	}
+	if !rows[0].FinishedAt.Equal(session.FinishedAt()) {
+		t.Errorf("Expected the entry to finish at %v, got %v", session.FinishedAt(), rows[0].FinishedAt)
+	}
+}
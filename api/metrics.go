@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/metrics"
+)
+
+// serverMetrics holds the metrics exposed at /metrics
+type serverMetrics struct {
+	registry        *metrics.Registry
+	gamesCreated    *metrics.Counter
+	gamesFinished   *metrics.Counter
+	moves           *metrics.Counter
+	errorResponses  *metrics.Counter
+	requestDuration *metrics.Histogram
+	evictions       *metrics.Counter
+	throttled       *metrics.Counter
+}
+
+// newServerMetrics registers the server metrics and hooks them up to the session manager
+func newServerMetrics(sessionManager *game.SessionManager) *serverMetrics {
+	registry := metrics.NewRegistry()
+
+	m := &serverMetrics{
+		registry:        registry,
+		gamesCreated:    registry.NewCounter("scoundrel_games_created_total", "Games created."),
+		gamesFinished:   registry.NewCounter("scoundrel_games_finished_total", "Games finished, by result.", "result"),
+		moves:           registry.NewCounter("scoundrel_moves_total", "Moves made, by type.", "type"),
+		errorResponses:  registry.NewCounter("scoundrel_http_error_responses_total", "HTTP responses with an error status, by code.", "code"),
+		requestDuration: registry.NewHistogram("scoundrel_http_request_duration_seconds", "HTTP request latency, by route and method.", metrics.DefaultBuckets, "route", "method"),
+		evictions:       registry.NewCounter("scoundrel_sessions_evicted_total", "Sessions removed by the reaper."),
+		throttled:       registry.NewCounter("scoundrel_throttled_requests_total", "Requests rejected by a rate limit, by limit.", "limit"),
+	}
+	registry.NewGaugeFunc("scoundrel_active_sessions", "Sessions currently held in memory.", func() float64 {
+		return float64(sessionManager.ActiveSessionCount())
+	})
+
+	sessionManager.OnCreate(func(*game.GameSession) {
+		m.gamesCreated.Inc()
+	})
+	sessionManager.OnMove(func(_ *game.GameSession, action game.Action) {
+		m.moves.Inc(action.Type.String())
+	})
+	sessionManager.OnGameOver(func(session *game.GameSession) {
+		if session.GetState() == game.GameStateWon {
+			m.gamesFinished.Inc("won")
+		} else {
+			m.gamesFinished.Inc("lost")
+		}
+	})
+
+	return m
+}
+
+// recordForcedEnd counts a game ended by an operator. Forced ends skip the
+// game over listeners, so they are counted here under their own result.
+func (m *serverMetrics) recordForcedEnd() {
+	m.gamesFinished.Inc("ended")
+}
+
+// recordEvictions counts sessions removed by the reaper
+func (m *serverMetrics) recordEvictions(n int) {
+	m.evictions.Add(float64(n))
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code before writing it
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records an implicit 200 before writing the body
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// methodLabel returns the metric label of an HTTP method. Clients can send
+// any method, so unknown ones share a label to keep cardinality bounded.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "other"
+	}
+}
+
+// middleware serves every request with router, recording latency per route
+// and counting error responses. It wraps the router rather than being
+// installed with Use, so requests matching no route are measured as well.
+func (m *serverMetrics) middleware(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Label by route template rather than path to keep cardinality bounded
+		route := "unmatched"
+		var match mux.RouteMatch
+		if router.Match(r, &match) && match.MatchErr == nil && match.Route != nil {
+			if template, err := match.Route.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		router.ServeHTTP(rec, r)
+
+		m.requestDuration.Observe(time.Since(start).Seconds(), route, methodLabel(r.Method))
+		if rec.status >= 400 {
+			m.errorResponses.Inc(strconv.Itoa(rec.status))
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// scrape fetches /metrics from a running server and parses the samples
+func scrape(t *testing.T, url string) map[string]float64 {
+	t.Helper()
+
+	resp, err := http.Get(url + "/metrics")
+	if err != nil {
+		t.Fatalf("Error scraping metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 from /metrics, got %d", resp.StatusCode)
+	}
+
+	samples := make(map[string]float64)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("Malformed sample %q: %v", line, err)
+		}
+		samples[line[:i]] = value
+	}
+	return samples
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+	doRequest(t, s, "POST", "/api/games/"+created.GameID+"/skip", "", "", nil)
+	doRequest(t, s, "POST", "/api/games/"+created.GameID+"/play/0", "", "", nil)
+	doRequest(t, s, "GET", "/api/games/missing", "", "", nil)
+	doRequest(t, s, "BREW", "/api/games/"+created.GameID+"/skip", "", "", nil)
+	doRequest(t, s, "PROPFIND", "/api/games/"+created.GameID+"/skip", "", "", nil)
+
+	samples := scrape(t, ts.URL)
+
+	// Unknown methods share a label
+	others := 0.0
+	for series, value := range samples {
+		if strings.Contains(series, "BREW") || strings.Contains(series, "PROPFIND") {
+			t.Errorf("Expected unknown methods to share a label, got %s", series)
+		}
+		if strings.HasPrefix(series, "scoundrel_http_request_duration_seconds_count{") && strings.Contains(series, `method="other"`) {
+			others += value
+		}
+	}
+	if others != 2 {
+		t.Errorf("Expected 2 requests with method \"other\", got %v", others)
+	}
+
+	checks := map[string]float64{
+		"scoundrel_active_sessions":                        1,
+		"scoundrel_games_created_total":                    1,
+		`scoundrel_moves_total{type="skip"}`:               1,
+		`scoundrel_moves_total{type="play"}`:               1,
+		`scoundrel_http_error_responses_total{code="404"}`: 3,
+		`scoundrel_http_request_duration_seconds_count{route="/api/games/{id}/skip",method="POST"}`: 1,
+	}
+	for series, want := range checks {
+		if got, ok := samples[series]; !ok || got != want {
+			t.Errorf("Expected %s = %v, got %v (present: %v)", series, want, got, ok)
+		}
+	}
+
+	// Evictions are counted once the reaper removes a session
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.sessionManager.RunReaper(ctx, time.Millisecond, 0, s.metrics.recordEvictions)
+	if got := scrape(t, ts.URL)["scoundrel_sessions_evicted_total"]; got != 1 {
+		t.Errorf("Expected 1 eviction, got %v", got)
+	}
+}
+
+func TestMetricsCountUnknownPathsAndForcedEnds(t *testing.T) {
+	config := testConfig("")
+	config.AdminToken = testAdminToken
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/no/such/page")
+	if err != nil {
+		t.Fatalf("Error requesting unknown path: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected 404 for an unknown path, got %d", resp.StatusCode)
+	}
+
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+	if code := doRequest(t, s, "POST", "/admin/api/sessions/"+created.GameID+"/end", testAdminToken, "", nil); code >= 300 {
+		t.Fatalf("Expected the admin to end the game, got %d", code)
+	}
+
+	samples := scrape(t, ts.URL)
+	checks := map[string]float64{
+		`scoundrel_http_error_responses_total{code="404"}`: 1,
+		`scoundrel_games_finished_total{result="ended"}`:   1,
+	}
+	for series, want := range checks {
+		if got, ok := samples[series]; !ok || got != want {
+			t.Errorf("Expected %s = %v, got %v (present: %v)", series, want, got, ok)
+		}
+	}
+}
+
+func TestMetricsMiddlewareLabelsUnmatchedRequests(t *testing.T) {
+	m := newServerMetrics(game.NewSessionManager())
+	router := mux.NewRouter()
+	router.HandleFunc("/known", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/unknown", nil),
+		httptest.NewRequest("POST", "/known", nil),
+	} {
+		m.middleware(router).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	ts := httptest.NewServer(m.registry.Handler())
+	defer ts.Close()
+	samples := scrape(t, ts.URL)
+	checks := map[string]float64{
+		`scoundrel_http_error_responses_total{code="404"}`:                               1,
+		`scoundrel_http_error_responses_total{code="405"}`:                               1,
+		`scoundrel_http_request_duration_seconds_count{route="unmatched",method="GET"}`:  1,
+		`scoundrel_http_request_duration_seconds_count{route="unmatched",method="POST"}`: 1,
+	}
+	for series, want := range checks {
+		if got, ok := samples[series]; !ok || got != want {
+			t.Errorf("Expected %s = %v, got %v (present: %v)", series, want, got, ok)
+		}
+	}
+}
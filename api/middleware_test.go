@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	var logs bytes.Buffer
+	s.logger = s.config.Logger(&logs)
+
+	handler := requestIDMiddleware(s.recoveryMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	req := httptest.NewRequest("GET", "/boom", nil)
+	req.Header.Set(requestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 after panic, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON error, got content type %q", ct)
+	}
+
+	var body struct {
+		Error     string `json:"error"`
+		RequestID string `json:"request_id"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Error decoding error body: %v", err)
+	}
+	if body.RequestID != "req-123" {
+		t.Errorf("Expected request ID req-123 in error body, got %q", body.RequestID)
+	}
+
+	if !strings.Contains(logs.String(), "boom") || !strings.Contains(logs.String(), "middleware_test.go") {
+		t.Errorf("Expected panic and stack trace in logs, got %s", logs.String())
+	}
+}
+
+func TestRequestLogging(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	var logs bytes.Buffer
+	s.logger = s.config.Logger(&logs)
+
+	req := httptest.NewRequest("GET", "/api/games/missing", nil)
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	id := rec.Header().Get(requestIDHeader)
+	if id == "" {
+		t.Fatal("Expected a generated X-Request-ID")
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected one JSON log line, got %s", logs.String())
+	}
+	for key, want := range map[string]interface{}{
+		"request_id": id,
+		"route":      "/api/games/{id}",
+		"game_id":    "missing",
+		"status":     float64(http.StatusNotFound),
+	} {
+		if entry[key] != want {
+			t.Errorf("Expected log %s %v, got %v", key, want, entry[key])
+		}
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	config := testConfig("")
+	config.MaxBodyBytes = 64
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	body := `{"name":"alice","password":"` + strings.Repeat("x", 100) + `"}`
+	if code := doRequest(t, s, "POST", "/api/register", "", body, nil); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for oversized body, got %d", code)
+	}
+}
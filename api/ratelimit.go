@@ -0,0 +1,75 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/ratelimit"
+)
+
+// rateLimit wraps a handler in a token-bucket limit shared by every route
+// using the same limiter, with a bucket per client as identified by keyOf.
+// A nil limiter disables the limit.
+func (s *Server) rateLimit(name string, limiter *ratelimit.Limiter, keyOf func(*http.Request) string, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, wait := limiter.Allow(keyOf(r))
+		if !allowed {
+			s.metrics.throttled.Inc(name)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "Too many requests, slow down", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newLimiter creates a limiter, or returns nil if rate is zero
+func newLimiter(rate float64, burst int) *ratelimit.Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return ratelimit.New(rate, burst)
+}
+
+// clientKey identifies the client of a request for rate limiting: the
+// player when authenticated, the IP otherwise
+func (s *Server) clientKey(r *http.Request) string {
+	if playerID := playerIDFromContext(r.Context()); playerID != "" {
+		return "player:" + playerID
+	}
+	return s.ipKey(r)
+}
+
+// ipKey identifies the client of a request by IP alone, for limits that new
+// accounts must not escape
+func (s *Server) ipKey(r *http.Request) string {
+	return "ip:" + clientIP(r, s.config.TrustProxy)
+}
+
+// clientIP returns the IP address of the client of a request. Behind a
+// trusted proxy it is the last address the proxy appended to X-Forwarded-For,
+// since earlier entries are supplied by the client.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			parts := strings.Split(forwarded, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
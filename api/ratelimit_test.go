@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateRateLimit(t *testing.T) {
+	config := testConfig("")
+	config.CreateRate = 0.01
+	config.CreateBurst = 2
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := doRequest(t, s, "POST", "/api/games", "", "", nil); code != http.StatusOK {
+			t.Fatalf("Expected game %d of the burst to be created, got %d", i+1, code)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/api/games", nil)
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 beyond the burst, got %d", rec.Code)
+	}
+	if retry := rec.Header().Get("Retry-After"); retry != "100" {
+		t.Errorf("Expected Retry-After of 100 seconds, got %q", retry)
+	}
+	if n := s.metrics.throttled.Value("create"); n != 1 {
+		t.Errorf("Expected 1 throttled create, got %v", n)
+	}
+
+	// A different client has its own bucket
+	req = httptest.NewRequest("POST", "/api/games", nil)
+	req.RemoteAddr = "198.51.100.7:4000"
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected another client to create a game, got %d", rec.Code)
+	}
+
+	// Moves have a separate limit
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	req = httptest.NewRequest("POST", "/api/games", nil)
+	req.RemoteAddr = "198.51.100.8:4000"
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected game to be created, got %d", rec.Code)
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("Error decoding created game: %v", err)
+	}
+	if code := doRequest(t, s, "POST", "/api/games/"+created.GameID+"/skip", "", "", nil); code != http.StatusOK {
+		t.Errorf("Expected move from a throttled creator to be allowed, got %d", code)
+	}
+}
+
+func TestAuthRateLimit(t *testing.T) {
+	config := testConfig("")
+	config.AuthRate = 0.01
+	config.AuthBurst = 2
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	for i, name := range []string{"alice", "bob"} {
+		body := `{"name":"` + name + `","password":"correct horse"}`
+		if code := doRequest(t, s, "POST", "/api/register", "", body, nil); code != http.StatusCreated {
+			t.Fatalf("Expected registration %d of the burst to succeed, got %d", i+1, code)
+		}
+	}
+
+	// Logins share the bucket of registrations
+	if code := doRequest(t, s, "POST", "/api/login", "", `{"name":"alice","password":"correct horse"}`, nil); code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 for a login beyond the burst, got %d", code)
+	}
+	if n := s.metrics.throttled.Value("auth"); n != 1 {
+		t.Errorf("Expected 1 throttled auth request, got %v", n)
+	}
+
+	// Another IP has its own bucket
+	req := httptest.NewRequest("POST", "/api/login", strings.NewReader(`{"name":"alice","password":"correct horse"}`))
+	req.RemoteAddr = "198.51.100.7:4000"
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected a login from another IP to succeed, got %d", rec.Code)
+	}
+}
+
+func TestHintRateLimit(t *testing.T) {
+	config := testConfig("")
+	config.HintRate = 0.01
+	config.HintBurst = 1
+	config.MoveRate = 0.01
+	config.MoveBurst = 1
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+	hint := "/api/games/" + created.GameID + "/hint"
+	if code := doRequest(t, s, "GET", hint, "", "", nil); code != http.StatusOK {
+		t.Fatalf("Expected the first hint to be given, got %d", code)
+	}
+	if code := doRequest(t, s, "GET", hint, "", "", nil); code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 beyond the hint burst, got %d", code)
+	}
+	if n := s.metrics.throttled.Value("hint"); n != 1 {
+		t.Errorf("Expected 1 throttled hint, got %v", n)
+	}
+
+	// Hints do not use up the move bucket
+	if code := doRequest(t, s, "POST", "/api/games/"+created.GameID+"/skip", "", "", nil); code != http.StatusOK {
+		t.Errorf("Expected a move after hints to be allowed, got %d", code)
+	}
+}
+
+func TestCreateRateLimitPerIP(t *testing.T) {
+	config := testConfig("")
+	config.CreateRate = 0.01
+	config.CreateBurst = 1
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var alice, bob struct {
+		Token string `json:"token"`
+	}
+	doRequest(t, s, "POST", "/api/register", "", `{"name":"alice","password":"correct horse"}`, &alice)
+	doRequest(t, s, "POST", "/api/register", "", `{"name":"bob","password":"correct horse"}`, &bob)
+
+	if code := doRequest(t, s, "POST", "/api/games", alice.Token, "", nil); code != http.StatusOK {
+		t.Fatalf("Expected alice to create a game, got %d", code)
+	}
+
+	// A second account from the same IP does not bring a new bucket
+	if code := doRequest(t, s, "POST", "/api/games", bob.Token, "", nil); code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 for another account from the same IP, got %d", code)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.9")
+
+	if ip := clientIP(req, false); ip != "10.0.0.1" {
+		t.Errorf("Expected remote address without a trusted proxy, got %s", ip)
+	}
+	if ip := clientIP(req, true); ip != "203.0.113.9" {
+		t.Errorf("Expected the address appended by the proxy, got %s", ip)
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/replay"
+)
+
+// ReplayHandler returns the seed and action log of a finished game
+func (h *Handler) ReplayHandler(w http.ResponseWriter, r *http.Request) {
+	// Get game ID from URL
+	vars := mux.Vars(r)
+	gameID := vars["id"]
+
+	rep, ok := h.viewReplay(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	// The spectator token is only handed out when the game is created
+	rep.SpectatorToken = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rep)
+}
+
+// ReplayStepHandler returns the state of a finished game after move N
+func (h *Handler) ReplayStepHandler(w http.ResponseWriter, r *http.Request) {
+	// Get game ID and step from URL
+	vars := mux.Vars(r)
+	gameID := vars["id"]
+	step, err := strconv.Atoi(vars["step"])
+	if err != nil {
+		http.Error(w, "Invalid step", http.StatusBadRequest)
+		return
+	}
+
+	rep, ok := h.viewReplay(w, r, gameID)
+	if !ok {
+		return
+	}
+
+	if step < 0 || step > len(rep.Actions) {
+		http.Error(w, "Step out of range", http.StatusBadRequest)
+		return
+	}
+
+	session, err := rep.StateAt(step)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The move that led to this state, if any
+	var action *game.Action
+	if step > 0 {
+		action = &rep.Actions[step-1]
+	}
+
+	response := map[string]interface{}{
+		"game_id": gameID,
+		"step":    step,
+		"total":   len(rep.Actions),
+		"action":  action,
+		"state":   session.View(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// viewReplay loads the replay of a game for a requester who may see it: the
+// owner of an owned game, or a spectator of a public game or holding its
+// spectator token. It writes an error response if there is none.
+func (h *Handler) viewReplay(w http.ResponseWriter, r *http.Request, gameID string) (game.Replay, bool) {
+	rep, err := h.replays.Get(gameID)
+	if err != nil {
+		writeReplayError(w, err)
+		return game.Replay{}, false
+	}
+
+	playerID := playerIDFromContext(r.Context())
+	token := r.URL.Query().Get("token")
+	if !rep.CanView(playerID, token) {
+		if playerID == "" && token == "" {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+		} else {
+			http.Error(w, "Game belongs to another player", http.StatusForbidden)
+		}
+		return game.Replay{}, false
+	}
+	return rep, true
+}
+
+// writeReplayError maps a replay store error to an HTTP response
+func writeReplayError(w http.ResponseWriter, err error) {
+	if errors.Is(err, replay.ErrNotFound) {
+		http.Error(w, "Replay not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// saveReplay keeps the seed and action log of a finished game
+func (s *Server) saveReplay(session *game.GameSession) {
+	if err := s.replays.Save(session.Replay()); err != nil {
+		s.logger.Error("failed to save replay", "game_id", session.GetID(), "error", err)
+	}
+}
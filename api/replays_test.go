@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestReplayAccess(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var alice, bob struct {
+		Token string `json:"token"`
+	}
+	doRequest(t, s, "POST", "/api/register", "", `{"name":"alice","password":"correct horse"}`, &alice)
+	doRequest(t, s, "POST", "/api/register", "", `{"name":"bob","password":"battery staple"}`, &bob)
+
+	var created struct {
+		GameID         string `json:"game_id"`
+		SpectatorToken string `json:"spectator_token"`
+	}
+	doRequest(t, s, "POST", "/api/games", alice.Token, "", &created)
+	session, err := s.sessionManager.GetSession(created.GameID)
+	if err != nil {
+		t.Fatalf("Error getting session: %v", err)
+	}
+	if err := s.replays.Save(session.Replay()); err != nil {
+		t.Fatalf("Error saving replay: %v", err)
+	}
+
+	// Private owned games are shown to their owner and their spectators only
+	tests := []struct {
+		name  string
+		token string
+		query string
+		want  int
+	}{
+		{"anonymous", "", "", http.StatusUnauthorized},
+		{"other player", bob.Token, "", http.StatusForbidden},
+		{"wrong spectator token", "", "?token=nope", http.StatusForbidden},
+		{"owner", alice.Token, "", http.StatusOK},
+		{"spectator", "", "?token=" + created.SpectatorToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{"/api/replays/" + created.GameID, "/api/replays/" + created.GameID + "/step/0"} {
+			if code := doRequest(t, s, "GET", path+tt.query, tt.token, "", nil); code != tt.want {
+				t.Errorf("%s: expected %d for %s, got %d", tt.name, tt.want, path, code)
+			}
+		}
+	}
+
+	// The spectator token is not handed out with the replay
+	var rep game.Replay
+	doRequest(t, s, "GET", "/api/replays/"+created.GameID, alice.Token, "", &rep)
+	if rep.GameID != created.GameID || rep.SpectatorToken != "" {
+		t.Errorf("Expected the replay without its spectator token, got %+v", rep)
+	}
+
+	// Anonymous games are open to anyone
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+	session, _ = s.sessionManager.GetSession(created.GameID)
+	s.replays.Save(session.Replay())
+	if code := doRequest(t, s, "GET", "/api/replays/"+created.GameID, "", "", nil); code != http.StatusOK {
+		t.Errorf("Expected 200 for an anonymous game's replay, got %d", code)
+	}
+}
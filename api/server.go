@@ -1,36 +1,133 @@
 package api
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/tippi-fifestarr/scoundrel/auth"
 	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/internal/jsonfile"
+	"github.com/tippi-fifestarr/scoundrel/leaderboard"
+	"github.com/tippi-fifestarr/scoundrel/replay"
+	"github.com/tippi-fifestarr/scoundrel/web"
 )
 
+// sessionsFile holds the sessions in progress across restarts
+const sessionsFile = "sessions.json"
+
 // Server represents the API server
 type Server struct {
 	router         *mux.Router
+	root           http.Handler
 	handler        *Handler
 	sessionManager *game.SessionManager
+	players        *auth.Store
+	leaderboard    *leaderboard.Leaderboard
+	replays        *replay.Store
+	metrics        *serverMetrics
+	config         Config
+	logger         *slog.Logger
+	static         http.Handler
+	idempotency    *idempotencyCache
+
+	// reaperRunning and draining are reported by the readiness check
+	reaperRunning atomic.Bool
+	draining      atomic.Bool
+
+	// dataDirErr is the result of the last write to the data directory by
+	// the readiness check, made at dataDirCheckedAt
+	dataDirMutex     sync.Mutex
+	dataDirErr       error
+	dataDirCheckedAt time.Time
 }
 
-// NewServer creates a new API server
-func NewServer() *Server {
+// NewServer creates a new API server from a validated config. Player
+// accounts, leaderboards and replays are stored in the config's data
+// directory; an empty data directory keeps everything in memory.
+func NewServer(config Config) (*Server, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	dataDir := config.DataDir
+
+	// Create the data directory up front, so the readiness check only has
+	// to look at it
+	if dataDir != "" {
+		if err := os.MkdirAll(dataDir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating data directory: %w", err)
+		}
+	}
+
+	players, err := auth.NewStore(dataPath(dataDir, "players.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	lb, err := leaderboard.New(dataPath(dataDir, "leaderboard.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	replays := replay.NewStore(dataPath(dataDir, "replays"))
+
 	sessionManager := game.NewSessionManager()
-	handler := NewHandler(sessionManager)
+	handler := NewHandler(sessionManager, players, lb, replays)
+	handler.maxSessions = config.MaxSessions
 	router := mux.NewRouter()
 
 	server := &Server{
 		router:         router,
 		handler:        handler,
 		sessionManager: sessionManager,
+		players:        players,
+		leaderboard:    lb,
+		replays:        replays,
+		metrics:        newServerMetrics(sessionManager),
+		config:         config,
+		logger:         config.Logger(os.Stderr),
+		idempotency:    newIdempotencyCache(),
+	}
+
+	// Serve the embedded frontend, or the one on disk in dev mode
+	if config.Dev {
+		server.static = devStaticHandler(config.StaticDir)
+	} else if server.static, err = staticHandler(web.Files); err != nil {
+		return nil, err
+	}
+
+	sessionManager.OnGameOver(server.saveReplay)
+	sessionManager.OnGameOver(server.recordGame)
+
+	if err := server.restoreSessions(); err != nil {
+		return nil, err
 	}
 
 	server.setupRoutes()
-	return server
+	return server, nil
+}
+
+// Handler returns the HTTP handler serving every route, for embedding the
+// server in tests or another mux
+func (s *Server) Handler() http.Handler {
+	return s.root
+}
+
+// dataPath returns the path of a file in the data directory, or "" if there is none
+func dataPath(dataDir, name string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, name)
 }
 
 // setupRoutes configures the API routes
@@ -38,62 +135,214 @@ func (s *Server) setupRoutes() {
 	// API version prefix
 	api := s.router.PathPrefix("/api").Subrouter()
 
+	// Registration and login are rate limited per IP, since each hashes a
+	// password and a new account would otherwise bring fresh buckets
+	authLimit := newLimiter(s.config.AuthRate, s.config.AuthBurst)
+
+	// Player routes
+	api.Handle("/register", s.rateLimit("auth", authLimit, s.ipKey, http.HandlerFunc(s.handler.RegisterHandler))).Methods("POST")
+	api.Handle("/login", s.rateLimit("auth", authLimit, s.ipKey, http.HandlerFunc(s.handler.LoginHandler))).Methods("POST")
+	api.HandleFunc("/logout", s.handler.LogoutHandler).Methods("POST")
+	api.HandleFunc("/me/games", s.handler.MyGamesHandler).Methods("GET")
+
+	// Game creation and moves are rate limited per client, and can be
+	// retried safely with an Idempotency-Key header. Game creation is also
+	// limited per IP, so that players cannot escape it with new accounts.
+	createLimit := newLimiter(s.config.CreateRate, s.config.CreateBurst)
+	createIPLimit := newLimiter(s.config.CreateRate, s.config.CreateBurst)
+	moveLimit := newLimiter(s.config.MoveRate, s.config.MoveBurst)
+	limitCreate := func(next http.HandlerFunc) http.Handler {
+		return s.rateLimit("create", createLimit, s.clientKey, s.rateLimit("create", createIPLimit, s.ipKey, next))
+	}
+	limitMove := func(next http.HandlerFunc) http.Handler {
+		return s.rateLimit("move", moveLimit, s.clientKey, next)
+	}
+
+	// Hints run a search, so they have their own, stricter limit
+	hintLimit := newLimiter(s.config.HintRate, s.config.HintBurst)
+
 	// Game routes
-	api.HandleFunc("/games", s.handler.CreateGameHandler).Methods("POST")
+	api.Handle("/games", s.idempotent(limitCreate(s.handler.CreateGameHandler))).Methods("POST")
+	api.HandleFunc("/games/live", s.handler.LiveGamesHandler).Methods("GET")
 	api.HandleFunc("/games/{id}", s.handler.GetGameHandler).Methods("GET")
-	api.HandleFunc("/games/{id}/play/{index}", s.handler.PlayCardHandler).Methods("POST")
-	api.HandleFunc("/games/{id}/play-without-weapon/{index}", s.handler.PlayCardWithoutWeaponHandler).Methods("POST")
-	api.HandleFunc("/games/{id}/skip", s.handler.SkipRoomHandler).Methods("POST")
+	api.Handle("/games/{id}/play/{index}", s.idempotent(limitMove(s.handler.PlayCardHandler))).Methods("POST")
+	api.Handle("/games/{id}/play-without-weapon/{index}", s.idempotent(limitMove(s.handler.PlayCardWithoutWeaponHandler))).Methods("POST")
+	api.Handle("/games/{id}/skip", s.idempotent(limitMove(s.handler.SkipRoomHandler))).Methods("POST")
+	api.Handle("/games/{id}/hint", s.rateLimit("hint", hintLimit, s.clientKey, http.HandlerFunc(s.handler.HintHandler))).Methods("GET")
+
+	// Tutorial routes
+	api.HandleFunc("/tutorial", s.handler.TutorialHandler).Methods("GET")
+
+	// Daily challenge routes
+	api.Handle("/daily", s.idempotent(limitCreate(s.handler.DailyHandler))).Methods("POST")
+
+	// Leaderboard routes
+	api.HandleFunc("/leaderboards/{board}", s.handler.LeaderboardHandler).Methods("GET")
+
+	// Replay routes
+	api.HandleFunc("/replays/{id}", s.handler.ReplayHandler).Methods("GET")
+	api.HandleFunc("/replays/{id}/step/{step}", s.handler.ReplayStepHandler).Methods("GET")
+
+	// Spectator routes
+	api.HandleFunc("/games/{id}/spectate", s.handler.SpectateGameHandler).Methods("GET")
+	api.HandleFunc("/games/{id}/spectate/stream", s.handler.SpectateStreamHandler).Methods("GET")
+
+	// Metrics
+	s.router.Handle("/metrics", s.metrics.registry.Handler()).Methods("GET")
+
+	// Health checks
+	s.router.HandleFunc("/healthz", s.HealthzHandler).Methods("GET")
+	s.router.HandleFunc("/readyz", s.ReadyzHandler).Methods("GET")
+
+	// Admin routes
+	s.setupAdminRoutes()
 
 	// Root handler
-	s.router.PathPrefix("/").Handler(http.FileServer(http.Dir("./web")))
+	s.router.PathPrefix("/").Handler(s.static)
 
-	// Apply middleware
-	s.router.Use(loggingMiddleware)
-	s.router.Use(corsMiddleware)
+	// Apply middleware, outermost first
+	s.router.Use(requestIDMiddleware)
+	s.router.Use(s.loggingMiddleware)
+	s.router.Use(s.recoveryMiddleware)
+	s.router.Use(bodyLimitMiddleware(s.config.MaxBodyBytes))
+	s.router.Use(corsMiddleware(s.config.CORSOrigins))
+	api.Use(authMiddleware(s.players))
+
+	// Metrics wrap the router so that unmatched requests are counted too
+	s.root = s.metrics.middleware(s.router)
 }
 
-// Start starts the HTTP server
-func (s *Server) Start(addr string) error {
-	// Use PORT from environment if provided (for Render)
-	port := os.Getenv("PORT")
-	if port != "" {
-		addr = "0.0.0.0:" + port
+// Run listens on the configured address and serves until ctx is cancelled,
+// then shuts down gracefully
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return err
 	}
 
+	s.logger.Info("server starting", "addr", s.config.Addr)
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln until ctx is cancelled. It then stops
+// accepting connections, closes spectator streams, waits up to the shutdown
+// timeout for in-flight requests to finish, stops background workers and
+// saves the sessions still in progress to the data directory.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
 	srv := &http.Server{
-		Handler:      s.router,
-		Addr:         addr,
-		WriteTimeout: 15 * time.Second,
-		ReadTimeout:  15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Handler:      s.root,
+		WriteTimeout: s.config.WriteTimeout,
+		ReadTimeout:  s.config.ReadTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
 	}
+	srv.RegisterOnShutdown(s.handler.closeStreams)
+
+	// Evict finished and abandoned sessions in the background
+	workers, stopWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	s.reaperRunning.Store(true)
+	go func() {
+		defer wg.Done()
+		defer s.reaperRunning.Store(false)
+		s.sessionManager.RunReaper(workers, s.config.ReaperInterval, s.config.SessionMaxAge, s.metrics.recordEvictions)
+	}()
+	defer func() {
+		stopWorkers()
+		wg.Wait()
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	s.logger.Info("shutting down, draining in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	shutdownErr := srv.Shutdown(shutdownCtx)
+	<-serveErr
 
-	log.Printf("Server starting on %s", addr)
-	return srv.ListenAndServe()
+	stopWorkers()
+	wg.Wait()
+
+	return errors.Join(shutdownErr, s.saveSessions())
 }
 
-// loggingMiddleware logs HTTP requests
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s %v", r.Method, r.URL.Path, time.Since(start))
-	})
+// saveSessions writes the sessions still in progress to the data directory
+func (s *Server) saveSessions() error {
+	path := dataPath(s.config.DataDir, sessionsFile)
+	if path == "" {
+		return nil
+	}
+
+	snapshots := s.sessionManager.Snapshot()
+	s.logger.Info("saving sessions in progress", "count", len(snapshots))
+	return jsonfile.Save(path, snapshots)
 }
 
-// corsMiddleware adds CORS headers
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// restoreSessions loads the sessions saved at the last shutdown. The file is
+// removed afterwards so a crash never resurrects stale games.
+func (s *Server) restoreSessions() error {
+	path := dataPath(s.config.DataDir, sessionsFile)
+	if path == "" {
+		return nil
+	}
+
+	var snapshots []game.SessionSnapshot
+	if err := jsonfile.Load(path, &snapshots); err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+	if err := s.sessionManager.Restore(snapshots); err != nil {
+		s.logger.Warn("some sessions could not be restored", "error", err)
+	}
+	s.logger.Info("restored sessions", "count", s.sessionManager.ActiveSessionCount())
+
+	return os.Remove(path)
+}
+
+// corsMiddleware adds CORS headers for the allowed origins. "*" allows any origin.
+func corsMiddleware(origins []string) mux.MiddlewareFunc {
+	allowAll := false
+	allowed := make(map[string]bool)
+	for _, origin := range origins {
+		if origin == "*" {
+			allowAll = true
 		}
+		allowed[origin] = true
+	}
 
-		next.ServeHTTP(w, r)
-	})
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			switch {
+			case allowAll:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			case allowed[origin]:
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Request-ID")
+
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testConfig returns the default config with the given data directory and
+// no rate limits
+func testConfig(dataDir string) Config {
+	config := DefaultConfig()
+	config.DataDir = dataDir
+	config.CreateRate = 0
+	config.MoveRate = 0
+	config.HintRate = 0
+	config.AuthRate = 0
+	return config
+}
+
+func TestShutdownDrainsInFlightMove(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewServer(testConfig(dir))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var created struct {
+		GameID string `json:"game_id"`
+	}
+	doRequest(t, s, "POST", "/api/games", "", "", &created)
+
+	// Hold move requests until the server has started shutting down
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	s.router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				close(entered)
+				<-release
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(ctx, ln)
+	}()
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Post("http://"+ln.Addr().String()+"/api/games/"+created.GameID+"/skip", "application/json", nil)
+		if err != nil {
+			t.Errorf("Error sending move: %v", err)
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+
+	<-entered
+	cancel()
+	// Give Shutdown time to close the listener before the move completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if code := <-status; code != http.StatusOK {
+		t.Errorf("Expected in-flight move to complete with 200, got %d", code)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+
+	session, err := s.sessionManager.GetSession(created.GameID)
+	if err != nil {
+		t.Fatalf("Error getting session: %v", err)
+	}
+	if n := len(session.Actions()); n != 1 {
+		t.Errorf("Expected the move to be applied, got %d actions", n)
+	}
+
+	// The session in progress is restored by the next server
+	restarted, err := NewServer(testConfig(dir))
+	if err != nil {
+		t.Fatalf("Error restarting server: %v", err)
+	}
+	restored, err := restarted.sessionManager.GetSession(created.GameID)
+	if err != nil {
+		t.Fatalf("Expected session to be restored: %v", err)
+	}
+	if n := len(restored.Actions()); n != 1 {
+		t.Errorf("Expected restored session to have 1 action, got %d", n)
+	}
+}
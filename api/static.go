@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// contentTypes maps the extensions of web assets to their content types, so
+// responses do not depend on the host's MIME tables
+var contentTypes = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".css":  "text/css; charset=utf-8",
+	".js":   "text/javascript; charset=utf-8",
+	".json": "application/json",
+	".svg":  "image/svg+xml",
+	".png":  "image/png",
+	".ico":  "image/x-icon",
+}
+
+// asset is an embedded file with its precomputed ETag
+type asset struct {
+	data []byte
+	etag string
+}
+
+// staticHandler serves the web assets of fsys. Every file is read and hashed
+// once up front; responses carry a strong ETag and must be revalidated, so a
+// deploy is picked up on the next load while unchanged files cost a 304.
+func staticHandler(fsys fs.FS) (http.Handler, error) {
+	assets := make(map[string]asset)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(name, ".go") {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		assets["/"+name] = asset{data: data, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := path.Clean("/" + r.URL.Path)
+		if strings.HasSuffix(name, "/") {
+			name += "index.html"
+		}
+
+		a, exists := assets[name]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		setContentType(w, name)
+		w.Header().Set("ETag", a.etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(a.data))
+	}), nil
+}
+
+// devStaticHandler serves the web assets from a directory on disk, uncached,
+// so edits show up on reload
+func devStaticHandler(dir string) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setContentType(w, r.URL.Path)
+		w.Header().Set("Cache-Control", "no-store")
+		files.ServeHTTP(w, r)
+	})
+}
+
+// setContentType sets the content type of a known asset extension
+func setContentType(w http.ResponseWriter, name string) {
+	if contentType, known := contentTypes[path.Ext(name)]; known {
+		w.Header().Set("Content-Type", contentType)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// get serves a GET request and returns the recorder
+func get(s *Server, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEmbeddedStaticFiles(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	for path, contentType := range map[string]string{
+		"/":               "text/html; charset=utf-8",
+		"/replay.html":    "text/html; charset=utf-8",
+		"/css/styles.css": "text/css; charset=utf-8",
+		"/js/game.js":     "text/javascript; charset=utf-8",
+	} {
+		rec := get(s, path, nil)
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected 200 for %s, got %d", path, rec.Code)
+			continue
+		}
+		if got := rec.Header().Get("Content-Type"); got != contentType {
+			t.Errorf("Expected content type %q for %s, got %q", contentType, path, got)
+		}
+		if rec.Header().Get("ETag") == "" {
+			t.Errorf("Expected ETag for %s", path)
+		}
+	}
+
+	// Unchanged files are revalidated without a body
+	etag := get(s, "/js/game.js", nil).Header().Get("ETag")
+	rec := get(s, "/js/game.js", map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for matching ETag, got %d", rec.Code)
+	}
+
+	if rec := get(s, "/embed.go", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for non-asset file, got %d", rec.Code)
+	}
+	if rec := get(s, "/missing.js", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing file, got %d", rec.Code)
+	}
+}
+
+func TestDevStaticFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<p>live edit</p>"), 0o644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	config := testConfig("")
+	config.Dev = true
+	config.StaticDir = dir
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	rec := get(s, "/", nil)
+	if !strings.Contains(rec.Body.String(), "live edit") {
+		t.Errorf("Expected file from disk in dev mode, got %q", rec.Body.String())
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Expected no-store in dev mode, got %q", cc)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// lessonSummary describes a tutorial lesson
+type lessonSummary struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Intro string `json:"intro"`
+	Steps int    `json:"steps"`
+}
+
+// TutorialHandler lists the tutorial lessons in teaching order. A lesson is
+// started by creating a game with its name as the "tutorial" option.
+func (h *Handler) TutorialHandler(w http.ResponseWriter, r *http.Request) {
+	lessons := make([]lessonSummary, 0, len(game.Lessons))
+	for _, lesson := range game.Lessons {
+		lessons = append(lessons, lessonSummary{
+			Name:  lesson.Name,
+			Title: lesson.Title,
+			Intro: lesson.Intro,
+			Steps: len(lesson.Steps),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lessons)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestTutorialGame(t *testing.T) {
+	s, err := NewServer(testConfig(""))
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+
+	var lessons []lessonSummary
+	doRequest(t, s, "GET", "/api/tutorial", "", "", &lessons)
+	if len(lessons) != len(game.Lessons) || lessons[0].Name != "weapons" {
+		t.Errorf("Expected the lessons in teaching order, got %+v", lessons)
+	}
+
+	if code := doRequest(t, s, "POST", "/api/games", "", `{"tutorial":"juggling"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown lesson, got %d", code)
+	}
+
+	var player struct {
+		Token string `json:"token"`
+	}
+	doRequest(t, s, "POST", "/api/register", "", `{"name":"alice","password":"correct horse"}`, &player)
+
+	var created struct {
+		GameID   string `json:"game_id"`
+		Tutorial string `json:"tutorial"`
+	}
+	doRequest(t, s, "POST", "/api/games", player.Token, `{"tutorial":"weapons"}`, &created)
+	if created.Tutorial != "weapons" {
+		t.Errorf("Expected a weapons tutorial, got %q", created.Tutorial)
+	}
+
+	var view game.View
+	doRequest(t, s, "GET", "/api/games/"+created.GameID, player.Token, "", &view)
+	if view.Tutorial == nil || view.Tutorial.Prompt != game.Lessons[0].Steps[0].Prompt {
+		t.Fatalf("Expected the first step's prompt, got %+v", view.Tutorial)
+	}
+
+	// Moves other than the one the step teaches are rejected
+	if code := doRequest(t, s, "POST", "/api/games/"+created.GameID+"/skip", player.Token, "", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a move the lesson did not ask for, got %d", code)
+	}
+
+	for range game.Lessons[0].Steps {
+		if code := doRequest(t, s, "POST", "/api/games/"+created.GameID+"/play/0", player.Token, "", &view); code != http.StatusOK {
+			t.Fatalf("Expected 200 playing the lesson, got %d", code)
+		}
+	}
+	if view.State != game.GameStateWon.String() || view.Tutorial.Outro == "" {
+		t.Errorf("Expected the lesson to end won with its outro, got %s %+v", view.State, view.Tutorial)
+	}
+
+	// Tutorial games are never ranked
+	var board struct {
+		Entries []interface{} `json:"entries"`
+	}
+	doRequest(t, s, "GET", "/api/leaderboards/all-time", "", "", &board)
+	if len(board.Entries) != 0 {
+		t.Errorf("Expected tutorial game to stay off the leaderboard, got %d entries", len(board.Entries))
+	}
+}
@@ -0,0 +1,354 @@
+// Package auth manages player accounts and API tokens
+package auth
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tippi-fifestarr/scoundrel/internal/jsonfile"
+)
+
+var (
+	// ErrNameTaken is returned when registering a name that already exists
+	ErrNameTaken = errors.New("player name already taken")
+	// ErrInvalidName is returned when a player name does not match the allowed format
+	ErrInvalidName = errors.New("player name must be 3-32 letters, digits, '-' or '_'")
+	// ErrWeakPassword is returned when a password is too short
+	ErrWeakPassword = errors.New("password must be at least 8 characters")
+	// ErrInvalidCredentials is returned when a name and password do not match
+	ErrInvalidCredentials = errors.New("invalid name or password")
+	// ErrInvalidToken is returned when a bearer token is unknown or expired
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// passwordIterations is the PBKDF2 work factor for password hashes
+var passwordIterations = 100000
+
+var (
+	// tokenTTL is how long an API token stays valid after it is issued
+	tokenTTL = 30 * 24 * time.Hour
+	// maxTokensPerPlayer bounds the live tokens of a player; issuing one
+	// more revokes the oldest
+	maxTokensPerPlayer = 10
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+// Player represents a registered player account
+type Player struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"password_hash"`
+	Salt         string    `json:"salt"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// tokenRecord is the player an API token belongs to and when it expires
+type tokenRecord struct {
+	PlayerID  string    `json:"player_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// storeData is the on-disk representation of the store
+type storeData struct {
+	Players []*Player `json:"players"`
+	// Tokens maps the SHA-256 hash of a token to its record
+	Tokens map[string]tokenRecord `json:"tokens"`
+}
+
+// Store is a local credential store for players and their API tokens.
+// Only hashes of passwords and tokens are kept.
+type Store struct {
+	path    string
+	players map[string]*Player // by ID
+	byName  map[string]*Player
+	tokens  map[string]tokenRecord
+	mutex   sync.RWMutex
+}
+
+// NewStore opens the credential store at path. An empty path keeps the
+// store in memory only.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		players: make(map[string]*Player),
+		byName:  make(map[string]*Player),
+		tokens:  make(map[string]tokenRecord),
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	var data storeData
+	if err := jsonfile.Load(path, &data); err != nil {
+		return nil, err
+	}
+	for _, p := range data.Players {
+		s.players[p.ID] = p
+		s.byName[p.Name] = p
+	}
+	for hash, record := range data.Tokens {
+		s.tokens[hash] = record
+	}
+	s.pruneTokens(time.Now())
+
+	return s, nil
+}
+
+// Register creates a new player account and returns it with a fresh API token
+func (s *Store) Register(name, password string) (*Player, string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, "", ErrInvalidName
+	}
+	if len(password) < 8 {
+		return nil, "", ErrWeakPassword
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, "", err
+	}
+	hash, err := hashPassword(password, salt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.byName[name]; exists {
+		return nil, "", ErrNameTaken
+	}
+
+	player := &Player{
+		ID:           uuid.New().String(),
+		Name:         name,
+		PasswordHash: hex.EncodeToString(hash),
+		Salt:         hex.EncodeToString(salt),
+		CreatedAt:    time.Now().UTC(),
+	}
+	s.players[player.ID] = player
+	s.byName[name] = player
+
+	token, err := s.issueToken(player.ID)
+	if err == nil {
+		err = s.save()
+	}
+	if err != nil {
+		// Free the name again so the player can retry
+		delete(s.players, player.ID)
+		delete(s.byName, name)
+		delete(s.tokens, hashToken(token))
+		return nil, "", err
+	}
+
+	return player, token, nil
+}
+
+// Login checks a name and password and returns the player with a fresh API token
+func (s *Store) Login(name, password string) (*Player, string, error) {
+	s.mutex.RLock()
+	player, exists := s.byName[name]
+	s.mutex.RUnlock()
+	if !exists {
+		return nil, "", ErrInvalidCredentials
+	}
+
+	salt, err := hex.DecodeString(player.Salt)
+	if err != nil {
+		return nil, "", err
+	}
+	want, err := hex.DecodeString(player.PasswordHash)
+	if err != nil {
+		return nil, "", err
+	}
+	got, err := hashPassword(password, salt)
+	if err != nil {
+		return nil, "", err
+	}
+	if subtle.ConstantTimeCompare(got, want) != 1 {
+		return nil, "", ErrInvalidCredentials
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Issuing may prune or revoke the player's tokens, so a failed save
+	// restores the whole list
+	saved := s.playerTokens(player.ID)
+	token, err := s.issueToken(player.ID)
+	if err == nil {
+		err = s.save()
+	}
+	if err != nil {
+		s.restoreTokens(player.ID, saved)
+		return nil, "", err
+	}
+
+	return player, token, nil
+}
+
+// Logout revokes an API token
+func (s *Store) Logout(token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hash := hashToken(token)
+	record, exists := s.tokens[hash]
+	if !exists {
+		return ErrInvalidToken
+	}
+
+	delete(s.tokens, hash)
+	if err := s.save(); err != nil {
+		s.tokens[hash] = record
+		return err
+	}
+	return nil
+}
+
+// Authenticate returns the player owning an API token
+func (s *Store) Authenticate(token string) (*Player, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	record, exists := s.tokens[hashToken(token)]
+	if !exists || !time.Now().Before(record.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	player, exists := s.players[record.PlayerID]
+	if !exists {
+		return nil, ErrInvalidToken
+	}
+
+	return player, nil
+}
+
+// GetPlayer returns a player by ID
+func (s *Store) GetPlayer(id string) (*Player, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	player, exists := s.players[id]
+	return player, exists
+}
+
+// issueToken creates a new API token for a player, after dropping expired
+// tokens and the player's oldest beyond maxTokensPerPlayer.
+// Must be called with the write lock held.
+func (s *Store) issueToken(playerID string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	s.pruneTokens(now)
+	for s.countTokens(playerID) >= maxTokensPerPlayer {
+		s.revokeOldest(playerID)
+	}
+
+	token := hex.EncodeToString(raw)
+	s.tokens[hashToken(token)] = tokenRecord{PlayerID: playerID, ExpiresAt: now.Add(tokenTTL)}
+
+	return token, nil
+}
+
+// pruneTokens drops expired tokens.
+// Must be called with the write lock held.
+func (s *Store) pruneTokens(now time.Time) {
+	for hash, record := range s.tokens {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+}
+
+// countTokens returns the number of tokens of a player.
+// Must be called with the lock held.
+func (s *Store) countTokens(playerID string) int {
+	n := 0
+	for _, record := range s.tokens {
+		if record.PlayerID == playerID {
+			n++
+		}
+	}
+	return n
+}
+
+// revokeOldest drops the token of a player that expires first.
+// Must be called with the write lock held.
+func (s *Store) revokeOldest(playerID string) {
+	var oldestHash string
+	var oldest time.Time
+	for hash, record := range s.tokens {
+		if record.PlayerID == playerID && (oldestHash == "" || record.ExpiresAt.Before(oldest)) {
+			oldestHash, oldest = hash, record.ExpiresAt
+		}
+	}
+	delete(s.tokens, oldestHash)
+}
+
+// playerTokens returns a copy of the tokens of a player, keyed by hash.
+// Must be called with the lock held.
+func (s *Store) playerTokens(playerID string) map[string]tokenRecord {
+	tokens := make(map[string]tokenRecord)
+	for hash, record := range s.tokens {
+		if record.PlayerID == playerID {
+			tokens[hash] = record
+		}
+	}
+	return tokens
+}
+
+// restoreTokens replaces the tokens of a player with those returned by
+// playerTokens. Must be called with the write lock held.
+func (s *Store) restoreTokens(playerID string, tokens map[string]tokenRecord) {
+	for hash, record := range s.tokens {
+		if record.PlayerID == playerID {
+			delete(s.tokens, hash)
+		}
+	}
+	for hash, record := range tokens {
+		s.tokens[hash] = record
+	}
+}
+
+// save writes the store to disk if it has a path.
+// Must be called with the lock held.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data := storeData{
+		Players: make([]*Player, 0, len(s.players)),
+		Tokens:  s.tokens,
+	}
+	for _, p := range s.players {
+		data.Players = append(data.Players, p)
+	}
+
+	return jsonfile.Save(s.path, data)
+}
+
+// hashPassword derives the stored hash of a password
+func hashPassword(password string, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, password, salt, passwordIterations, 32)
+}
+
+// hashToken returns the stored form of an API token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatalf("Error creating store: %v", err)
+	}
+
+	player, token, err := store.Register("alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering player: %v", err)
+	}
+
+	// The registration token should authenticate the player
+	got, err := store.Authenticate(token)
+	if err != nil || got.ID != player.ID {
+		t.Errorf("Expected registration token to authenticate %s, got %v (%v)", player.ID, got, err)
+	}
+
+	// Duplicate names are rejected
+	if _, _, err := store.Register("alice", "another password"); !errors.Is(err, ErrNameTaken) {
+		t.Errorf("Expected ErrNameTaken, got %v", err)
+	}
+
+	// Wrong password is rejected
+	if _, _, err := store.Login("alice", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+
+	// Right password issues a new working token
+	_, loginToken, err := store.Login("alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Error logging in: %v", err)
+	}
+	if loginToken == token {
+		t.Errorf("Expected login to issue a new token")
+	}
+	if _, err := store.Authenticate(loginToken); err != nil {
+		t.Errorf("Expected login token to authenticate, got %v", err)
+	}
+
+	// Unknown tokens are rejected
+	if _, err := store.Authenticate("not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	store, _ := NewStore("")
+
+	if _, _, err := store.Register("a", "long enough"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName for short name, got %v", err)
+	}
+	if _, _, err := store.Register("bob", "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("Expected ErrWeakPassword for short password, got %v", err)
+	}
+}
+
+func TestStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "players.json")
+
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Error creating store: %v", err)
+	}
+	player, token, err := store.Register("carol", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering player: %v", err)
+	}
+
+	// Reopen the store from disk
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatalf("Error reopening store: %v", err)
+	}
+	got, err := reopened.Authenticate(token)
+	if err != nil || got.ID != player.ID {
+		t.Errorf("Expected token to survive a restart, got %v (%v)", got, err)
+	}
+	if _, _, err := reopened.Login("carol", "correct horse"); err != nil {
+		t.Errorf("Expected password to survive a restart, got %v", err)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	store, _ := NewStore("")
+	_, token, err := store.Register("dave", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering player: %v", err)
+	}
+
+	// Age the token past its lifetime
+	for hash, record := range store.tokens {
+		record.ExpiresAt = time.Now().Add(-time.Second)
+		store.tokens[hash] = record
+	}
+	if _, err := store.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for expired token, got %v", err)
+	}
+
+	// Logging in again prunes it
+	if _, _, err := store.Login("dave", "correct horse"); err != nil {
+		t.Fatalf("Error logging in: %v", err)
+	}
+	if len(store.tokens) != 1 {
+		t.Errorf("Expected expired token to be pruned, got %d tokens", len(store.tokens))
+	}
+}
+
+func TestTokenLimit(t *testing.T) {
+	store, _ := NewStore("")
+	_, first, err := store.Register("erin", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering player: %v", err)
+	}
+
+	for i := 0; i < maxTokensPerPlayer; i++ {
+		if _, _, err := store.Login("erin", "correct horse"); err != nil {
+			t.Fatalf("Error logging in: %v", err)
+		}
+	}
+	if len(store.tokens) != maxTokensPerPlayer {
+		t.Errorf("Expected %d tokens, got %d", maxTokensPerPlayer, len(store.tokens))
+	}
+	if _, err := store.Authenticate(first); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected the oldest token to be revoked, got %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	store, _ := NewStore("")
+	_, token, err := store.Register("frank", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering player: %v", err)
+	}
+
+	if err := store.Logout(token); err != nil {
+		t.Fatalf("Error logging out: %v", err)
+	}
+	if _, err := store.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken after logout, got %v", err)
+	}
+	if err := store.Logout(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken logging out twice, got %v", err)
+	}
+}
+
+func TestRegisterRollsBackFailedSave(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "players.json"))
+	if err != nil {
+		t.Fatalf("Error creating store: %v", err)
+	}
+
+	// A file in place of the data directory makes every save fail
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+	store.path = filepath.Join(blocker, "players.json")
+
+	if _, _, err := store.Register("gina", "correct horse"); err == nil {
+		t.Fatal("Expected registration to fail when the store cannot be saved")
+	}
+	if len(store.byName) != 0 || len(store.players) != 0 || len(store.tokens) != 0 {
+		t.Errorf("Expected failed registration to leave no player or token behind")
+	}
+
+	// The name is free again once saving works
+	store.path = filepath.Join(dir, "players.json")
+	if _, _, err := store.Register("gina", "correct horse"); err != nil {
+		t.Errorf("Expected registration to succeed after a failed save, got %v", err)
+	}
+}
+
+func TestLoginRestoresTokensOnFailedSave(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "players.json"))
+	if err != nil {
+		t.Fatalf("Error creating store: %v", err)
+	}
+	_, first, err := store.Register("hank", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering player: %v", err)
+	}
+	for i := 1; i < maxTokensPerPlayer; i++ {
+		if _, _, err := store.Login("hank", "correct horse"); err != nil {
+			t.Fatalf("Error logging in: %v", err)
+		}
+	}
+
+	// At the limit, a login revokes the oldest token before saving
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+	store.path = filepath.Join(blocker, "players.json")
+
+	if _, _, err := store.Login("hank", "correct horse"); err == nil {
+		t.Fatal("Expected login to fail when the store cannot be saved")
+	}
+	if len(store.tokens) != maxTokensPerPlayer {
+		t.Errorf("Expected %d tokens after a failed login, got %d", maxTokensPerPlayer, len(store.tokens))
+	}
+	if _, err := store.Authenticate(first); err != nil {
+		t.Errorf("Expected the oldest token to survive a failed login, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package bot provides automated Scoundrel players
+package bot
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Strategy chooses moves from the visible state of a game. Strategies see
+// only what a player sees, so they can run in process or behind the engine
+// protocol.
+type Strategy interface {
+	// Name identifies the strategy in logs and results
+	Name() string
+	// Move returns the next move. It must be one of LegalMoves(view).
+	Move(view game.View) game.Action
+}
+
+// factories builds the built-in strategies by name
+var factories = map[string]func(seed int64) Strategy{
+	"random":     func(seed int64) Strategy { return NewRandom(seed) },
+	"expectimax": func(seed int64) Strategy { return NewExpectimax(DefaultDepth) },
+	"greedy":     func(seed int64) Strategy { return NewGreedy() },
+	"heuristic":  func(seed int64) Strategy { return NewHeuristic(DefaultWeights) },
+}
+
+// New returns the built-in strategy with the given name. The seed drives
+// strategies that make random choices.
+func New(name string, seed int64) (Strategy, error) {
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+	return factory(seed), nil
+}
+
+// Names returns the names of the built-in strategies, sorted
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Play makes moves chosen by s until the game is over
+func Play(session *game.GameSession, s Strategy) error {
+	for !session.IsGameOver() {
+		move := s.Move(session.View())
+		if err := session.Apply(move); err != nil {
+			return fmt.Errorf("%s played %s: %w", s.Name(), move, err)
+		}
+	}
+	return nil
+}
+
+// LegalMoves returns every move allowed in the current state: playing each
+// room card, fighting each monster barehanded while a weapon is equipped,
+// and skipping a full room unless the previous room was skipped
+func LegalMoves(view game.View) []game.Action {
+	moves := make([]game.Action, 0, 9)
+	for i, card := range view.Room.Cards {
+		moves = append(moves, game.Action{Type: game.ActionPlay, Index: i})
+		if game.CardType(card.Type) == game.Monster && view.Player.EquippedWeapon != nil {
+			moves = append(moves, game.Action{Type: game.ActionPlayBarehanded, Index: i})
+		}
+	}
+	if CanSkip(view) {
+		moves = append(moves, game.Action{Type: game.ActionSkip})
+	}
+	return moves
+}
+
+// CanSkip reports whether the current room may be skipped: no card of it has
+// been played and the previous room was not skipped
+func CanSkip(view game.View) bool {
+	return !view.Deck.PreviousRoomSkipped && len(view.Room.Cards) == 4
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestLegalMoves(t *testing.T) {
+	session := game.NewGameSessionWithOptions(game.SessionOptions{Tutorial: "skipping"})
+
+	// A full room of monsters without a weapon: four plays and a skip
+	moves := LegalMoves(session.View())
+	if len(moves) != 5 || moves[4].Type != game.ActionSkip {
+		t.Errorf("Expected 4 plays and a skip, got %v", moves)
+	}
+
+	// After a skip, the next room cannot be skipped
+	session.Apply(game.Action{Type: game.ActionSkip})
+	session.Apply(game.Action{Type: game.ActionPlay, Index: 0})
+
+	// With the 9♦ equipped, monsters can also be fought barehanded
+	moves = LegalMoves(session.View())
+	expected := []game.Action{
+		{Type: game.ActionPlay, Index: 0},
+		{Type: game.ActionPlayBarehanded, Index: 0},
+		{Type: game.ActionPlay, Index: 1},
+		{Type: game.ActionPlayBarehanded, Index: 1},
+		{Type: game.ActionPlay, Index: 2},
+	}
+	if len(moves) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, moves)
+	}
+	for i := range expected {
+		if moves[i] != expected[i] {
+			t.Errorf("Expected move %d to be %s, got %s", i, expected[i], moves[i])
+		}
+	}
+}
+
+func TestStrategiesPlayLegalGames(t *testing.T) {
+	// Expectimax searches thousands of rooms a move, so it plays fewer seeds
+	games := map[string]int64{"expectimax": 5}
+	scores := make(map[string][]int)
+	for _, name := range Names() {
+		seeds := int64(50)
+		if n, ok := games[name]; ok {
+			seeds = n
+		}
+		for seed := int64(1); seed <= seeds; seed++ {
+			s, err := New(name, seed)
+			if err != nil {
+				t.Fatalf("Error creating %s: %v", name, err)
+			}
+			session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed})
+			if err := Play(session, s); err != nil {
+				t.Fatalf("Error playing seed %d: %v", seed, err)
+			}
+			scores[name] = append(scores[name], session.Score())
+		}
+	}
+
+	if total(scores["heuristic"]) <= total(scores["greedy"]) {
+		t.Errorf("Expected the tuned heuristic to outscore greedy, got %d vs %d", total(scores["heuristic"]), total(scores["greedy"]))
+	}
+	if total(scores["greedy"]) <= total(scores["random"]) {
+		t.Errorf("Expected greedy to outscore random, got %d vs %d", total(scores["greedy"]), total(scores["random"]))
+	}
+	searched := len(scores["expectimax"])
+	if total(scores["expectimax"]) <= total(scores["greedy"][:searched]) {
+		t.Errorf("Expected expectimax to outscore greedy, got %d vs %d", total(scores["expectimax"]), total(scores["greedy"][:searched]))
+	}
+
+	if _, err := New("oracle", 1); err == nil {
+		t.Errorf("Expected an error for an unknown strategy")
+	}
+}
+
+// total adds up scores
+func total(scores []int) int {
+	sum := 0
+	for _, score := range scores {
+		sum += score
+	}
+	return sum
+}
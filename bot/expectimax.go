@@ -0,0 +1,477 @@
+package bot
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Expectimax searches the game tree to a fixed number of room draws. The
+// player's choices are max nodes, and each new room is a chance node over
+// every set of cards the dungeon could deal, weighted by its probability
+// given the cards the player has not yet seen. Cards of a skipped room are
+// dealt last, in the known order. States past the last draw are valued like
+// Greedy values the end of a room; finished games are valued by their score.
+// Values of searched states are kept in a transposition table.
+//
+// Expectimax remembers the cards played in the game it is playing, so it
+// must see every move of the game; it starts over when a new game begins.
+// It is not safe for concurrent use.
+type Expectimax struct {
+	depth    int
+	memory   *Memory
+	last     *game.View
+	lastMove game.Action
+	search   *search
+}
+
+// DefaultDepth is the number of room draws the built-in expectimax player
+// looks ahead
+const DefaultDepth = 1
+
+// NewExpectimax creates a player looking depth room draws ahead, at least
+// one. Each extra draw multiplies the work by thousands.
+func NewExpectimax(depth int) *Expectimax {
+	return &Expectimax{depth: max(1, depth), memory: NewMemory(), search: newSearch()}
+}
+
+// Name returns "expectimax"
+func (e *Expectimax) Name() string {
+	return "expectimax"
+}
+
+// Move returns the legal move with the best expected value
+func (e *Expectimax) Move(view game.View) game.Action {
+	if e.last != nil {
+		e.memory.Record(*e.last, e.lastMove)
+	}
+	if !e.memory.Matches(view) {
+		e.memory = NewMemory()
+	}
+
+	move := e.Evaluate(view, e.memory)[0].Move
+	e.last, e.lastMove = &view, move
+	return move
+}
+
+// MoveValue is a move with the value a search expects it to lead to
+type MoveValue struct {
+	Move  game.Action `json:"move"`
+	Value float64     `json:"value"`
+}
+
+// Evaluate values every legal move in a state, given what the player
+// remembers of the game, best first. Moves of equal value keep the order of
+// LegalMoves. Values are expected scores for games the search sees to the
+// end, and otherwise health plus usable weapon strength, less a share of
+// the monsters left in the room.
+func (e *Expectimax) Evaluate(view game.View, memory *Memory) []MoveValue {
+	s := e.search
+	if s.maxHealth != view.Player.MaxHealth {
+		s.reset(view.Player.MaxHealth)
+	}
+	root := rootNode(view, memory, e.depth)
+
+	values := make([]MoveValue, 0, 9)
+	for _, move := range LegalMoves(view) {
+		var value float64
+		if move.Type == game.ActionSkip {
+			value = s.skip(root)
+		} else {
+			value = s.play(root, move.Index, move.Type == game.ActionPlayBarehanded)
+		}
+		values = append(values, MoveValue{Move: move, Value: value})
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Value > values[j].Value })
+	return values
+}
+
+// Card classes: cards of the same type and value play alike, so the search
+// counts the unseen cards by class. Monsters 2 to Ace come first, then
+// weapons and potions 2 to 10.
+const (
+	weaponClasses = 13
+	potionClasses = 22
+	numClasses    = 31
+	noCard        = -1
+)
+
+// classOf returns the class of a card
+func classOf(card game.CardView) int8 {
+	switch game.CardType(card.Type) {
+	case game.Weapon:
+		return int8(weaponClasses + card.Value - 2)
+	case game.Potion:
+		return int8(potionClasses + card.Value - 2)
+	}
+	return int8(card.Value - 2)
+}
+
+// classCard returns the type and value of a class
+func classCard(class int8) (game.CardType, int) {
+	return classKinds[class], int(classValues[class])
+}
+
+// classKinds and classValues hold the type and value of each class
+var classKinds, classValues = func() (kinds [numClasses]game.CardType, values [numClasses]int8) {
+	for class := range kinds {
+		switch {
+		case class >= potionClasses:
+			kinds[class], values[class] = game.Potion, int8(class-potionClasses+2)
+		case class >= weaponClasses:
+			kinds[class], values[class] = game.Weapon, int8(class-weaponClasses+2)
+		default:
+			kinds[class], values[class] = game.Monster, int8(class+2)
+		}
+	}
+	return kinds, values
+}()
+
+// node is a state of the search. It holds only values, so it can key the
+// transposition table.
+type node struct {
+	health, weapon, limit int8
+	// bonus is the value of the last card played if it was a potion, which
+	// a won game at full health adds to its score
+	bonus      int8
+	potionUsed bool
+	skipped    bool
+	// draws is the number of room draws left to search
+	draws int8
+	// room holds the classes of the room cards, then noCard
+	room  [4]int8
+	rooms int8
+	// deck is the number of cards left in the dungeon
+	deck int8
+	// pool counts the unseen cards by class, and poolSize in all
+	pool     [numClasses]int8
+	poolSize int8
+	// bottom holds the classes of the skipped cards still in the dungeon,
+	// in the order they will be drawn
+	bottom  [4]int8
+	bottoms int8
+	// monsters is the value of the monsters not yet defeated, in the room or
+	// the dungeon, which a lost game scores against the player
+	monsters int16
+}
+
+// rootNode builds the node of a visible state
+func rootNode(view game.View, memory *Memory, draws int) node {
+	n := node{
+		health:     int8(view.Player.Health),
+		limit:      int8(view.Player.WeaponLimit()),
+		potionUsed: view.Player.UsedPotion,
+		skipped:    view.Deck.PreviousRoomSkipped || memory.skipped,
+		draws:      int8(draws),
+		deck:       int8(view.Deck.RemainingCards),
+		room:       [4]int8{noCard, noCard, noCard, noCard},
+		bottom:     [4]int8{noCard, noCard, noCard, noCard},
+	}
+	if view.Player.EquippedWeapon != nil {
+		n.weapon = int8(view.Player.EquippedWeapon.Value)
+	}
+	if memory.last != nil && game.CardType(memory.last.Type) == game.Potion {
+		n.bonus = int8(memory.last.Value)
+	}
+	for i, card := range view.Room.Cards {
+		n.room[i] = classOf(card.CardView)
+		n.monsters += monsterValue(card.CardView)
+	}
+	n.rooms = int8(len(view.Room.Cards))
+	for _, card := range memory.Unseen(view) {
+		n.pool[classOf(card)]++
+		n.poolSize++
+		n.monsters += monsterValue(card)
+	}
+	for i, card := range memory.Bottom(view) {
+		if i < len(n.bottom) {
+			n.bottom[i] = classOf(card)
+			n.bottoms++
+			n.monsters += monsterValue(card)
+		}
+	}
+	return n
+}
+
+// monsterValue returns the value of a monster, and 0 for other cards
+func monsterValue(card game.CardView) int16 {
+	if game.CardType(card.Type) != game.Monster {
+		return 0
+	}
+	return int16(card.Value)
+}
+
+// search holds the transposition table shared by the searches of a player
+type search struct {
+	maxHealth int
+	table     map[node]float64
+}
+
+// maxTableSize bounds the transposition table; it is cleared when full
+const maxTableSize = 1 << 20
+
+// newSearch returns a search with an empty table
+func newSearch() *search {
+	return &search{table: make(map[node]float64)}
+}
+
+// reset clears the table, whose values depend on the maximum health
+func (s *search) reset(maxHealth int) {
+	s.maxHealth = maxHealth
+	s.table = make(map[node]float64)
+}
+
+// decide returns the value of the best move in a room with cards to play
+func (s *search) decide(n node) float64 {
+	// Rooms after the last draw rarely repeat, so they are searched without
+	// the table
+	if n.draws == 0 {
+		return s.lastRoom(n.player(), n.room, int(n.rooms), n.deck < 3)
+	}
+	if value, ok := s.table[n]; ok {
+		return value
+	}
+
+	best := math.Inf(-1)
+	if n.rooms == 4 && !n.skipped {
+		best = s.skip(n)
+	}
+	for i := int8(0); i < n.rooms; i++ {
+		// Cards of the same class play alike
+		if i > 0 && n.room[i] == n.room[i-1] {
+			continue
+		}
+		best = max(best, s.play(n, int(i), false))
+		if kind, _ := classCard(n.room[i]); kind == game.Monster && n.weapon > 0 {
+			best = max(best, s.play(n, int(i), true))
+		}
+	}
+
+	s.store(n, best)
+	return best
+}
+
+// store keeps the value of a node in the table
+func (s *search) store(n node, value float64) {
+	if len(s.table) >= maxTableSize {
+		s.table = make(map[node]float64)
+	}
+	s.table[n] = value
+}
+
+// player is the part of a node a play changes
+type player struct {
+	health, weapon, limit, bonus int8
+	potionUsed                   bool
+	// monsters is the value of the monsters not yet defeated
+	monsters int16
+}
+
+// player returns the player of a node
+func (n node) player() player {
+	return player{n.health, n.weapon, n.limit, n.bonus, n.potionUsed, n.monsters}
+}
+
+// apply plays a card of a class, following the game rules
+func (p player) apply(class int8, barehanded bool, maxHealth int) player {
+	kind, value := classCard(class)
+	p.bonus = 0
+	switch kind {
+	case game.Monster:
+		p.monsters -= int16(value)
+		if !barehanded && p.weapon > 0 && value <= int(p.limit) {
+			p.health -= int8(max(0, value-int(p.weapon)))
+			p.limit = int8(value)
+		} else {
+			p.health -= int8(value)
+		}
+	case game.Weapon:
+		p.weapon = int8(value)
+		p.limit = int8(game.Ace)
+	case game.Potion:
+		if !p.potionUsed {
+			p.health = int8(min(maxHealth, int(p.health)+value))
+			p.potionUsed = true
+		}
+		p.bonus = int8(value)
+	}
+	return p
+}
+
+// lastRoom returns the value of the best plays in a room after the last
+// draw: the score if the game ends, and the horizon value otherwise
+func (s *search) lastRoom(p player, room [4]int8, rooms int, deckDone bool) float64 {
+	best := math.Inf(-1)
+	for i := 0; i < rooms; i++ {
+		if i > 0 && room[i] == room[i-1] {
+			continue
+		}
+		rest := room
+		copy(rest[i:], rest[i+1:])
+		kind, _ := classCard(room[i])
+		for _, barehanded := range [2]bool{false, true} {
+			if barehanded && (kind != game.Monster || p.weapon == 0) {
+				break
+			}
+			next := p.apply(room[i], barehanded, s.maxHealth)
+			var value float64
+			switch {
+			case next.health <= 0:
+				value = float64(-next.monsters)
+			case rooms > 2:
+				value = s.lastRoom(next, rest, rooms-1, deckDone)
+			case deckDone:
+				value = s.won(next)
+			default:
+				value = horizon(next, rest[0])
+			}
+			best = max(best, value)
+		}
+	}
+	return best
+}
+
+// play returns the value of playing a room card
+func (s *search) play(n node, index int, barehanded bool) float64 {
+	p := n.player().apply(n.room[index], barehanded, s.maxHealth)
+	n.health, n.weapon, n.limit, n.bonus, n.potionUsed, n.monsters = p.health, p.weapon, p.limit, p.bonus, p.potionUsed, p.monsters
+	copy(n.room[index:], n.room[index+1:])
+	n.room[len(n.room)-1] = noCard
+	n.rooms--
+	sortRoom(&n)
+
+	if n.health <= 0 {
+		return float64(-n.monsters)
+	}
+	if n.rooms > 1 {
+		return s.decide(n)
+	}
+	return s.refill(n)
+}
+
+// refill returns the value of a finished room: the next room is dealt from
+// the leftover card and three from the dungeon, or the game is won when the
+// dungeon runs out
+func (s *search) refill(n node) float64 {
+	if n.deck < 3 {
+		return s.won(n.player())
+	}
+	if n.draws == 0 {
+		return horizon(n.player(), n.room[0])
+	}
+	// The new room plays alike whatever the last card and potion were
+	n.bonus, n.potionUsed = 0, false
+	if value, ok := s.table[n]; ok {
+		return value
+	}
+
+	next := n
+	next.draws--
+	value := s.deal(next, 3)
+	s.store(n, value)
+	return value
+}
+
+// skip returns the value of skipping a full room: its cards go to the
+// bottom of the dungeon and a new room of four is dealt
+func (s *search) skip(n node) float64 {
+	for i := int8(0); i < n.rooms && n.bottoms < int8(len(n.bottom)); i++ {
+		n.bottom[n.bottoms] = n.room[i]
+		n.bottoms++
+	}
+	n.deck += n.rooms
+	n.room = [4]int8{noCard, noCard, noCard, noCard}
+	n.rooms = 0
+	n.skipped = true
+	n.potionUsed = false
+	n.draws--
+	return s.deal(n, 4)
+}
+
+// deal is the chance node of dealing count cards to the room: the expected
+// value of the room over every set of cards the unseen ones could give,
+// then the skipped cards in order once the unseen run out
+func (s *search) deal(n node, count int) float64 {
+	fromPool := min(count, int(n.deck-n.bottoms), int(n.poolSize))
+	n.deck -= int8(count)
+
+	// Skipped cards complete the room in order
+	for i := fromPool; i < count && n.bottoms > 0; i++ {
+		n.room[n.rooms] = n.bottom[0]
+		n.rooms++
+		copy(n.bottom[:], n.bottom[1:])
+		n.bottom[len(n.bottom)-1] = noCard
+		n.bottoms--
+	}
+	if fromPool == 0 {
+		sortRoom(&n)
+		return s.decide(n)
+	}
+
+	total := 0.0
+	ways := binomial(int(n.poolSize), fromPool)
+	var visit func(n node, class int8, left int, weight float64)
+	visit = func(n node, class int8, left int, weight float64) {
+		if left == 0 {
+			sortRoom(&n)
+			total += weight * s.decide(n)
+			return
+		}
+		for ; class < numClasses; class++ {
+			count := int(n.pool[class])
+			if count == 0 {
+				continue
+			}
+			// Deal k cards of this class, then move on to the next ones
+			next := n
+			for k := 1; k <= min(count, left); k++ {
+				next.pool[class]--
+				next.poolSize--
+				next.room[next.rooms] = class
+				next.rooms++
+				visit(next, class+1, left-k, weight*binomial(count, k))
+			}
+		}
+	}
+	visit(n, 0, fromPool, 1)
+	return total / ways
+}
+
+// won returns the score of a won game
+func (s *search) won(p player) float64 {
+	score := int(p.health)
+	if score == s.maxHealth {
+		score += int(p.bonus)
+	}
+	return float64(score)
+}
+
+// horizon values a state the search goes no further from, at the end of a
+// room, as Greedy values the end of a room plan
+func horizon(p player, leftover int8) float64 {
+	value := float64(p.health) + weaponWorth*float64(min(p.weapon, p.limit))
+	if kind, v := classCard(leftover); kind == game.Monster {
+		value -= leftoverMonsterCost * float64(v)
+	}
+	return value
+}
+
+// sortRoom orders the room by class, so rooms dealt in any order share
+// their table entries
+func sortRoom(n *node) {
+	for i := int8(1); i < n.rooms; i++ {
+		for j := i; j > 0 && n.room[j] < n.room[j-1]; j-- {
+			n.room[j], n.room[j-1] = n.room[j-1], n.room[j]
+		}
+	}
+}
+
+// binomial returns n choose k
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 0; i < k; i++ {
+		result = result * float64(n-i) / float64(i+1)
+	}
+	return result
+}
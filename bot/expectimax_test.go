@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestMemory(t *testing.T) {
+	session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: 4})
+	memory := NewMemory()
+
+	// Skipped cards wait at the bottom of the dungeon in room order
+	skipped := session.View()
+	session.Apply(game.Action{Type: game.ActionSkip})
+	memory.Record(skipped, game.Action{Type: game.ActionSkip})
+	bottom := memory.Bottom(session.View())
+	if len(bottom) != 4 {
+		t.Fatalf("Expected 4 cards at the bottom, got %d", len(bottom))
+	}
+	for i, card := range bottom {
+		if card != skipped.Room.Cards[i].CardView {
+			t.Errorf("Expected bottom card %d to be %s, got %s", i, skipped.Room.Cards[i].Display, card.Display)
+		}
+	}
+
+	greedy := NewGreedy()
+	for !session.IsGameOver() {
+		view := session.View()
+		if !memory.Matches(view) {
+			t.Fatalf("Expected memory to match after %d cards", memory.Played())
+		}
+		if unseen := len(memory.Unseen(view)) + len(memory.Bottom(view)); unseen != view.Deck.RemainingCards {
+			t.Fatalf("Expected %d cards in the dungeon, memory has %d", view.Deck.RemainingCards, unseen)
+		}
+		move := greedy.Move(view)
+		session.Apply(move)
+		memory.Record(view, move)
+	}
+
+	rebuilt, err := MemoryOf(session.Replay())
+	if err != nil {
+		t.Fatalf("Error rebuilding memory: %v", err)
+	}
+	if rebuilt.Played() != memory.Played() || !rebuilt.Matches(session.View()) {
+		t.Errorf("Expected the rebuilt memory to match, got %d cards played", rebuilt.Played())
+	}
+}
+
+func TestExpectimaxEndgameIsExact(t *testing.T) {
+	checked := 0
+	for seed := int64(1); seed <= 50; seed++ {
+		// Play greedily until the dungeon is about to run out
+		session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed})
+		greedy := NewGreedy()
+		for !session.IsGameOver() && session.View().Deck.RemainingCards >= 3 {
+			session.Apply(greedy.Move(session.View()))
+		}
+		if session.IsGameOver() {
+			continue
+		}
+		checked++
+
+		// Nothing is left to chance, so the best value is the final score
+		e := NewExpectimax(DefaultDepth)
+		var expected float64
+		for step := 0; !session.IsGameOver(); step++ {
+			memory, err := MemoryOf(session.Replay())
+			if err != nil {
+				t.Fatalf("Error rebuilding memory: %v", err)
+			}
+			values := e.Evaluate(session.View(), memory)
+			if step == 0 {
+				expected = values[0].Value
+			}
+			if err := session.Apply(values[0].Move); err != nil {
+				t.Fatalf("Seed %d: error playing %s: %v", seed, values[0].Move, err)
+			}
+		}
+		if float64(session.Score()) != expected {
+			t.Errorf("Seed %d: expected score %.0f, got %d", seed, expected, session.Score())
+		}
+	}
+	if checked == 0 {
+		t.Fatalf("Expected some games to reach the end of the dungeon")
+	}
+}
+
+func TestExpectimaxEvaluate(t *testing.T) {
+	session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: 7})
+	view := session.View()
+	values := NewExpectimax(DefaultDepth).Evaluate(view, NewMemory())
+
+	legal := LegalMoves(view)
+	if len(values) != len(legal) {
+		t.Fatalf("Expected a value for each of %d moves, got %d", len(legal), len(values))
+	}
+	for i := 1; i < len(values); i++ {
+		if values[i].Value > values[i-1].Value {
+			t.Errorf("Expected moves best first, got %v", values)
+		}
+	}
+}
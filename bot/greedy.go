@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"math"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Greedy plans the rest of the current room. It tries every order of the
+// cards left to play, with and without the weapon, and picks the plan that
+// leaves it with the most health and the best weapon, without looking at the
+// rooms to come. It skips a room when every plan would leave it nearly dead.
+type Greedy struct{}
+
+// NewGreedy creates a greedy player
+func NewGreedy() *Greedy {
+	return &Greedy{}
+}
+
+// Name returns "greedy"
+func (g *Greedy) Name() string {
+	return "greedy"
+}
+
+const (
+	// weaponWorth is the value of each point of usable weapon strength, in health
+	weaponWorth = 1.0
+	// leftoverMonsterCost is the cost of each point of a monster carried into the next room
+	leftoverMonsterCost = 0.75
+	// skipThreshold is the health below which a room is skipped if possible
+	skipThreshold = 3
+)
+
+// roomState is the part of the visible state a room plan changes
+type roomState struct {
+	health     int
+	maxHealth  int
+	weapon     int
+	limit      int
+	potionUsed bool
+	// wasted is the healing lost to full health or to a second potion
+	wasted int
+}
+
+// Move returns the first move of the best plan for the room
+func (g *Greedy) Move(view game.View) game.Action {
+	state := newRoomState(view)
+	cards := view.Room.Cards
+	plays := max(1, len(cards)-1)
+	if CanSkip(view) && bestHealth(state, cards, plays) < skipThreshold {
+		return game.Action{Type: game.ActionSkip}
+	}
+	move, _ := planRoom(state, cards, plays, evaluate)
+	return move
+}
+
+// newRoomState returns the room state of a visible state
+func newRoomState(view game.View) roomState {
+	state := roomState{
+		health:     view.Player.Health,
+		maxHealth:  view.Player.MaxHealth,
+		limit:      view.Player.WeaponLimit(),
+		potionUsed: view.Player.UsedPotion,
+	}
+	if view.Player.EquippedWeapon != nil {
+		state.weapon = view.Player.EquippedWeapon.Value
+	}
+	return state
+}
+
+// evaluator scores the state at the end of a room plan, given the cards left
+type evaluator func(state roomState, leftover []game.RoomCardView) float64
+
+// planRoom searches every sequence of the given number of plays and returns
+// the first move of the best one and its value
+func planRoom(state roomState, cards []game.RoomCardView, plays int, eval evaluator) (game.Action, float64) {
+	best := game.Action{Type: game.ActionPlay}
+	bestValue := math.Inf(-1)
+	for _, move := range roomMoves(state, cards) {
+		next, rest := applyRoomMove(state, cards, move)
+		var value float64
+		if plays <= 1 || next.health <= 0 {
+			value = eval(next, rest)
+		} else {
+			_, value = planRoom(next, rest, plays-1, eval)
+		}
+		if value > bestValue {
+			best, bestValue = move, value
+		}
+	}
+	return best, bestValue
+}
+
+// bestHealth returns the most health any plan for the room leaves
+func bestHealth(state roomState, cards []game.RoomCardView, plays int) int {
+	best := math.MinInt
+	for _, move := range roomMoves(state, cards) {
+		next, rest := applyRoomMove(state, cards, move)
+		health := next.health
+		if plays > 1 && next.health > 0 {
+			health = bestHealth(next, rest, plays-1)
+		}
+		best = max(best, health)
+	}
+	return best
+}
+
+// roomMoves lists the plays available in a planned room
+func roomMoves(state roomState, cards []game.RoomCardView) []game.Action {
+	moves := make([]game.Action, 0, 2*len(cards))
+	for i, card := range cards {
+		moves = append(moves, game.Action{Type: game.ActionPlay, Index: i})
+		if game.CardType(card.Type) == game.Monster && state.weapon > 0 {
+			moves = append(moves, game.Action{Type: game.ActionPlayBarehanded, Index: i})
+		}
+	}
+	return moves
+}
+
+// applyRoomMove plays a card on the planned state, following the game rules
+func applyRoomMove(state roomState, cards []game.RoomCardView, move game.Action) (roomState, []game.RoomCardView) {
+	card := cards[move.Index]
+	rest := make([]game.RoomCardView, 0, len(cards)-1)
+	rest = append(rest, cards[:move.Index]...)
+	rest = append(rest, cards[move.Index+1:]...)
+
+	switch game.CardType(card.Type) {
+	case game.Monster:
+		if move.Type == game.ActionPlay && state.weapon > 0 && card.Value <= state.limit {
+			state.health -= max(0, card.Value-state.weapon)
+			state.limit = card.Value
+		} else {
+			state.health -= card.Value
+		}
+	case game.Weapon:
+		state.weapon = card.Value
+		state.limit = int(game.Ace)
+	case game.Potion:
+		if !state.potionUsed {
+			healed := min(state.maxHealth, state.health+card.Value)
+			state.wasted += card.Value - (healed - state.health)
+			state.health = healed
+			state.potionUsed = true
+		} else {
+			state.wasted += card.Value
+		}
+	}
+	return state, rest
+}
+
+// evaluate scores the state at the end of a room plan
+func evaluate(state roomState, leftover []game.RoomCardView) float64 {
+	if state.health <= 0 {
+		return -1000 + float64(state.health)
+	}
+
+	value := float64(state.health) + weaponWorth*float64(min(state.weapon, state.limit))
+	for _, card := range leftover {
+		if game.CardType(card.Type) == game.Monster {
+			value -= leftoverMonsterCost * float64(card.Value)
+		}
+	}
+	return value
+}
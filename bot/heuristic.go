@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/internal/jsonfile"
+)
+
+// Weights drive the decisions of a Heuristic player. Every weight is in
+// health: the end of a room plan is worth its health, plus or minus the
+// weighted features below.
+type Weights struct {
+	// WeaponValue is the worth of each point of weapon strength still usable
+	WeaponValue float64 `json:"weapon_value"`
+	// Degradation is the cost of each point the weapon's limit has fallen
+	// below an Ace, for the monsters it may no longer fight
+	Degradation float64 `json:"degradation"`
+	// PotionWaste is the cost of each point of healing lost to full health
+	// or to a second potion in a room
+	PotionWaste float64 `json:"potion_waste"`
+	// LeftoverMonster is the cost of each point of a monster left for the
+	// next room
+	LeftoverMonster float64 `json:"leftover_monster"`
+	// LeftoverWeapon is the worth of each point of a weapon left for the
+	// next room
+	LeftoverWeapon float64 `json:"leftover_weapon"`
+	// LeftoverPotion is the worth of each point of a potion left for the
+	// next room
+	LeftoverPotion float64 `json:"leftover_potion"`
+	// SkipThreshold is the health below which a room is skipped, when
+	// every plan for it would leave less
+	SkipThreshold float64 `json:"skip_threshold"`
+}
+
+// GreedyWeights are the hand-tuned weights of Greedy; a Heuristic player
+// with them plays exactly as Greedy does
+var GreedyWeights = Weights{
+	WeaponValue:     weaponWorth,
+	LeftoverMonster: leftoverMonsterCost,
+	SkipThreshold:   skipThreshold,
+}
+
+// DefaultWeights are the weights of the built-in heuristic player, tuned
+// from GreedyWeights with cmd/tune's defaults (seeds 1-2000, 24 candidates
+// for 30 generations). On seeds 100001-102000 they average -49.8, where
+// GreedyWeights average -69.9.
+var DefaultWeights = Weights{
+	WeaponValue:     0.55,
+	Degradation:     0.38,
+	PotionWaste:     0.73,
+	LeftoverMonster: 0.64,
+	LeftoverWeapon:  0.99,
+	LeftoverPotion:  0.25,
+	SkipThreshold:   0.9,
+}
+
+// LoadWeights reads weights saved as JSON. Weights missing from the file
+// keep their default value.
+func LoadWeights(path string) (Weights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Weights{}, err
+	}
+
+	w := DefaultWeights
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&w); err != nil {
+		return Weights{}, fmt.Errorf("reading weights from %s: %w", path, err)
+	}
+	return w, nil
+}
+
+// Save writes the weights as JSON
+func (w Weights) Save(path string) error {
+	return jsonfile.Save(path, w)
+}
+
+// Heuristic plans the rest of the current room as Greedy does, valuing
+// each plan with a vector of weights. Tune the weights with cmd/tune.
+type Heuristic struct {
+	weights Weights
+}
+
+// NewHeuristic creates a heuristic player with the given weights
+func NewHeuristic(w Weights) *Heuristic {
+	return &Heuristic{weights: w}
+}
+
+// Name returns "heuristic"
+func (h *Heuristic) Name() string {
+	return "heuristic"
+}
+
+// Weights returns the weights of the player
+func (h *Heuristic) Weights() Weights {
+	return h.weights
+}
+
+// Move returns the first move of the best plan for the room
+func (h *Heuristic) Move(view game.View) game.Action {
+	state := newRoomState(view)
+	cards := view.Room.Cards
+	plays := max(1, len(cards)-1)
+	if CanSkip(view) && float64(bestHealth(state, cards, plays)) < h.weights.SkipThreshold {
+		return game.Action{Type: game.ActionSkip}
+	}
+	move, _ := planRoom(state, cards, plays, h.evaluate)
+	return move
+}
+
+// evaluate scores the state at the end of a room plan with the weights
+func (h *Heuristic) evaluate(state roomState, leftover []game.RoomCardView) float64 {
+	if state.health <= 0 {
+		return -1000 + float64(state.health)
+	}
+
+	w := h.weights
+	value := float64(state.health) - w.PotionWaste*float64(state.wasted)
+	if state.weapon > 0 {
+		value += w.WeaponValue*float64(min(state.weapon, state.limit)) -
+			w.Degradation*float64(int(game.Ace)-state.limit)
+	}
+	for _, card := range leftover {
+		switch game.CardType(card.Type) {
+		case game.Monster:
+			value -= w.LeftoverMonster * float64(card.Value)
+		case game.Weapon:
+			value += w.LeftoverWeapon * float64(card.Value)
+		case game.Potion:
+			value += w.LeftoverPotion * float64(card.Value)
+		}
+	}
+	return value
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestHeuristicWithGreedyWeightsPlaysLikeGreedy(t *testing.T) {
+	heuristic, greedy := NewHeuristic(GreedyWeights), NewGreedy()
+	for seed := int64(1); seed <= 30; seed++ {
+		session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed})
+		for !session.IsGameOver() {
+			view := session.View()
+			move := heuristic.Move(view)
+			if expected := greedy.Move(view); move != expected {
+				t.Fatalf("Seed %d: expected %s, got %s", seed, expected, move)
+			}
+			session.Apply(move)
+		}
+	}
+}
+
+func TestWeightsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.json")
+	saved := Weights{WeaponValue: 1.5, Degradation: 0.25, SkipThreshold: 6}
+	if err := saved.Save(path); err != nil {
+		t.Fatalf("Error saving weights: %v", err)
+	}
+	loaded, err := LoadWeights(path)
+	if err != nil {
+		t.Fatalf("Error loading weights: %v", err)
+	}
+	if loaded != saved {
+		t.Errorf("Expected %+v, got %+v", saved, loaded)
+	}
+
+	// Weights missing from the file keep their default
+	os.WriteFile(path, []byte(`{"skip_threshold": 5}`), 0o644)
+	loaded, _ = LoadWeights(path)
+	expected := DefaultWeights
+	expected.SkipThreshold = 5
+	if loaded != expected {
+		t.Errorf("Expected %+v, got %+v", expected, loaded)
+	}
+
+	os.WriteFile(path, []byte(`{"skip_treshold": 5}`), 0o644)
+	if _, err := LoadWeights(path); err == nil {
+		t.Errorf("Expected an error for an unknown weight")
+	}
+	if _, err := LoadWeights(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
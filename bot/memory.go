@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// dungeon is every card of a full dungeon
+var dungeon = func() []game.CardView {
+	var cards []game.CardView
+	for suit := game.Clubs; suit <= game.Spades; suit++ {
+		for rank := game.Two; rank <= game.Ace; rank++ {
+			card := game.NewCard(suit, rank)
+			if !card.IsRedFaceOrAce() {
+				cards = append(cards, game.NewCardView(card))
+			}
+		}
+	}
+	return cards
+}()
+
+// cardID identifies a card; every card of the dungeon is different
+type cardID struct {
+	suit, rank int
+}
+
+// idOf returns the identity of a card
+func idOf(card game.CardView) cardID {
+	return cardID{card.Suit, card.Rank}
+}
+
+// Memory is what a player remembers of a game beyond its visible state: the
+// cards that have left the dungeon, the last card played, and the cards of a
+// skipped room, which wait at the bottom of the dungeon in a known order.
+// Everything it holds was seen by the player, so strategies may use it
+// without cheating.
+type Memory struct {
+	played  map[cardID]bool
+	last    *game.CardView
+	bottom  []game.CardView
+	skipped bool
+}
+
+// NewMemory returns the memory of a game that has not started
+func NewMemory() *Memory {
+	return &Memory{played: make(map[cardID]bool)}
+}
+
+// MemoryOf rebuilds what the player of a game remembers after the moves of
+// a replay
+func MemoryOf(rep game.Replay) (*Memory, error) {
+	session, err := rep.StateAt(0)
+	if err != nil {
+		return nil, err
+	}
+	m := NewMemory()
+	for _, action := range rep.Actions {
+		view := session.View()
+		if err := session.Apply(action); err != nil {
+			return nil, err
+		}
+		m.Record(view, action)
+	}
+	return m, nil
+}
+
+// Record remembers a move made in a state
+func (m *Memory) Record(view game.View, move game.Action) {
+	if move.Type == game.ActionSkip {
+		// Skipped cards go to the bottom of the dungeon in room order
+		for _, card := range view.Room.Cards {
+			m.bottom = append(m.bottom, card.CardView)
+		}
+		m.skipped = true
+		return
+	}
+	if move.Index < 0 || move.Index >= len(view.Room.Cards) {
+		return
+	}
+	card := view.Room.Cards[move.Index].CardView
+	m.played[idOf(card)] = true
+	m.last = &card
+}
+
+// Played returns the number of cards remembered leaving the dungeon
+func (m *Memory) Played() int {
+	return len(m.played)
+}
+
+// Matches reports whether the memory could belong to the game in view: the
+// cards it remembers played are all the cards missing from the dungeon and
+// the room. A memory that does not match started in another game or joined
+// this one after its start.
+func (m *Memory) Matches(view game.View) bool {
+	return m.Played() == len(dungeon)-view.Deck.RemainingCards-len(view.Room.Cards)
+}
+
+// Bottom returns the skipped cards still in the dungeon, in the order they
+// will be drawn, after every card of Unseen
+func (m *Memory) Bottom(view game.View) []game.CardView {
+	inRoom := roomIDs(view)
+	bottom := make([]game.CardView, 0, len(m.bottom))
+	for _, card := range m.bottom {
+		if id := idOf(card); !m.played[id] && !inRoom[id] {
+			bottom = append(bottom, card)
+		}
+	}
+	return bottom
+}
+
+// Unseen returns the cards that may be anywhere in the dungeon above the
+// skipped ones: the cards of a full dungeon the player has not seen leave
+// it, and that are neither in the room nor known to be at the bottom
+func (m *Memory) Unseen(view game.View) []game.CardView {
+	known := roomIDs(view)
+	for _, card := range m.bottom {
+		known[idOf(card)] = true
+	}
+	unseen := make([]game.CardView, 0, len(dungeon))
+	for _, card := range dungeon {
+		if id := idOf(card); !m.played[id] && !known[id] {
+			unseen = append(unseen, card)
+		}
+	}
+	return unseen
+}
+
+// roomIDs returns the identities of the cards in the room
+func roomIDs(view game.View) map[cardID]bool {
+	ids := make(map[cardID]bool, len(view.Room.Cards))
+	for _, card := range view.Room.Cards {
+		ids[idOf(card.CardView)] = true
+	}
+	return ids
+}
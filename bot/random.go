@@ -0,0 +1,29 @@
+package bot
+
+import (
+	"math/rand"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Random plays a uniformly random legal move. It is the baseline other
+// strategies are measured against.
+type Random struct {
+	rng *rand.Rand
+}
+
+// NewRandom creates a random player whose choices are determined by seed
+func NewRandom(seed int64) *Random {
+	return &Random{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Name returns "random"
+func (r *Random) Name() string {
+	return "random"
+}
+
+// Move returns a random legal move
+func (r *Random) Move(view game.View) game.Action {
+	moves := LegalMoves(view)
+	return moves[r.rng.Intn(len(moves))]
+}
@@ -0,0 +1,696 @@
+// Package client is a Go client for the Scoundrel API server. It wraps every
+// route of the player and admin APIs with typed requests and responses, and
+// retries failed requests safely: moves and game creation carry an
+// Idempotency-Key, so a retried move is never applied twice.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/leaderboard"
+)
+
+const (
+	// DefaultRetries is the number of times a failed request is retried
+	DefaultRetries = 3
+	// DefaultBackoff is the wait before the first retry; it doubles on each retry
+	DefaultBackoff = 200 * time.Millisecond
+	// maxBackoff caps the wait between retries
+	maxBackoff = 10 * time.Second
+)
+
+// Client calls the Scoundrel API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	adminToken string
+
+	token string
+	mutex sync.RWMutex
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithToken sets the player's API token
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithAdminToken sets the token for the admin routes
+func WithAdminToken(token string) Option {
+	return func(c *Client) {
+		c.adminToken = token
+	}
+}
+
+// WithRetries sets how many times a failed request is retried, and the wait
+// before the first retry
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token returns the player's API token
+func (c *Client) Token() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.token
+}
+
+// SetToken sets the player's API token used by later requests
+func (c *Client) SetToken(token string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.token = token
+}
+
+// Account is a registered player and an API token
+type Account struct {
+	PlayerID string `json:"player_id"`
+	Name     string `json:"name"`
+	Token    string `json:"token"`
+}
+
+// CreateGameOptions are the options of a new game
+type CreateGameOptions struct {
+	// Public lets anyone spectate the game
+	Public bool `json:"public"`
+	// Tutorial starts the named tutorial lesson, see Lessons
+	Tutorial string `json:"tutorial,omitempty"`
+}
+
+// CreatedGame is a newly created game
+type CreatedGame struct {
+	GameID         string `json:"game_id"`
+	Public         bool   `json:"public"`
+	SpectatorToken string `json:"spectator_token"`
+	OwnerID        string `json:"owner_id"`
+	Tutorial       string `json:"tutorial,omitempty"`
+}
+
+// Lesson describes a tutorial lesson
+type Lesson struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Intro string `json:"intro"`
+	Steps int    `json:"steps"`
+}
+
+// DailyGame is the player's game for a daily challenge
+type DailyGame struct {
+	GameID    string `json:"game_id"`
+	Challenge string `json:"challenge"`
+	OwnerID   string `json:"owner_id"`
+}
+
+// LeaderboardQuery selects the rows of a leaderboard
+type LeaderboardQuery struct {
+	// Seed is required by the seed board
+	Seed int64
+	// Date selects the daily challenge board's date (YYYY-MM-DD)
+	Date string
+	// Rules restricts the board to a rule preset
+	Rules string
+	// Limit caps the number of rows; zero means the server default
+	Limit int
+}
+
+// Leaderboard is the ranked rows of a board
+type Leaderboard struct {
+	Board   string               `json:"board"`
+	Entries []leaderboard.Ranked `json:"entries"`
+}
+
+// ReplayStep is the state of a finished game after a number of moves
+type ReplayStep struct {
+	GameID string       `json:"game_id"`
+	Step   int          `json:"step"`
+	Total  int          `json:"total"`
+	Action *game.Action `json:"action"`
+	State  game.View    `json:"state"`
+}
+
+// Hint is a suggested move for a game in progress
+type Hint struct {
+	GameID   string      `json:"game_id"`
+	Strategy string      `json:"strategy"`
+	Move     game.Action `json:"move"`
+	// Moves values every legal move, best first
+	Moves []bot.MoveValue `json:"moves"`
+}
+
+// Readiness is the result of the server's readiness checks
+type Readiness struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		OK     bool   `json:"ok"`
+		Detail string `json:"detail"`
+	} `json:"checks"`
+}
+
+// SessionFilter selects sessions on the admin list
+type SessionFilter struct {
+	State   string
+	OwnerID string
+	MinAge  time.Duration
+	MaxAge  time.Duration
+	Offset  int
+	Limit   int
+}
+
+// ExportOptions selects the training data exported by AdminExport
+type ExportOptions struct {
+	// Format is "jsonl" or "csv"; empty means jsonl
+	Format string
+	// Outcome keeps only the games "won" or "lost"
+	Outcome string
+	// Rules keeps only the games of a rule preset
+	Rules string
+	// Dedup drops samples whose state was already exported
+	Dedup bool
+}
+
+// SessionPage is a page of the admin session list
+type SessionPage struct {
+	Total    int                   `json:"total"`
+	Offset   int                   `json:"offset"`
+	Limit    int                   `json:"limit"`
+	Sessions []game.SessionSummary `json:"sessions"`
+}
+
+// Register creates a player account. The client uses its token from then on.
+func (c *Client) Register(ctx context.Context, name, password string) (*Account, error) {
+	return c.authenticate(ctx, "/api/register", name, password)
+}
+
+// Login exchanges a name and password for a new token. The client uses it from then on.
+func (c *Client) Login(ctx context.Context, name, password string) (*Account, error) {
+	return c.authenticate(ctx, "/api/login", name, password)
+}
+
+// Logout revokes the client's token on the server and stops using it
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, http.MethodPost, "/api/logout", nil, false, nil); err != nil {
+		return err
+	}
+	c.SetToken("")
+	return nil
+}
+
+func (c *Client) authenticate(ctx context.Context, path, name, password string) (*Account, error) {
+	body := map[string]string{"name": name, "password": password}
+
+	var account Account
+	if err := c.do(ctx, http.MethodPost, path, body, false, &account); err != nil {
+		return nil, err
+	}
+	c.SetToken(account.Token)
+	return &account, nil
+}
+
+// CreateGame starts a new game, owned by the player if the client has a token
+func (c *Client) CreateGame(ctx context.Context, opts CreateGameOptions) (*CreatedGame, error) {
+	var created CreatedGame
+	if err := c.do(ctx, http.MethodPost, "/api/games", opts, true, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetGame returns the current state of a game. Games owned by another
+// player need GetGameAsSpectator.
+func (c *Client) GetGame(ctx context.Context, gameID string) (*game.View, error) {
+	return c.GetGameAsSpectator(ctx, gameID, "")
+}
+
+// GetGameAsSpectator returns the current state of a game, read with its
+// spectator token. An empty token is the same as GetGame.
+func (c *Client) GetGameAsSpectator(ctx context.Context, gameID, token string) (*game.View, error) {
+	path := "/api/games/" + url.PathEscape(gameID)
+	if token != "" {
+		path += "?token=" + url.QueryEscape(token)
+	}
+	return c.view(ctx, http.MethodGet, path, false)
+}
+
+// Play plays a card from the room, using the equipped weapon against a monster when allowed
+func (c *Client) Play(ctx context.Context, gameID string, index int) (*game.View, error) {
+	return c.view(ctx, http.MethodPost, fmt.Sprintf("/api/games/%s/play/%d", url.PathEscape(gameID), index), true)
+}
+
+// PlayBarehanded plays a card from the room, fighting a monster without the weapon
+func (c *Client) PlayBarehanded(ctx context.Context, gameID string, index int) (*game.View, error) {
+	return c.view(ctx, http.MethodPost, fmt.Sprintf("/api/games/%s/play-without-weapon/%d", url.PathEscape(gameID), index), true)
+}
+
+// Skip skips the current room
+func (c *Client) Skip(ctx context.Context, gameID string) (*game.View, error) {
+	return c.view(ctx, http.MethodPost, "/api/games/"+url.PathEscape(gameID)+"/skip", true)
+}
+
+// Apply performs a move
+func (c *Client) Apply(ctx context.Context, gameID string, action game.Action) (*game.View, error) {
+	switch action.Type {
+	case game.ActionPlay:
+		return c.Play(ctx, gameID, action.Index)
+	case game.ActionPlayBarehanded:
+		return c.PlayBarehanded(ctx, gameID, action.Index)
+	case game.ActionSkip:
+		return c.Skip(ctx, gameID)
+	default:
+		return nil, fmt.Errorf("unknown action type %d", int(action.Type))
+	}
+}
+
+// Daily starts the player's game for today's daily challenge. If the player
+// has already attempted it, the error matches ErrConflict and carries the
+// existing game's ID.
+func (c *Client) Daily(ctx context.Context) (*DailyGame, error) {
+	var daily DailyGame
+	if err := c.do(ctx, http.MethodPost, "/api/daily", nil, true, &daily); err != nil {
+		return nil, err
+	}
+	return &daily, nil
+}
+
+// MyGames lists the games owned by the player
+func (c *Client) MyGames(ctx context.Context) ([]game.View, error) {
+	var response struct {
+		Games []game.View `json:"games"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/me/games", nil, false, &response); err != nil {
+		return nil, err
+	}
+	return response.Games, nil
+}
+
+// LiveGames lists the public games in progress
+func (c *Client) LiveGames(ctx context.Context) ([]game.View, error) {
+	var response struct {
+		Games []game.View `json:"games"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/games/live", nil, false, &response); err != nil {
+		return nil, err
+	}
+	return response.Games, nil
+}
+
+// Lessons lists the tutorial lessons in teaching order
+func (c *Client) Lessons(ctx context.Context) ([]Lesson, error) {
+	var lessons []Lesson
+	if err := c.do(ctx, http.MethodGet, "/api/tutorial", nil, false, &lessons); err != nil {
+		return nil, err
+	}
+	return lessons, nil
+}
+
+// Spectate returns the visible state of a game. Private games need their spectator token.
+func (c *Client) Spectate(ctx context.Context, gameID, token string) (*game.View, error) {
+	return c.view(ctx, http.MethodGet, spectatePath(gameID, token, false), false)
+}
+
+// Watch calls fn with the visible state of a game after every move until the
+// game ends, ctx is cancelled or fn returns an error. Streams are not retried.
+func (c *Client) Watch(ctx context.Context, gameID, token string, fn func(game.View) error) error {
+	req, err := c.newRequest(ctx, http.MethodGet, spectatePath(gameID, token, true), nil, "")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return newError(resp, body)
+	}
+
+	// Read "data:" lines of server-sent events
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var view game.View
+		if err := json.Unmarshal([]byte(data), &view); err != nil {
+			return err
+		}
+		if err := fn(view); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func spectatePath(gameID, token string, stream bool) string {
+	path := "/api/games/" + url.PathEscape(gameID) + "/spectate"
+	if stream {
+		path += "/stream"
+	}
+	if token != "" {
+		path += "?token=" + url.QueryEscape(token)
+	}
+	return path
+}
+
+// Leaderboard returns the ranked rows of a board, such as "all-time" or "daily"
+func (c *Client) Leaderboard(ctx context.Context, board string, q LeaderboardQuery) (*Leaderboard, error) {
+	params := url.Values{}
+	if board == leaderboard.BoardSeed {
+		params.Set("seed", strconv.FormatInt(q.Seed, 10))
+	}
+	if q.Date != "" {
+		params.Set("date", q.Date)
+	}
+	if q.Rules != "" {
+		params.Set("rules", q.Rules)
+	}
+	if q.Limit > 0 {
+		params.Set("limit", strconv.Itoa(q.Limit))
+	}
+
+	path := "/api/leaderboards/" + url.PathEscape(board)
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	var lb Leaderboard
+	if err := c.do(ctx, http.MethodGet, path, nil, false, &lb); err != nil {
+		return nil, err
+	}
+	return &lb, nil
+}
+
+// Hint suggests a move for a game in progress. Ranked games get no hints.
+func (c *Client) Hint(ctx context.Context, gameID string) (*Hint, error) {
+	var hint Hint
+	if err := c.do(ctx, http.MethodGet, "/api/games/"+url.PathEscape(gameID)+"/hint", nil, false, &hint); err != nil {
+		return nil, err
+	}
+	return &hint, nil
+}
+
+// Replay returns the seed and move log of a finished game
+func (c *Client) Replay(ctx context.Context, gameID string) (*game.Replay, error) {
+	var rep game.Replay
+	if err := c.do(ctx, http.MethodGet, "/api/replays/"+url.PathEscape(gameID), nil, false, &rep); err != nil {
+		return nil, err
+	}
+	return &rep, nil
+}
+
+// ReplayStep returns the state of a finished game after step moves
+func (c *Client) ReplayStep(ctx context.Context, gameID string, step int) (*ReplayStep, error) {
+	var rs ReplayStep
+	path := fmt.Sprintf("/api/replays/%s/step/%d", url.PathEscape(gameID), step)
+	if err := c.do(ctx, http.MethodGet, path, nil, false, &rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// Healthy reports whether the server process is alive
+func (c *Client) Healthy(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/healthz", nil, false, nil)
+}
+
+// Ready returns the server's readiness checks. A server that is not ready
+// returns an error matching ErrUnavailable.
+func (c *Client) Ready(ctx context.Context) (*Readiness, error) {
+	var readiness Readiness
+	if err := c.do(ctx, http.MethodGet, "/readyz", nil, false, &readiness); err != nil {
+		return nil, err
+	}
+	return &readiness, nil
+}
+
+// AdminSessions lists sessions on the server. Requires the admin token.
+func (c *Client) AdminSessions(ctx context.Context, f SessionFilter) (*SessionPage, error) {
+	params := url.Values{}
+	if f.State != "" {
+		params.Set("state", f.State)
+	}
+	if f.OwnerID != "" {
+		params.Set("owner", f.OwnerID)
+	}
+	if f.MinAge > 0 {
+		params.Set("min_age", f.MinAge.String())
+	}
+	if f.MaxAge > 0 {
+		params.Set("max_age", f.MaxAge.String())
+	}
+	if f.Offset > 0 {
+		params.Set("offset", strconv.Itoa(f.Offset))
+	}
+	if f.Limit > 0 {
+		params.Set("limit", strconv.Itoa(f.Limit))
+	}
+
+	path := "/admin/api/sessions"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	var page SessionPage
+	if err := c.doAdmin(ctx, http.MethodGet, path, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AdminInspect returns the full internal state of a session, including the
+// deck order. Requires the admin token.
+func (c *Client) AdminInspect(ctx context.Context, gameID string) (*game.Inspection, error) {
+	var inspection game.Inspection
+	if err := c.doAdmin(ctx, http.MethodGet, "/admin/api/sessions/"+url.PathEscape(gameID), &inspection); err != nil {
+		return nil, err
+	}
+	return &inspection, nil
+}
+
+// AdminEndSession forces a game to end as lost. Requires the admin token.
+func (c *Client) AdminEndSession(ctx context.Context, gameID string) error {
+	return c.doAdmin(ctx, http.MethodPost, "/admin/api/sessions/"+url.PathEscape(gameID)+"/end", nil)
+}
+
+// AdminDeleteSession removes a session. Requires the admin token.
+func (c *Client) AdminDeleteSession(ctx context.Context, gameID string) error {
+	return c.doAdmin(ctx, http.MethodDelete, "/admin/api/sessions/"+url.PathEscape(gameID), nil)
+}
+
+// AdminCleanup runs a reaper sweep and returns the number of sessions
+// removed. Requires the admin token.
+func (c *Client) AdminCleanup(ctx context.Context) (int, error) {
+	var response struct {
+		Removed int `json:"removed"`
+	}
+	if err := c.doAdmin(ctx, http.MethodPost, "/admin/api/cleanup", &response); err != nil {
+		return 0, err
+	}
+	return response.Removed, nil
+}
+
+// AdminExport writes training data from the finished games stored on the
+// server to w, in the format of opts. Requires the admin token.
+func (c *Client) AdminExport(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	params := url.Values{}
+	if opts.Format != "" {
+		params.Set("format", opts.Format)
+	}
+	if opts.Outcome != "" {
+		params.Set("outcome", opts.Outcome)
+	}
+	if opts.Rules != "" {
+		params.Set("rules", opts.Rules)
+	}
+	if opts.Dedup {
+		params.Set("dedup", "true")
+	}
+
+	path := "/admin/api/export"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil, c.adminToken)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return newError(resp, body)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// view performs a request returning a game state
+func (c *Client) view(ctx context.Context, method, path string, idempotent bool) (*game.View, error) {
+	var view game.View
+	if err := c.do(ctx, method, path, nil, idempotent, &view); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// doAdmin performs a request with the admin token. Admin actions are not retried.
+func (c *Client) doAdmin(ctx context.Context, method, path string, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, nil, c.adminToken)
+	if err != nil {
+		return err
+	}
+	return c.send(req, out)
+}
+
+// do performs a request with the player token, retrying transport errors,
+// throttling and temporary unavailability. GETs are always retried; other
+// methods only when idempotent, in which case every attempt carries the same
+// Idempotency-Key so the server applies the request at most once.
+func (c *Client) do(ctx context.Context, method, path string, in interface{}, idempotent bool, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	canRetry := method == http.MethodGet || idempotent
+	key := ""
+	if idempotent {
+		key = uuid.New().String()
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest(ctx, method, path, body, c.Token())
+		if err != nil {
+			return err
+		}
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+
+		err = c.send(req, out)
+		if err == nil || !canRetry || attempt >= c.retries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		// Wait as long as the server asked, or back off exponentially
+		wait := backoff
+		var e *Error
+		if errors.As(err, &e) && e.RetryAfter > 0 {
+			wait = e.RetryAfter
+		}
+		backoff = min(backoff*2, maxBackoff)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// newRequest builds a request with a bearer token and optional JSON body
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte, token string) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// send performs a request and decodes a JSON response into out
+func (c *Client) send(req *http.Request, out interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return newError(resp, body)
+	}
+
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,355 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/api"
+	"github.com/tippi-fifestarr/scoundrel/client"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+const adminToken = "admin-token-for-client-tests"
+
+// newTestServer starts an in-memory API server without rate limits
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	config := api.DefaultConfig()
+	config.DataDir = ""
+	config.CreateRate = 0
+	config.MoveRate = 0
+	config.HintRate = 0
+	config.AuthRate = 0
+	config.AdminToken = adminToken
+
+	s, err := api.NewServer(config)
+	if err != nil {
+		t.Fatalf("Error creating server: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// isOver reports whether a game state is final
+func isOver(view *game.View) bool {
+	return view.State == game.GameStateWon.String() || view.State == game.GameStateLost.String()
+}
+
+func TestPlayFullGame(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+	c := client.New(ts.URL)
+
+	account, err := c.Register(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+	if c.Token() != account.Token {
+		t.Error("Expected client to use the token from registration")
+	}
+
+	created, err := c.CreateGame(ctx, client.CreateGameOptions{Public: true})
+	if err != nil {
+		t.Fatalf("Error creating game: %v", err)
+	}
+	if created.OwnerID != account.PlayerID || !created.Public {
+		t.Errorf("Expected public game owned by alice, got %+v", created)
+	}
+
+	view, err := c.GetGame(ctx, created.GameID)
+	if err != nil {
+		t.Fatalf("Error getting game: %v", err)
+	}
+	if len(view.Room.Cards) == 0 {
+		t.Fatal("Expected cards in the first room")
+	}
+
+	// Always playing the first card finishes the game
+	moves := 0
+	for !isOver(view) {
+		if view, err = c.Apply(ctx, created.GameID, game.Action{Type: game.ActionPlay, Index: 0}); err != nil {
+			t.Fatalf("Error playing move %d: %v", moves+1, err)
+		}
+		moves++
+	}
+
+	rep, err := c.Replay(ctx, created.GameID)
+	if err != nil {
+		t.Fatalf("Error getting replay: %v", err)
+	}
+	if len(rep.Actions) != moves {
+		t.Errorf("Expected %d moves in replay, got %d", moves, len(rep.Actions))
+	}
+
+	step, err := c.ReplayStep(ctx, created.GameID, 1)
+	if err != nil {
+		t.Fatalf("Error getting replay step: %v", err)
+	}
+	if step.Action == nil || step.Total != moves {
+		t.Errorf("Expected step 1 of %d with an action, got %+v", moves, step)
+	}
+
+	board, err := c.Leaderboard(ctx, "all-time", client.LeaderboardQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("Error getting leaderboard: %v", err)
+	}
+	if len(board.Entries) != 1 || board.Entries[0].PlayerID != account.PlayerID {
+		t.Errorf("Expected alice on the leaderboard, got %+v", board.Entries)
+	}
+
+	games, err := c.MyGames(ctx)
+	if err != nil {
+		t.Fatalf("Error listing games: %v", err)
+	}
+	if len(games) != 1 {
+		t.Errorf("Expected 1 game for alice, got %d", len(games))
+	}
+}
+
+func TestWeaponRoundTrip(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+	c := client.New(ts.URL)
+
+	// Play the first weapon dealt, then read the game back
+	for attempt := 0; attempt < 20; attempt++ {
+		created, err := c.CreateGame(ctx, client.CreateGameOptions{})
+		if err != nil {
+			t.Fatalf("Error creating game: %v", err)
+		}
+		view, err := c.GetGame(ctx, created.GameID)
+		if err != nil {
+			t.Fatalf("Error getting game: %v", err)
+		}
+		for !isOver(view) && view.Player.EquippedWeapon == nil {
+			index := 0
+			for i, card := range view.Room.Cards {
+				if game.CardType(card.Type) == game.Weapon {
+					index = i
+				}
+			}
+			if view, err = c.Play(ctx, created.GameID, index); err != nil {
+				t.Fatalf("Error playing card: %v", err)
+			}
+		}
+		if view.Player.EquippedWeapon == nil {
+			continue
+		}
+
+		view, err = c.GetGame(ctx, created.GameID)
+		if err != nil {
+			t.Fatalf("Error getting game: %v", err)
+		}
+		if weapon := view.Player.EquippedWeapon; game.CardType(weapon.Type) != game.Weapon {
+			t.Errorf("Expected the equipped %s to be a weapon, got type %d", weapon.Display, weapon.Type)
+		}
+		return
+	}
+	t.Fatal("Expected a weapon to be equipped in one of the games")
+}
+
+func TestTypedErrors(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	alice := client.New(ts.URL)
+	alice.Register(ctx, "alice", "correct horse")
+	bob := client.New(ts.URL)
+	bob.Register(ctx, "bob", "correct horse")
+
+	if _, err := alice.GetGame(ctx, "missing"); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown game, got %v", err)
+	}
+
+	created, err := alice.CreateGame(ctx, client.CreateGameOptions{})
+	if err != nil {
+		t.Fatalf("Error creating game: %v", err)
+	}
+
+	if _, err := bob.Skip(ctx, created.GameID); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden moving in another player's game, got %v", err)
+	}
+
+	if _, err := alice.Hint(ctx, created.GameID); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for a hint in a ranked game, got %v", err)
+	}
+
+	if _, err := alice.Play(ctx, created.GameID, 9); !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("Expected ErrBadRequest for illegal move, got %v", err)
+	}
+
+	var apiErr *client.Error
+	_, err = client.New(ts.URL).Daily(ctx)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.RequestID == "" {
+		t.Errorf("Expected 401 error with request ID, got %v", err)
+	}
+
+	if _, err := alice.Daily(ctx); err != nil {
+		t.Fatalf("Error starting daily challenge: %v", err)
+	}
+	_, err = alice.Daily(ctx)
+	if !errors.Is(err, client.ErrConflict) || !errors.As(err, &apiErr) || apiErr.GameID == "" {
+		t.Errorf("Expected conflict carrying the existing game, got %v", err)
+	}
+}
+
+// dropFirstResponse forwards every request but loses the response of the first one
+type dropFirstResponse struct {
+	dropped atomic.Bool
+}
+
+func (d *dropFirstResponse) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil && d.dropped.CompareAndSwap(false, true) {
+		resp.Body.Close()
+		return nil, errors.New("connection reset")
+	}
+	return resp, err
+}
+
+func TestRetriedMoveAppliedOnce(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := client.New(ts.URL).CreateGame(ctx, client.CreateGameOptions{})
+	if err != nil {
+		t.Fatalf("Error creating game: %v", err)
+	}
+
+	// The skip reaches the server but its response is lost. Retrying it
+	// without an idempotency key would fail, since rooms cannot be skipped
+	// twice in a row.
+	flaky := client.New(ts.URL,
+		client.WithHTTPClient(&http.Client{Transport: &dropFirstResponse{}}),
+		client.WithRetries(2, time.Millisecond),
+	)
+	if _, err := flaky.Skip(ctx, created.GameID); err != nil {
+		t.Fatalf("Expected retried skip to succeed, got %v", err)
+	}
+
+	admin := client.New(ts.URL, client.WithAdminToken(adminToken))
+	inspection, err := admin.AdminInspect(ctx, created.GameID)
+	if err != nil {
+		t.Fatalf("Error inspecting game: %v", err)
+	}
+	if n := len(inspection.Actions); n != 1 {
+		t.Errorf("Expected the skip to be applied once, got %d actions", n)
+	}
+}
+
+func TestContextCancelsRetries(t *testing.T) {
+	// A server that is never ready
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	c := client.New(ts.URL, client.WithRetries(100, 10*time.Millisecond))
+	start := time.Now()
+	_, err := c.Ready(ctx)
+	if !errors.Is(err, client.ErrUnavailable) {
+		t.Errorf("Expected ErrUnavailable, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected retries to stop when the context ends, took %s", elapsed)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	c := client.New(ts.URL)
+	account, err := c.Register(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("Error registering: %v", err)
+	}
+
+	if err := c.Logout(ctx); err != nil {
+		t.Fatalf("Error logging out: %v", err)
+	}
+	if c.Token() != "" {
+		t.Error("Expected the client to drop its token after logging out")
+	}
+
+	// The revoked token no longer authenticates
+	revoked := client.New(ts.URL, client.WithToken(account.Token))
+	if _, err := revoked.MyGames(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized with a revoked token, got %v", err)
+	}
+}
+
+func TestGetGameAsSpectator(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	alice := client.New(ts.URL)
+	alice.Register(ctx, "alice", "correct horse")
+	created, err := alice.CreateGame(ctx, client.CreateGameOptions{})
+	if err != nil {
+		t.Fatalf("Error creating game: %v", err)
+	}
+
+	spectator := client.New(ts.URL)
+	if _, err := spectator.GetGame(ctx, created.GameID); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized reading an owned game without a token, got %v", err)
+	}
+	view, err := spectator.GetGameAsSpectator(ctx, created.GameID, created.SpectatorToken)
+	if err != nil {
+		t.Fatalf("Error reading game with the spectator token: %v", err)
+	}
+	if view.GameID != created.GameID {
+		t.Errorf("Expected game %s, got %s", created.GameID, view.GameID)
+	}
+	if _, err := spectator.GetGameAsSpectator(ctx, created.GameID, "wrong"); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden with a wrong spectator token, got %v", err)
+	}
+}
+
+func TestAdminExport(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	c := client.New(ts.URL)
+	created, err := c.CreateGame(ctx, client.CreateGameOptions{})
+	if err != nil {
+		t.Fatalf("Error creating game: %v", err)
+	}
+	view, err := c.GetGame(ctx, created.GameID)
+	if err != nil {
+		t.Fatalf("Error getting game: %v", err)
+	}
+	moves := 0
+	for !isOver(view) {
+		if view, err = c.Apply(ctx, created.GameID, game.Action{Type: game.ActionPlay, Index: 0}); err != nil {
+			t.Fatalf("Error playing move %d: %v", moves+1, err)
+		}
+		moves++
+	}
+
+	if err := c.AdminExport(ctx, io.Discard, client.ExportOptions{}); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized exporting without the admin token, got %v", err)
+	}
+
+	admin := client.New(ts.URL, client.WithAdminToken(adminToken))
+	var out bytes.Buffer
+	if err := admin.AdminExport(ctx, &out, client.ExportOptions{Format: "jsonl"}); err != nil {
+		t.Fatalf("Error exporting: %v", err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != moves {
+		t.Errorf("Expected one sample per move (%d), got %d", moves, lines)
+	}
+}
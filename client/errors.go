@@ -0,0 +1,105 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matched by errors.Is against an *Error
+var (
+	// ErrBadRequest is returned for invalid requests, including illegal moves
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized is returned when a token is missing or invalid
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the token does not grant access, e.g. another player's game
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is returned for unknown games, replays and boards
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned for duplicate registrations and repeated daily attempts
+	ErrConflict = errors.New("conflict")
+	// ErrRateLimited is returned when the server throttled the request
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnavailable is returned when the server is at capacity or not ready
+	ErrUnavailable = errors.New("service unavailable")
+	// ErrServer is returned for other server errors
+	ErrServer = errors.New("server error")
+)
+
+// Error is an error response from the server
+type Error struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Message is the error message sent by the server
+	Message string
+	// RequestID identifies the request in the server logs
+	RequestID string
+	// RetryAfter is how long the server asked the client to wait, if it did
+	RetryAfter time.Duration
+	// GameID is the existing game reported by a daily challenge conflict
+	GameID string
+}
+
+// Error returns the status and message of the response
+func (e *Error) Error() string {
+	return fmt.Sprintf("scoundrel: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is matches the sentinel error for the status code
+func (e *Error) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case http.StatusServiceUnavailable:
+		return target == ErrUnavailable
+	}
+	return e.StatusCode >= 500 && target == ErrServer
+}
+
+// newError builds an Error from a response and its body. Bodies are either
+// plain text or JSON with an "error" field.
+func newError(resp *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(body)),
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+
+	var payload struct {
+		Error  string `json:"error"`
+		GameID string `json:"game_id"`
+	}
+	if json.Unmarshal(body, &payload) == nil && payload.Error != "" {
+		e.Message = payload.Error
+		e.GameID = payload.GameID
+	}
+
+	if seconds, err := time.ParseDuration(resp.Header.Get("Retry-After") + "s"); err == nil {
+		e.RetryAfter = seconds
+	}
+	return e
+}
+
+// retryable reports whether a request failing with err may be sent again
+func retryable(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		// Transport errors, e.g. a dropped connection
+		return true
+	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable ||
+		e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusGatewayTimeout
+}
@@ -1,19 +1,45 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/tippi-fifestarr/scoundrel/api"
 )
 
 func main() {
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Load config from flags, environment and an optional config file
+	config, err := api.LoadConfig(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	logger := config.Logger(os.Stderr)
+	slog.SetDefault(logger)
+
+	server, err := api.NewServer(config)
+	if err != nil {
+		logger.Error("failed to start server", "error", err)
+		os.Exit(1)
+	}
+
+	// Shut down gracefully on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	server := api.NewServer()
-	log.Fatal(server.Start(":" + port))
+	if err := server.Run(ctx); err != nil {
+		logger.Error("server failed", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("server stopped")
 }
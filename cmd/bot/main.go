@@ -0,0 +1,47 @@
+// Command bot runs a built-in strategy as an engine protocol bot over stdin
+// and stdout, for use with cmd/host or cmd/cli --engine-protocol
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+func main() {
+	strategy := flag.String("strategy", "greedy", "strategy to play: "+strings.Join(bot.Names(), ", "))
+	seed := flag.Int64("seed", 0, "seed of strategies that make random choices (default: random)")
+	weightsFile := flag.String("weights", "", "weights file of the heuristic strategy, as saved by cmd/tune")
+	flag.Parse()
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	s, err := bot.New(*strategy, *seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *weightsFile != "" {
+		if *strategy != "heuristic" {
+			fmt.Fprintln(os.Stderr, "-weights needs -strategy heuristic")
+			os.Exit(2)
+		}
+		weights, err := bot.LoadWeights(*weightsFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		s = bot.NewHeuristic(weights)
+	}
+
+	if err := protocol.Serve(os.Stdin, os.Stdout, s); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
@@ -2,63 +2,293 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tippi-fifestarr/scoundrel/client"
 	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+	"github.com/tippi-fifestarr/scoundrel/replay"
+	"golang.org/x/term"
 )
 
+// Game is a game the terminal UI can show and play. A local *game.GameSession
+// satisfies it, as does a game hosted by an API server.
+type Game interface {
+	View() game.View
+	Apply(action game.Action) error
+}
+
 func main() {
-	fmt.Println("Scoundrel Card Game CLI")
-	fmt.Println("=======================")
-	fmt.Println("Starting new game...")
+	daily := flag.Bool("daily", false, "play today's daily challenge (same deal as the server's)")
+	seed := flag.Int64("seed", 0, "deal the dungeon from a fixed seed")
+	replayFrom := flag.String("replay", "", "step through a recorded game, from a replay file or a game ID in the data directory")
+	record := flag.String("record", "", "save the replay of this game to a file when it ends")
+	dataDir := flag.String("data-dir", "", "data directory of the API server, used to look up replays by game ID")
+	server := flag.String("server", "", "play a game hosted by the API server at this URL, e.g. http://localhost:8080")
+	gameID := flag.String("game", "", "with --server, resume the game with this ID instead of starting one")
+	token := flag.String("token", os.Getenv("SCOUNDREL_TOKEN"), "with --server, API token of the player (default $SCOUNDREL_TOKEN)")
+	lineMode := flag.Bool("line", false, "use the line-by-line interface even in a terminal")
+	tutorial := flag.Bool("tutorial", false, "learn the rules in a guided tutorial, one lesson per rule")
+	lesson := flag.String("lesson", "", "with --tutorial, play only this lesson (weapons, degradation, potions, skipping or scoring)")
+	scriptFrom := flag.String("script", "", "play the commands in this file (- for stdin) without prompting, printing the game as JSON lines")
+	engineProtocol := flag.Bool("engine-protocol", false, "host a local game for a bot speaking the engine protocol on stdin and stdout (see docs/protocol.md)")
+	moveTime := flag.Duration("move-time", 0, "with --engine-protocol, time the bot has for each move (0 for no limit)")
+	flag.Parse()
+
+	// The full-screen UI needs a terminal on both ends
+	fullScreen := !*lineMode && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+
+	// In script and engine protocol modes stdout carries only JSON or
+	// protocol messages, so messages go to stderr
+	console := io.Writer(os.Stdout)
+	if *scriptFrom != "" || *engineProtocol {
+		console = os.Stderr
+	} else {
+		fmt.Println("Scoundrel Card Game CLI")
+		fmt.Println("=======================")
+	}
+
+	if *replayFrom != "" {
+		rep, err := loadReplay(*replayFrom, *dataDir)
+		if err != nil {
+			fmt.Fprintf(console, "Error loading replay: %s\n", err)
+			os.Exit(1)
+		}
+		runReplay(rep)
+		return
+	}
+
+	var script io.Reader
+	switch *scriptFrom {
+	case "":
+	case "-":
+		script = os.Stdin
+	default:
+		f, err := os.Open(*scriptFrom)
+		if err != nil {
+			fmt.Fprintf(console, "Error opening script: %s\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		script = f
+	}
+
+	// Client of the API server, for remote games
+	api := client.New(*server, client.WithToken(*token), client.WithRetries(3, 200*time.Millisecond))
+
+	if *engineProtocol && (*tutorial || *lesson != "" || *server != "" || script != nil) {
+		fmt.Fprintln(console, "--engine-protocol only hosts local games, and cannot be combined with --tutorial or --script")
+		os.Exit(2)
+	}
+
+	if *tutorial || *lesson != "" {
+		if *daily || *seed != 0 || *gameID != "" || script != nil {
+			fmt.Fprintln(console, "--tutorial cannot be combined with --daily, --seed, --game or --script")
+			os.Exit(2)
+		}
+
+		// Play every lesson in order, or just the one asked for
+		var names []string
+		if *lesson != "" {
+			if _, ok := game.LessonByName(*lesson); !ok {
+				fmt.Fprintf(console, "Unknown lesson %q\n", *lesson)
+				os.Exit(2)
+			}
+			names = append(names, *lesson)
+		} else {
+			for _, l := range game.Lessons {
+				names = append(names, l.Name)
+			}
+		}
+
+		open := func(name string) (Game, error) {
+			return game.NewGameSessionWithOptions(game.SessionOptions{Tutorial: name}), nil
+		}
+		if *server != "" {
+			open = func(name string) (Game, error) {
+				remote, err := openRemoteGame(api, "", false, client.CreateGameOptions{Tutorial: name})
+				if err != nil {
+					return nil, err
+				}
+				return remote, nil
+			}
+		}
+
+		runTutorial(names, open)
+		return
+	}
+
+	if *server != "" {
+		if *seed != 0 || *record != "" {
+			fmt.Fprintln(console, "--seed and --record only apply to local games")
+			os.Exit(2)
+		}
+		if *daily && *token == "" {
+			fmt.Fprintln(console, "The daily challenge on a server needs a player --token")
+			os.Exit(2)
+		}
+
+		// Connect to the server's game
+		remote, err := openRemoteGame(api, *gameID, *daily, client.CreateGameOptions{})
+		if err != nil {
+			fmt.Fprintf(console, "Error connecting to server: %s\n", err)
+			os.Exit(1)
+		}
+		if remote.challenge != "" {
+			fmt.Fprintf(console, "Daily challenge for %s\n", remote.challenge)
+		}
+		fmt.Fprintf(console, "Playing game %s on %s\n", remote.id, *server)
+
+		if script != nil {
+			if code := playScript(remote, script, os.Stdout, console, remoteScore(remote)); code != 0 {
+				os.Exit(code)
+			}
+			return
+		}
+
+		playGame(remote, fullScreen)
+
+		score, err := remote.Score()
+		if err != nil {
+			fmt.Printf("Error getting final score: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Final score: %d\n", score)
+		return
+	}
+
+	if *gameID != "" {
+		fmt.Fprintln(console, "--game needs --server")
+		os.Exit(2)
+	}
+
+	opts := game.SessionOptions{Seed: *seed}
+	if *daily {
+		now := time.Now()
+		opts.Seed = game.DailySeed(now)
+		opts.Challenge = game.DailyDate(now)
+		fmt.Fprintf(console, "Daily challenge for %s\n", opts.Challenge)
+	}
+	fmt.Fprintln(console, "Starting new game...")
 
 	// Create a new game session
-	session := game.NewGameSession()
+	session := game.NewGameSessionWithOptions(opts)
+
+	// A bot forfeiting fails the run once its replay is saved
+	exitCode := 0
+	if *engineProtocol {
+		// The bot plays over stdin and stdout
+		b := protocol.NewBot(os.Stdin, os.Stdout)
+		if err := b.Handshake(context.Background()); err != nil {
+			fmt.Fprintf(console, "Error starting bot: %s\n", err)
+			os.Exit(1)
+		}
+		result, err := protocol.Host(context.Background(), session, b, *moveTime)
+		b.Quit()
+		b.Close()
+		if err != nil {
+			fmt.Fprintf(console, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		if result.Forfeit != "" {
+			fmt.Fprintf(console, "Bot forfeited: %s\n", result.Forfeit)
+			exitCode = 1
+		}
+		fmt.Fprintf(console, "Final score: %d\n", result.Score)
+	} else if script != nil {
+		if code := playScript(session, script, os.Stdout, console, localScore(session)); code != 0 {
+			os.Exit(code)
+		}
+	} else {
+		playGame(session, fullScreen)
+		fmt.Printf("Final score: %d\n", session.Score())
+	}
+
+	if *record != "" {
+		if err := replay.SaveFile(*record, session.Replay()); err != nil {
+			fmt.Fprintf(console, "Error saving replay: %s\n", err)
+		} else {
+			fmt.Fprintf(console, "Replay saved to %s\n", *record)
+		}
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// isOver reports whether the game has been won or lost
+func isOver(view game.View) bool {
+	return view.State == game.GameStateWon.String() || view.State == game.GameStateLost.String()
+}
+
+// playGame runs the game until it is over, in the full-screen UI or line by line
+func playGame(g Game, fullScreen bool) {
+	if fullScreen {
+		err := runTUI(g)
+		switch {
+		case err == errQuit:
+			fmt.Println("Quitting game...")
+			os.Exit(0)
+		case err != nil:
+			fmt.Printf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 
 	// Game loop
-	for !session.IsGameOver() {
+	for !isOver(g.View()) {
 		// Display game state
-		displayGameState(session)
+		displayGameState(g.View())
 
 		// Get player action
-		action, err := getPlayerAction(reader, session)
+		action, err := getPlayerAction(reader, g.View())
 		if err != nil {
 			fmt.Printf("Error: %s\n", err)
+			if err == io.EOF {
+				os.Exit(1)
+			}
 			continue
 		}
 
 		// Process action
-		executeAction(action, reader, session)
+		executeAction(action, reader, g)
 	}
 
 	// Game over
-	displayGameState(session)
-	if session.GetState() == game.GameStateWon {
+	view := g.View()
+	displayGameState(view)
+	if view.State == game.GameStateWon.String() {
 		fmt.Println("Congratulations! You won!")
 	} else {
 		fmt.Println("Game over! You lost.")
 	}
 }
 
-func displayGameState(session *game.GameSession) {
+func displayGameState(view game.View) {
 	fmt.Println("\n--------------------------------------------------")
-	fmt.Printf("Health: %d/%d\n", session.GetPlayer().Health(), session.GetPlayer().MaxHealth())
+	fmt.Printf("Health: %d/%d\n", view.Player.Health, view.Player.MaxHealth)
 
 	// Display equipped weapon
-	if weapon := session.GetPlayer().EquippedWeapon(); weapon != nil {
-		fmt.Printf("Equipped Weapon: %s (Value: %d)\n", weapon.String(), weapon.Value())
+	if weapon := view.Player.EquippedWeapon; weapon != nil {
+		fmt.Printf("Equipped Weapon: %s (Value: %d)\n", weapon.Display, weapon.Value)
 
-		if len(session.GetPlayer().DefeatedMonsters()) > 0 {
+		if len(view.Player.DefeatedMonsters) > 0 {
 			fmt.Print("Defeated monsters: ")
-			for i, monster := range session.GetPlayer().DefeatedMonsters() {
+			for i, monster := range view.Player.DefeatedMonsters {
 				if i > 0 {
 					fmt.Print(", ")
 				}
-				fmt.Print(monster.String())
+				fmt.Print(monster.Display)
 			}
 			fmt.Println()
 		}
@@ -67,46 +297,45 @@ func displayGameState(session *game.GameSession) {
 	}
 
 	// Add potion usage status for clarity
-	if session.GetPlayer().UsedPotionThisRoom() {
+	if view.Player.UsedPotion {
 		fmt.Println("Potion already used in this room (only one effective potion per room)")
 	}
 
 	// Display current room
-	if room := session.GetCurrentRoom(); room != nil {
+	if len(view.Room.Cards) > 0 {
 		fmt.Println("\nCurrent Room:")
-		for i, card := range room.Cards() {
-			fmt.Printf("[%d] %s ", i, card.String())
+		for _, card := range view.Room.Cards {
+			fmt.Printf("[%d] %s ", card.Index, card.Display)
 
-			switch card.Type() {
+			switch game.CardType(card.Type) {
 			case game.Monster:
-				fmt.Printf("(Monster, Damage: %d)", card.Value())
+				fmt.Printf("(Monster, Damage: %d)", card.Value)
 			case game.Weapon:
-				fmt.Printf("(Weapon, Value: %d)", card.Value())
+				fmt.Printf("(Weapon, Value: %d)", card.Value)
 			case game.Potion:
-				fmt.Printf("(Potion, Heal: %d)", card.Value())
+				fmt.Printf("(Potion, Heal: %d)", card.Value)
 			}
 			fmt.Println()
 		}
 	}
 
 	// Display deck info
-	fmt.Printf("\nCards remaining in dungeon: %d\n", session.GetDeck().Remaining())
-	if session.GetDeck().PrevRoomSkipped() {
+	fmt.Printf("\nCards remaining in dungeon: %d\n", view.Deck.RemainingCards)
+	if view.Deck.PreviousRoomSkipped {
 		fmt.Println("You skipped the previous room, you cannot skip this one.")
 	}
 	fmt.Println("--------------------------------------------------")
 }
 
-func getPlayerAction(reader *bufio.Reader, session *game.GameSession) (string, error) {
+func getPlayerAction(reader *bufio.Reader, view game.View) (string, error) {
 	// Display options
 	fmt.Println("\nActions:")
-	for i, card := range session.GetCurrentRoom().Cards() {
-		fmt.Printf("[%d] Play card %s\n", i, card.String())
+	for _, card := range view.Room.Cards {
+		fmt.Printf("[%d] Play card %s\n", card.Index, card.Display)
 	}
 
 	// Skip room option - only show if the previous room wasn't skipped AND no cards have been played yet
-	currentRoom := session.GetCurrentRoom()
-	if !session.GetDeck().PrevRoomSkipped() && len(currentRoom.Cards()) == 4 { // Original room has 4 cards, so no cards played yet
+	if !view.Deck.PreviousRoomSkipped && len(view.Room.Cards) == 4 { // Original room has 4 cards, so no cards played yet
 		fmt.Println("[s] Skip this room")
 	}
 
@@ -122,7 +351,9 @@ func getPlayerAction(reader *bufio.Reader, session *game.GameSession) (string, e
 	return strings.TrimSpace(input), nil
 }
 
-func executeAction(action string, reader *bufio.Reader, session *game.GameSession) {
+func executeAction(action string, reader *bufio.Reader, g Game) {
+	view := g.View()
+
 	// Check for quit
 	if action == "q" {
 		fmt.Println("Quitting game...")
@@ -132,18 +363,17 @@ func executeAction(action string, reader *bufio.Reader, session *game.GameSessio
 	// Check for skip room
 	if action == "s" {
 		// Only allow skipping if the previous room wasn't skipped AND no cards have been played yet
-		currentRoom := session.GetCurrentRoom()
-		if session.GetDeck().PrevRoomSkipped() {
+		if view.Deck.PreviousRoomSkipped {
 			fmt.Println("You cannot skip two rooms in a row!")
 			return
 		}
 
-		if len(currentRoom.Cards()) < 4 { // If cards have been played, the room has less than 4 cards
+		if len(view.Room.Cards) < 4 { // If cards have been played, the room has less than 4 cards
 			fmt.Println("You cannot skip a room after playing cards!")
 			return
 		}
 
-		err := session.SkipRoom()
+		err := g.Apply(game.Action{Type: game.ActionSkip})
 		if err != nil {
 			fmt.Printf("Error skipping room: %s\n", err)
 		} else {
@@ -160,69 +390,62 @@ func executeAction(action string, reader *bufio.Reader, session *game.GameSessio
 	}
 
 	// Check if the index is valid
-	if index < 0 || index >= len(session.GetCurrentRoom().Cards()) {
+	if index < 0 || index >= len(view.Room.Cards) {
 		fmt.Println("Invalid card index! Please try again.")
 		return
 	}
 
 	// Get the card before playing it to provide better feedback and check if it's a monster
-	card := session.GetCurrentRoom().Cards()[index]
+	card := view.Room.Cards[index]
+	cardType := game.CardType(card.Type)
+	play := game.Action{Type: game.ActionPlay, Index: index}
 
 	// If it's a monster and player has a weapon, ask if they want to use it
-	if card.Type() == game.Monster && session.GetPlayer().EquippedWeapon() != nil {
-		weapon := session.GetPlayer().EquippedWeapon()
-
+	if weapon := view.Player.EquippedWeapon; cardType == game.Monster && weapon != nil {
 		// Check if weapon can be used against this monster
-		canUseWeapon := session.GetPlayer().CanUseWeaponAgainst(card)
-		if canUseWeapon {
-			damage := card.Value() - weapon.Value()
+		if view.Player.CanUseWeaponAgainst(card.CardView) {
+			damage := card.Value - weapon.Value
 			if damage < 0 {
 				damage = 0
 			}
 
-			fmt.Printf("\nYou're facing a monster with value %d. You have a weapon with value %d.\n", card.Value(), weapon.Value())
+			fmt.Printf("\nYou're facing a monster with value %d. You have a weapon with value %d.\n", card.Value, weapon.Value)
 			fmt.Printf("Using your weapon would result in %d damage.\n", damage)
-			fmt.Printf("Fighting barehanded would result in %d damage.\n", card.Value())
+			fmt.Printf("Fighting barehanded would result in %d damage.\n", card.Value)
 			fmt.Print("Do you want to use your weapon? (y/n): ")
 
 			input, _ := reader.ReadString('\n')
 			input = strings.TrimSpace(input)
 
-			if strings.ToLower(input) == "y" || strings.ToLower(input) == "yes" {
-				// Use weapon
-				err = session.PlayCard(index)
-			} else {
+			if strings.ToLower(input) != "y" && strings.ToLower(input) != "yes" {
 				// Fight barehanded
-				err = session.PlayCardWithoutWeapon(index)
+				play.Type = game.ActionPlayBarehanded
 			}
 		} else {
-			fmt.Printf("\nYour weapon (%s) can't be used against this monster because it's stronger than the last monster you defeated.\n", weapon.String())
-			fmt.Printf("You'll take full damage of %d from this monster.\n", card.Value())
+			fmt.Printf("\nYour weapon (%s) can't be used against this monster because it's stronger than the last monster you defeated.\n", weapon.Display)
+			fmt.Printf("You'll take full damage of %d from this monster.\n", card.Value)
 
 			// Automatically fight barehanded
-			err = session.PlayCardWithoutWeapon(index)
+			play.Type = game.ActionPlayBarehanded
 		}
-	} else {
-		// For non-monster cards or when player has no weapon, just play normally
-		err = session.PlayCard(index)
 	}
 
-	if err != nil {
+	if err := g.Apply(play); err != nil {
 		fmt.Printf("Error playing card: %s\n", err)
 		return
 	}
 
 	// Provide feedback based on card type
-	switch card.Type() {
+	switch cardType {
 	case game.Monster:
-		fmt.Printf("Fought a monster with value %d.\n", card.Value())
+		fmt.Printf("Fought a monster with value %d.\n", card.Value)
 	case game.Weapon:
-		fmt.Printf("Equipped a weapon with value %d.\n", card.Value())
+		fmt.Printf("Equipped a weapon with value %d.\n", card.Value)
 	case game.Potion:
-		if !session.GetPlayer().UsedPotionThisRoom() {
-			fmt.Printf("Used a potion with value %d and restored health.\n", card.Value())
+		if !view.Player.UsedPotion {
+			fmt.Printf("Used a potion with value %d and restored health.\n", card.Value)
 		} else {
-			fmt.Printf("Used a potion with value %d but it had no effect (only one effective potion per room).\n", card.Value())
+			fmt.Printf("Used a potion with value %d but it had no effect (only one effective potion per room).\n", card.Value)
 		}
 	}
 }
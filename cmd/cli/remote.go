@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/client"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// requestTimeout bounds each call to the API server
+const requestTimeout = 30 * time.Second
+
+// remoteGame is a game hosted by an API server
+type remoteGame struct {
+	client *client.Client
+	id     string
+	view   game.View
+	// challenge is the date of the daily challenge, if one was started
+	challenge string
+}
+
+// openRemoteGame resumes gameID on the server, or starts a new game with opts
+// if it is empty. With daily set, it starts or resumes the player's daily
+// challenge instead.
+func openRemoteGame(c *client.Client, gameID string, daily bool, opts client.CreateGameOptions) (*remoteGame, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	var challenge string
+	switch {
+	case gameID != "":
+	case daily:
+		created, err := c.Daily(ctx)
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) && apiErr.GameID != "" {
+			// Resume today's attempt
+			gameID = apiErr.GameID
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		gameID = created.GameID
+		challenge = created.Challenge
+	default:
+		created, err := c.CreateGame(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		gameID = created.GameID
+	}
+
+	view, err := c.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteGame{client: c, id: gameID, view: *view, challenge: challenge}, nil
+}
+
+// View returns the state of the game after the last move
+func (r *remoteGame) View() game.View {
+	return r.view
+}
+
+// Apply sends a move to the server
+func (r *remoteGame) Apply(action game.Action) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	view, err := r.client.Apply(ctx, r.id, action)
+	if err != nil {
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) {
+			return errors.New(apiErr.Message)
+		}
+		return err
+	}
+	r.view = *view
+	return nil
+}
+
+// Score returns the final score from the server's replay of the finished game
+func (r *remoteGame) Score() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	rep, err := r.client.Replay(ctx, r.id)
+	if err != nil {
+		return 0, err
+	}
+	return rep.Score, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/replay"
+)
+
+// loadReplay reads a replay from a file, or by game ID from the local data directory
+func loadReplay(fileOrID, dataDir string) (game.Replay, error) {
+	if _, err := os.Stat(fileOrID); err == nil {
+		return replay.LoadFile(fileOrID)
+	}
+	if dataDir == "" {
+		return game.Replay{}, fmt.Errorf("%s is not a file; pass --data-dir to look it up as a game ID", fileOrID)
+	}
+	return replay.NewStore(filepath.Join(dataDir, "replays")).Get(fileOrID)
+}
+
+// runReplay steps forward and back through a recorded game
+func runReplay(rep game.Replay) {
+	reader := bufio.NewReader(os.Stdin)
+	step := 0
+	total := len(rep.Actions)
+
+	for {
+		session, err := rep.StateAt(step)
+		if err != nil {
+			fmt.Printf("Error rebuilding move %d: %s\n", step, err)
+			return
+		}
+
+		fmt.Printf("\nReplay of game %s - move %d/%d\n", rep.GameID, step, total)
+		if step > 0 {
+			fmt.Printf("Last move: %s\n", rep.Actions[step-1])
+		}
+		displayGameState(session.View())
+		if step == total {
+			fmt.Printf("Final result: %s, score %d\n", rep.State, rep.Score)
+		}
+
+		fmt.Println("\n[n] Next move  [p] Previous move  [g N] Go to move N  [q] Quit")
+		fmt.Print("\nEnter your choice: ")
+
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(input)
+		command := "n"
+		if len(fields) > 0 {
+			command = fields[0]
+		}
+
+		switch command {
+		case "n":
+			if step < total {
+				step++
+			}
+		case "p":
+			if step > 0 {
+				step--
+			}
+		case "g":
+			if len(fields) < 2 {
+				fmt.Println("Usage: g N")
+				continue
+			}
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 0 || n > total {
+				fmt.Printf("Move must be between 0 and %d\n", total)
+				continue
+			}
+			step = n
+		case "q":
+			return
+		default:
+			fmt.Println("Invalid action! Please try again.")
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// scriptMove is printed for the initial deal (move 0) and after each move of a script
+type scriptMove struct {
+	Type   string       `json:"type"`
+	Move   int          `json:"move"`
+	Action *game.Action `json:"action,omitempty"`
+	State  game.View    `json:"state"`
+}
+
+// scriptSummary is printed when a script has been played to the end
+type scriptSummary struct {
+	Type   string `json:"type"`
+	GameID string `json:"game_id"`
+	State  string `json:"state"`
+	// Score is omitted when it is not known yet, for a server game still in progress
+	Score  *int `json:"score,omitempty"`
+	Moves  int  `json:"moves"`
+	Health int  `json:"health"`
+}
+
+// scriptError is printed when a script stops at a bad command or an illegal move
+type scriptError struct {
+	Type    string `json:"type"`
+	Line    int    `json:"line"`
+	Command string `json:"command"`
+	Error   string `json:"error"`
+}
+
+// parseCommand parses a script command: "play N", "play N barehanded" or "skip"
+func parseCommand(command string) (game.Action, error) {
+	fields := strings.Fields(strings.ToLower(command))
+	switch {
+	case len(fields) == 1 && fields[0] == "skip":
+		return game.Action{Type: game.ActionSkip}, nil
+	case len(fields) >= 2 && len(fields) <= 3 && fields[0] == "play":
+		index, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return game.Action{}, fmt.Errorf("invalid card index %q", fields[1])
+		}
+		if len(fields) == 2 {
+			return game.Action{Type: game.ActionPlay, Index: index}, nil
+		}
+		if fields[2] == "barehanded" {
+			return game.Action{Type: game.ActionPlayBarehanded, Index: index}, nil
+		}
+	}
+	return game.Action{}, fmt.Errorf("unknown command %q (want \"play N\", \"play N barehanded\" or \"skip\")", command)
+}
+
+// playScript runs a script with runScript, reporting a failure on console,
+// and returns the exit code of the CLI: 0 if every command was played and
+// 1 if the script stopped early
+func playScript(g Game, r io.Reader, w, console io.Writer, score func() (*int, error)) int {
+	if err := runScript(g, r, w, score); err != nil {
+		fmt.Fprintf(console, "Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// localScore returns the score function of a script played on a local
+// session. The score only counts once the game is over, as on a server.
+func localScore(session *game.GameSession) func() (*int, error) {
+	return func() (*int, error) {
+		if !session.IsGameOver() {
+			return nil, nil
+		}
+		score := session.Score()
+		return &score, nil
+	}
+}
+
+// remoteScore returns the score function of a script played on a server,
+// which only knows the score once the game is over
+func remoteScore(remote *remoteGame) func() (*int, error) {
+	return func() (*int, error) {
+		if !isOver(remote.View()) {
+			return nil, nil
+		}
+		score, err := remote.Score()
+		return &score, err
+	}
+}
+
+// runScript plays the commands read from r, one per line, and writes the game
+// to w as JSON lines. Blank lines and lines starting with # are ignored.
+// score returns the final score, or nil if it is not known. It stops at the
+// first bad command or illegal move, reports it on w and returns its error.
+func runScript(g Game, r io.Reader, w io.Writer, score func() (*int, error)) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(scriptMove{Type: "move", State: g.View()}); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber, moves := 0, 0
+	for scanner.Scan() {
+		lineNumber++
+		command := strings.TrimSpace(scanner.Text())
+		if command == "" || strings.HasPrefix(command, "#") {
+			continue
+		}
+
+		action, err := parseCommand(command)
+		if err == nil {
+			err = g.Apply(action)
+		}
+		if err != nil {
+			enc.Encode(scriptError{Type: "error", Line: lineNumber, Command: command, Error: err.Error()})
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		moves++
+		if err := enc.Encode(scriptMove{Type: "move", Move: moves, Action: &action, State: g.View()}); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	final, err := score()
+	if err != nil {
+		return err
+	}
+	view := g.View()
+	return enc.Encode(scriptSummary{
+		Type:   "summary",
+		GameID: view.GameID,
+		State:  view.State,
+		Score:  final,
+		Moves:  moves,
+		Health: view.Player.Health,
+	})
+}
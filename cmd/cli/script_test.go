@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// testSeed deals the same dungeon in every script test
+const testSeed = 42
+
+// decodeLines decodes the JSON lines written by a script
+func decodeLines(t *testing.T, out string) []map[string]interface{} {
+	t.Helper()
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var v map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("Malformed output line %q: %v", line, err)
+		}
+		lines = append(lines, v)
+	}
+	return lines
+}
+
+// runTestScript plays a script on a fresh seeded game and returns the
+// session, the JSON lines written, the console output and the exit code
+func runTestScript(t *testing.T, script string) (*game.GameSession, []map[string]interface{}, string, int) {
+	t.Helper()
+
+	session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: testSeed})
+	var out, console bytes.Buffer
+	code := playScript(session, strings.NewReader(script), &out, &console, localScore(session))
+	return session, decodeLines(t, out.String()), console.String(), code
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    game.Action
+		wantErr bool
+	}{
+		{"skip", game.Action{Type: game.ActionSkip}, false},
+		{"SKIP", game.Action{Type: game.ActionSkip}, false},
+		{"play 2", game.Action{Type: game.ActionPlay, Index: 2}, false},
+		{"  play   0  ", game.Action{Type: game.ActionPlay, Index: 0}, false},
+		{"play 1 barehanded", game.Action{Type: game.ActionPlayBarehanded, Index: 1}, false},
+		{"Play 3 Barehanded", game.Action{Type: game.ActionPlayBarehanded, Index: 3}, false},
+		{"play", game.Action{}, true},
+		{"play x", game.Action{}, true},
+		{"play 1 with-weapon", game.Action{}, true},
+		{"play 1 barehanded now", game.Action{}, true},
+		{"skip 1", game.Action{}, true},
+		{"jump", game.Action{}, true},
+		{"", game.Action{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCommand(tt.command)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCommand(%q) error = %v, want error %v", tt.command, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseCommand(%q) = %+v, want %+v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestScriptStopsAtIllegalMove(t *testing.T) {
+	// Rooms cannot be skipped twice in a row
+	_, lines, console, code := runTestScript(t, "# opening\nskip\n\nskip\nplay 0\n")
+
+	if code == 0 {
+		t.Error("Expected a non-zero exit code for an illegal move")
+	}
+	if !strings.Contains(console, "line 4") {
+		t.Errorf("Expected the console to name the failing line, got %q", console)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected the deal, one move and an error, got %d lines", len(lines))
+	}
+	if lines[0]["type"] != "move" || lines[0]["move"] != 0.0 {
+		t.Errorf("Expected the deal as move 0, got %v", lines[0])
+	}
+	if lines[1]["type"] != "move" || lines[1]["move"] != 1.0 {
+		t.Errorf("Expected the skip as move 1, got %v", lines[1])
+	}
+
+	last := lines[2]
+	if last["type"] != "error" || last["line"] != 4.0 || last["command"] != "skip" || last["error"] == "" {
+		t.Errorf("Expected an error line for the second skip, got %v", last)
+	}
+}
+
+func TestScriptStopsAtBadCommand(t *testing.T) {
+	_, lines, _, code := runTestScript(t, "jump\n")
+
+	if code == 0 {
+		t.Error("Expected a non-zero exit code for a bad command")
+	}
+	last := lines[len(lines)-1]
+	if last["type"] != "error" || last["line"] != 1.0 || last["command"] != "jump" {
+		t.Errorf("Expected an error line for the bad command, got %v", last)
+	}
+}
+
+func TestScriptSummaryOmitsScoreUntilOver(t *testing.T) {
+	session, lines, _, code := runTestScript(t, "play 0\n")
+
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	summary := lines[len(lines)-1]
+	if summary["type"] != "summary" || summary["moves"] != 1.0 || summary["game_id"] != session.GetID() {
+		t.Errorf("Expected a summary of one move, got %v", summary)
+	}
+	if summary["state"] != game.GameStateInProgress.String() {
+		t.Errorf("Expected the game to be in progress, got %v", summary["state"])
+	}
+	if _, ok := summary["score"]; ok {
+		t.Errorf("Expected no score for a game in progress, got %v", summary["score"])
+	}
+}
+
+func TestScriptSummaryScoresFinishedGame(t *testing.T) {
+	// Find how many first-card plays finish the dungeon
+	probe := game.NewGameSessionWithOptions(game.SessionOptions{Seed: testSeed})
+	var script strings.Builder
+	moves := 0
+	for !probe.IsGameOver() {
+		if err := probe.Apply(game.Action{Type: game.ActionPlay, Index: 0}); err != nil {
+			t.Fatalf("Error playing move %d: %v", moves+1, err)
+		}
+		script.WriteString("play 0\n")
+		moves++
+	}
+
+	session, lines, _, code := runTestScript(t, script.String())
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d", code)
+	}
+	if len(lines) != moves+2 {
+		t.Errorf("Expected the deal, %d moves and a summary, got %d lines", moves, len(lines))
+	}
+
+	summary := lines[len(lines)-1]
+	if summary["type"] != "summary" || summary["moves"] != float64(moves) {
+		t.Errorf("Expected a summary of %d moves, got %v", moves, summary)
+	}
+	if summary["state"] != session.GetState().String() || summary["health"] != float64(session.GetPlayer().Health()) {
+		t.Errorf("Expected the final state and health, got %v", summary)
+	}
+	if summary["score"] != float64(session.Score()) {
+		t.Errorf("Expected score %d, got %v", session.Score(), summary["score"])
+	}
+}
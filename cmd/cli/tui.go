@@ -0,0 +1,554 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used by the terminal UI
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	clearScreen  = "\x1b[H\x1b[2J"
+
+	colorReset = "\x1b[0m"
+	bold       = "\x1b[1m"
+	dim        = "\x1b[2m"
+	reverse    = "\x1b[7m"
+	red        = "\x1b[31m"
+	green      = "\x1b[32m"
+	yellow     = "\x1b[33m"
+	cyan       = "\x1b[36m"
+)
+
+// Keys returned by parseKeys besides printable characters
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyEnter     = "enter"
+	keyEsc       = "esc"
+	keyInterrupt = "ctrl-c"
+)
+
+// errQuit is returned by runTUI when the player quits
+var errQuit = errors.New("quit")
+
+const (
+	// maxLogEntries is the number of moves kept for the log panel
+	maxLogEntries = 100
+	// healthBarWidth is the number of cells in the health bar
+	healthBarWidth = 20
+	// cardWidth is the width of a drawn card, borders included
+	cardWidth = 9
+	// accordionStep is how much of each covered card shows in the weapon stack
+	accordionStep = 4
+	// modalWidth is the inner width of the weapon choice box
+	modalWidth = 36
+)
+
+// logEntry is a line in the move log panel
+type logEntry struct {
+	text string
+	err  bool
+}
+
+// tui is the full-screen terminal UI
+type tui struct {
+	game   Game
+	in     io.Reader
+	out    io.Writer
+	width  int
+	height int
+
+	// selected is the room card under the cursor
+	selected int
+	log      []logEntry
+	moves    int
+
+	// confirm is the monster awaiting the weapon-or-barehanded choice
+	confirm *game.RoomCardView
+	// useWeapon is the highlighted choice in the confirmation box
+	useWeapon bool
+}
+
+// runTUI plays the game in the full-screen terminal UI until it is over.
+// It returns errQuit if the player quits first.
+func runTUI(g Game) error {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Print(altScreenOn + hideCursor)
+	defer fmt.Print(showCursor + altScreenOff)
+
+	t := &tui{game: g, in: os.Stdin, out: os.Stdout, width: 80, height: 24}
+	return t.run()
+}
+
+// run draws the screen and handles keys until the game is over
+func (t *tui) run() error {
+	buf := make([]byte, 64)
+	for {
+		if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 && height > 0 {
+			t.width, t.height = width, height
+		}
+		t.draw()
+
+		n, err := t.in.Read(buf)
+		if err != nil {
+			return err
+		}
+
+		// Any key leaves the game over screen
+		if isOver(t.game.View()) {
+			return nil
+		}
+
+		for _, key := range parseKeys(buf[:n]) {
+			if err := t.handleKey(key); err != nil {
+				return err
+			}
+			if isOver(t.game.View()) {
+				break
+			}
+		}
+	}
+}
+
+// parseKeys splits raw terminal input into keys
+func parseKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		switch {
+		case b[0] == 0x1b && len(b) >= 3 && (b[1] == '[' || b[1] == 'O'):
+			switch b[2] {
+			case 'A':
+				keys = append(keys, keyUp)
+			case 'B':
+				keys = append(keys, keyDown)
+			case 'C':
+				keys = append(keys, keyRight)
+			case 'D':
+				keys = append(keys, keyLeft)
+			}
+			b = b[3:]
+		case b[0] == 0x1b:
+			keys = append(keys, keyEsc)
+			b = b[1:]
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, keyEnter)
+			b = b[1:]
+		case b[0] == 3:
+			keys = append(keys, keyInterrupt)
+			b = b[1:]
+		default:
+			keys = append(keys, strings.ToLower(string(b[0])))
+			b = b[1:]
+		}
+	}
+	return keys
+}
+
+// handleKey performs the action bound to a key
+func (t *tui) handleKey(key string) error {
+	if key == keyInterrupt || key == "q" {
+		return errQuit
+	}
+	if t.confirm != nil {
+		t.handleConfirmKey(key)
+		return nil
+	}
+
+	cards := t.game.View().Room.Cards
+	switch key {
+	case keyLeft, keyUp, "h":
+		if t.selected > 0 {
+			t.selected--
+		}
+	case keyRight, keyDown, "l":
+		if t.selected < len(cards)-1 {
+			t.selected++
+		}
+	case keyEnter, " ":
+		t.play(t.selected)
+	case "s":
+		t.skip()
+	case "1", "2", "3", "4":
+		// Number keys match the labels under the cards, which count from
+		// 1, while line mode and the API take 0-based indices
+		if index := int(key[0] - '1'); index < len(cards) {
+			t.selected = index
+			t.play(index)
+		}
+	}
+	return nil
+}
+
+// handleConfirmKey answers the weapon-or-barehanded choice
+func (t *tui) handleConfirmKey(key string) {
+	card := t.confirm
+	switch key {
+	case keyLeft, keyRight, keyUp, keyDown, "h", "l":
+		t.useWeapon = !t.useWeapon
+	case "w", "y":
+		t.confirm = nil
+		t.apply(game.Action{Type: game.ActionPlay, Index: card.Index})
+	case "b", "n":
+		t.confirm = nil
+		t.apply(game.Action{Type: game.ActionPlayBarehanded, Index: card.Index})
+	case keyEnter, " ":
+		t.confirm = nil
+		if t.useWeapon {
+			t.apply(game.Action{Type: game.ActionPlay, Index: card.Index})
+		} else {
+			t.apply(game.Action{Type: game.ActionPlayBarehanded, Index: card.Index})
+		}
+	case keyEsc, "c":
+		t.confirm = nil
+	}
+}
+
+// play plays a room card, asking first when a monster could be fought either way
+func (t *tui) play(index int) {
+	view := t.game.View()
+	if index < 0 || index >= len(view.Room.Cards) {
+		return
+	}
+	card := view.Room.Cards[index]
+
+	weapon := view.Player.EquippedWeapon
+	if game.CardType(card.Type) == game.Monster && weapon != nil {
+		if view.Player.CanUseWeaponAgainst(card.CardView) {
+			t.confirm = &card
+			t.useWeapon = true
+			return
+		}
+		t.logf(false, "%s is stronger than the last monster %s defeated", card.Display, weapon.Display)
+		t.apply(game.Action{Type: game.ActionPlayBarehanded, Index: index})
+		return
+	}
+
+	t.apply(game.Action{Type: game.ActionPlay, Index: index})
+}
+
+// skip skips the current room if the rules allow it
+func (t *tui) skip() {
+	view := t.game.View()
+	switch {
+	case view.Deck.PreviousRoomSkipped:
+		t.logf(true, "You cannot skip two rooms in a row")
+	case len(view.Room.Cards) < 4:
+		t.logf(true, "You cannot skip a room after playing cards")
+	default:
+		t.apply(game.Action{Type: game.ActionSkip})
+	}
+}
+
+// apply makes a move and records it in the log
+func (t *tui) apply(action game.Action) {
+	before := t.game.View()
+	if err := t.game.Apply(action); err != nil {
+		t.logf(true, "Cannot %s: %s", action, err)
+		return
+	}
+	after := t.game.View()
+
+	t.moves++
+	t.logf(false, "%d. %s", t.moves, describeMove(before, after, action))
+	if t.selected >= len(after.Room.Cards) {
+		t.selected = len(after.Room.Cards) - 1
+	}
+	if t.selected < 0 {
+		t.selected = 0
+	}
+}
+
+// logf adds a line to the move log
+func (t *tui) logf(isErr bool, format string, args ...interface{}) {
+	t.log = append(t.log, logEntry{text: fmt.Sprintf(format, args...), err: isErr})
+	if len(t.log) > maxLogEntries {
+		t.log = t.log[len(t.log)-maxLogEntries:]
+	}
+}
+
+// describeMove summarizes the effect of a move for the log
+func describeMove(before, after game.View, action game.Action) string {
+	if action.Type == game.ActionSkip {
+		return "Skipped the room"
+	}
+
+	card := before.Room.Cards[action.Index]
+	healthChange := after.Player.Health - before.Player.Health
+	switch game.CardType(card.Type) {
+	case game.Monster:
+		if weapon := before.Player.EquippedWeapon; action.Type == game.ActionPlay && weapon != nil {
+			return fmt.Sprintf("Fought %s with %s, took %d damage", card.Display, weapon.Display, -healthChange)
+		}
+		return fmt.Sprintf("Fought %s barehanded, took %d damage", card.Display, -healthChange)
+	case game.Weapon:
+		return fmt.Sprintf("Equipped %s", card.Display)
+	case game.Potion:
+		if before.Player.UsedPotion {
+			return fmt.Sprintf("Drank %s, no effect (one potion per room)", card.Display)
+		}
+		return fmt.Sprintf("Drank %s, healed %d", card.Display, healthChange)
+	}
+	return action.String()
+}
+
+// draw renders the whole screen
+func (t *tui) draw() {
+	view := t.game.View()
+	var lines []string
+
+	// Header
+	title := bold + "SCOUNDREL" + colorReset
+	dungeon := fmt.Sprintf("Dungeon: %d cards", view.Deck.RemainingCards)
+	lines = append(lines, " "+title+strings.Repeat(" ", max(1, t.width-len("SCOUNDREL")-len(dungeon)-3))+dungeon)
+	lines = append(lines, " Health "+healthBar(view.Player.Health, view.Player.MaxHealth))
+	lines = append(lines, "")
+
+	// Room
+	var notes []string
+	if view.Player.UsedPotion {
+		notes = append(notes, "potion used this room")
+	}
+	if view.Deck.PreviousRoomSkipped {
+		notes = append(notes, "cannot skip this room")
+	}
+	heading := " " + bold + "Room" + colorReset
+	if len(notes) > 0 {
+		heading += "  " + dim + strings.Join(notes, ", ") + colorReset
+	}
+	lines = append(lines, heading)
+	lines = append(lines, t.roomLines(view)...)
+	lines = append(lines, "")
+
+	// Weapon and the monsters it has defeated
+	lines = append(lines, " "+bold+"Weapon"+colorReset)
+	lines = append(lines, weaponLines(view.Player)...)
+	lines = append(lines, "")
+
+	// Move log, as much as fits above the footer
+	lines = append(lines, " "+bold+"Log"+colorReset)
+	room := t.height - len(lines) - 2
+	if room < 1 {
+		room = 1
+	}
+	entries := t.log
+	if len(entries) > room {
+		entries = entries[len(entries)-room:]
+	}
+	for _, entry := range entries {
+		text := " " + truncate(entry.text, t.width-2)
+		if entry.err {
+			text = red + text + colorReset
+		}
+		lines = append(lines, text)
+	}
+	for i := len(entries); i < room; i++ {
+		lines = append(lines, "")
+	}
+
+	// Footer
+	switch {
+	case view.State == game.GameStateWon.String():
+		lines = append(lines, " "+bold+green+"You won! Press any key."+colorReset)
+	case view.State == game.GameStateLost.String():
+		lines = append(lines, " "+bold+red+"You died in the dungeon. Press any key."+colorReset)
+	default:
+		lines = append(lines, dim+truncate(" ←/→ select  enter play  1-4 play card  s skip  q quit", t.width)+colorReset)
+	}
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	b.WriteString(strings.Join(lines, "\n"))
+	if t.confirm != nil {
+		t.drawConfirm(&b, view)
+	}
+	io.WriteString(t.out, b.String())
+}
+
+// roomLines draws the room cards side by side with their keys below
+func (t *tui) roomLines(view game.View) []string {
+	if len(view.Room.Cards) == 0 {
+		return []string{"  (empty)"}
+	}
+
+	lines := make([]string, 6)
+	for i, card := range view.Room.Cards {
+		box := cardBox(card.CardView)
+		style := cardColor(card.CardView)
+		if i == t.selected && !isOver(view) {
+			style += bold
+		}
+		for row, line := range box {
+			lines[row] += " " + style + line + colorReset
+		}
+
+		label := center(fmt.Sprintf("%d %s %d", i+1, typeLabel(card.CardView), card.Value), cardWidth)
+		if i == t.selected && !isOver(view) {
+			label = reverse + label + colorReset
+		}
+		lines[5] += " " + label
+	}
+	return lines
+}
+
+// weaponLines draws the equipped weapon with the monsters it has defeated
+// stacked over it like an accordion, each covering all but the corner of the
+// card below
+func weaponLines(player game.PlayerView) []string {
+	if player.EquippedWeapon == nil {
+		return []string{"  (none)"}
+	}
+
+	stack := append([]game.CardView{*player.EquippedWeapon}, player.DefeatedMonsters...)
+	lines := make([]string, 5)
+	for i := range lines {
+		lines[i] = " "
+	}
+	for i, card := range stack {
+		box := cardBox(card)
+		for row, line := range box {
+			if i < len(stack)-1 {
+				line = string([]rune(line)[:accordionStep])
+			}
+			lines[row] += cardColor(card) + line + colorReset
+		}
+	}
+	return lines
+}
+
+// drawConfirm overlays the weapon-or-barehanded choice in the middle of the screen
+func (t *tui) drawConfirm(b *strings.Builder, view game.View) {
+	card := t.confirm.CardView
+	weapon := view.Player.EquippedWeapon
+
+	weaponOption, barehandedOption := "  Weapon  ", "  Barehanded  "
+	if t.useWeapon {
+		weaponOption = reverse + weaponOption + colorReset
+	} else {
+		barehandedOption = reverse + barehandedOption + colorReset
+	}
+	options := "   " + weaponOption + "    " + barehandedOption
+	optionsWidth := 3 + 10 + 4 + 14
+
+	rows := []string{
+		pad(fmt.Sprintf(" Fight %s (%d)", card.Display, card.Value), modalWidth),
+		pad(fmt.Sprintf(" With %s: take %d damage", weapon.Display, max(0, card.Value-weapon.Value)), modalWidth),
+		pad(fmt.Sprintf(" Barehanded: take %d damage", card.Value), modalWidth),
+		pad("", modalWidth),
+		options + strings.Repeat(" ", modalWidth-optionsWidth),
+		dim + pad(" w/b, or ←/→ and enter; esc cancels", modalWidth) + colorReset,
+	}
+
+	top := max(1, t.height/2-len(rows)/2-1)
+	left := max(1, (t.width-modalWidth-2)/2+1)
+	fmt.Fprintf(b, "\x1b[%d;%dH%s┌%s┐%s", top, left, bold, strings.Repeat("─", modalWidth), colorReset)
+	for i, row := range rows {
+		fmt.Fprintf(b, "\x1b[%d;%dH%s│%s%s%s│%s", top+1+i, left, bold, colorReset, row, bold, colorReset)
+	}
+	fmt.Fprintf(b, "\x1b[%d;%dH%s└%s┘%s", top+1+len(rows), left, bold, strings.Repeat("─", modalWidth), colorReset)
+}
+
+// cardBox draws a card as five lines of cardWidth columns
+func cardBox(card game.CardView) []string {
+	inner := cardWidth - 2
+	suit := string([]rune(card.Display)[utf8.RuneCountInString(card.Display)-1])
+	return []string{
+		"┌" + strings.Repeat("─", inner) + "┐",
+		"│" + pad(card.Display, inner) + "│",
+		"│" + center(suit, inner) + "│",
+		"│" + strings.Repeat(" ", inner-utf8.RuneCountInString(card.Display)) + card.Display + "│",
+		"└" + strings.Repeat("─", inner) + "┘",
+	}
+}
+
+// cardColor returns the color of a card's type
+func cardColor(card game.CardView) string {
+	switch game.CardType(card.Type) {
+	case game.Monster:
+		return red
+	case game.Weapon:
+		return cyan
+	case game.Potion:
+		return green
+	}
+	return ""
+}
+
+// typeLabel is the short name of what a card's value means
+func typeLabel(card game.CardView) string {
+	switch game.CardType(card.Type) {
+	case game.Monster:
+		return "Dmg"
+	case game.Weapon:
+		return "Atk"
+	case game.Potion:
+		return "Heal"
+	}
+	return ""
+}
+
+// healthBar draws the player's health as a colored bar
+func healthBar(health, maxHealth int) string {
+	if maxHealth <= 0 {
+		maxHealth = 1
+	}
+	filled := health * healthBarWidth / maxHealth
+	filled = min(max(filled, 0), healthBarWidth)
+
+	color := green
+	switch {
+	case health*3 <= maxHealth:
+		color = red
+	case health*3 <= maxHealth*2:
+		color = yellow
+	}
+	return fmt.Sprintf("%s%s%s%s%s %d/%d", color, strings.Repeat("█", filled), dim, strings.Repeat("░", healthBarWidth-filled), colorReset, health, maxHealth)
+}
+
+// pad fills s with spaces to width columns
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// center places s in the middle of width columns
+func center(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= width {
+		return s
+	}
+	left := (width - n) / 2
+	return strings.Repeat(" ", left) + s + strings.Repeat(" ", width-n-left)
+}
+
+// truncate cuts s to at most width columns
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width])
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []string{keyUp, keyDown, keyRight, keyLeft}},
+		{"application mode arrows", "\x1bOA\x1bOD", []string{keyUp, keyLeft}},
+		{"lone escape", "\x1b", []string{keyEsc}},
+		{"enter", "\r\n", []string{keyEnter, keyEnter}},
+		{"interrupt", "\x03", []string{keyInterrupt}},
+		{"letters are lowercased", "S q", []string{"s", " ", "q"}},
+		{"digits", "14", []string{"1", "4"}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseKeys(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeGame is a game whose view never changes, recording the moves made
+type fakeGame struct {
+	view    game.View
+	actions []game.Action
+}
+
+func (g *fakeGame) View() game.View { return g.view }
+
+func (g *fakeGame) Apply(action game.Action) error {
+	g.actions = append(g.actions, action)
+	return nil
+}
+
+// TestNumberKeysCountFromOne verifies that the number keys match the card
+// labels, which count from 1, rather than the 0-based indices of line mode
+func TestNumberKeysCountFromOne(t *testing.T) {
+	potion := func(index int) game.RoomCardView {
+		return game.RoomCardView{Index: index, CardView: game.CardView{Type: int(game.Potion), Value: 2, Display: "2♥"}}
+	}
+	tests := []struct {
+		key  string
+		want []game.Action
+	}{
+		{"1", []game.Action{{Type: game.ActionPlay, Index: 0}}},
+		{"3", []game.Action{{Type: game.ActionPlay, Index: 2}}},
+		{"4", nil}, // only three cards left
+		{"0", nil},
+	}
+	for _, tt := range tests {
+		g := &fakeGame{view: game.View{
+			State:  game.GameStateInProgress.String(),
+			Player: game.PlayerView{Health: 20, MaxHealth: 20},
+			Room:   game.RoomView{Cards: []game.RoomCardView{potion(0), potion(1), potion(2)}},
+		}}
+		ui := &tui{game: g, out: io.Discard, width: 80, height: 24}
+		if err := ui.handleKey(tt.key); err != nil {
+			t.Fatalf("Key %s: unexpected error %v", tt.key, err)
+		}
+		if !reflect.DeepEqual(g.actions, tt.want) {
+			t.Errorf("Key %s: expected moves %v, got %v", tt.key, tt.want, g.actions)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runTutorial plays the named lessons in order, opening a tutorial game for each
+func runTutorial(lessons []string, open func(lesson string) (Game, error)) {
+	reader := bufio.NewReader(os.Stdin)
+
+	for i, name := range lessons {
+		g, err := open(name)
+		if err != nil {
+			fmt.Printf("Error starting lesson %s: %s\n", name, err)
+			os.Exit(1)
+		}
+		runLesson(g, reader, i+1, len(lessons))
+
+		if i < len(lessons)-1 {
+			fmt.Print("\nPress enter for the next lesson, or q to stop: ")
+			input, err := reader.ReadString('\n')
+			if err != nil || input == "q\n" {
+				return
+			}
+		}
+	}
+	fmt.Println("\nTutorial complete!")
+}
+
+// runLesson plays a tutorial game line by line, showing the prompt of each
+// step and explaining the outcome of every move
+func runLesson(g Game, reader *bufio.Reader, number, total int) {
+	tutorial := g.View().Tutorial
+	fmt.Printf("\n==================================================\n")
+	fmt.Printf("Lesson %d of %d: %s\n", number, total, tutorial.Title)
+	fmt.Println(tutorial.Intro)
+
+	for !isOver(g.View()) {
+		view := g.View()
+		displayGameState(view)
+		fmt.Printf("\nStep %d of %d: %s\n", view.Tutorial.Step+1, view.Tutorial.Steps, view.Tutorial.Prompt)
+
+		// Get player action
+		action, err := getPlayerAction(reader, view)
+		if err != nil {
+			fmt.Printf("Error: %s\n", err)
+			if err == io.EOF {
+				os.Exit(1)
+			}
+			continue
+		}
+
+		// Process action; moves the lesson did not ask for are rejected with a hint
+		executeAction(action, reader, g)
+
+		if after := g.View().Tutorial; after.Step > view.Tutorial.Step {
+			fmt.Printf("\n>> %s\n", after.Outcome)
+		}
+	}
+
+	view := g.View()
+	displayGameState(view)
+	fmt.Printf("\nLesson complete. %s\n", view.Tutorial.Outro)
+}
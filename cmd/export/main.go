@@ -0,0 +1,127 @@
+// Command export writes training data, one sample per move, from simulated
+// games and replay files, as JSON Lines or CSV.
+//
+//	export -sim greedy -seeds 1-1000 -format csv -o greedy.csv
+//	export -outcome won -dedup data/replays game.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/dataset"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/replay"
+	"github.com/tippi-fifestarr/scoundrel/tournament"
+)
+
+func main() {
+	format := flag.String("format", "jsonl", "output format: jsonl or csv")
+	output := flag.String("o", "-", "output file (- for stdout)")
+	sim := flag.String("sim", "", "simulate games with these strategies, comma separated: "+strings.Join(bot.Names(), ", "))
+	seedList := flag.String("seeds", "1-100", "with -sim, seeds to simulate, e.g. 1-100 or 7,42,1000-1009")
+	botSeed := flag.Int64("bot-seed", 1, "with -sim, seed of strategies that make random choices")
+	outcome := flag.String("outcome", "", "export only games with this outcome: won or lost")
+	rules := flag.String("rules", "", "export only games played under this rule set, e.g. "+game.StandardRules)
+	dedup := flag.Bool("dedup", false, "export only the first sample of each visible state")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [replay files or directories...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *sim == "" && flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *outcome != "" && !strings.EqualFold(*outcome, "won") && !strings.EqualFold(*outcome, "lost") {
+		fmt.Fprintln(os.Stderr, "-outcome must be won or lost")
+		os.Exit(2)
+	}
+
+	out := io.Writer(os.Stdout)
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+	writer, err := dataset.NewWriter(out, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	exporter := dataset.NewExporter(writer, dataset.Options{Outcome: *outcome, Rules: *rules, Dedup: *dedup})
+
+	if *sim != "" {
+		seeds, err := tournament.ParseSeeds(*seedList)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		for _, name := range strings.Split(*sim, ",") {
+			s, err := bot.New(strings.TrimSpace(name), *botSeed)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(2)
+			}
+			for _, seed := range seeds {
+				rep, err := dataset.Simulate(s, seed)
+				if err == nil {
+					err = exporter.AddReplay(rep, dataset.SourceSimulation, s.Name())
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error simulating %s on seed %d: %s\n", s.Name(), seed, err)
+					os.Exit(1)
+				}
+			}
+		}
+	}
+
+	for _, arg := range flag.Args() {
+		paths, err := replayFiles(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		for _, path := range paths {
+			rep, err := replay.LoadFile(path)
+			if err == nil {
+				err = exporter.AddReplay(rep, dataset.SourceReplay, rep.OwnerID)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping %s: %s\n", path, err)
+			}
+		}
+	}
+
+	if err := exporter.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d samples from %d games", exporter.Samples, exporter.Games)
+	if *dedup {
+		fmt.Fprintf(os.Stderr, " (%d duplicate states skipped)", exporter.Duplicates)
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// replayFiles returns the replay files in a directory, or the file itself
+func replayFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	return filepath.Glob(filepath.Join(path, "*.json"))
+}
@@ -0,0 +1,23 @@
+// Command gym serves the reinforcement learning environment as JSON lines
+// over stdin and stdout, so trainers in any language can drive it. See
+// docs/gym.md for the commands.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tippi-fifestarr/scoundrel/gym"
+)
+
+func main() {
+	sparse := flag.Bool("sparse", false, "give the official score as the only reward, at the end of each game")
+	flag.Parse()
+
+	env := gym.New(gym.Options{Sparse: *sparse})
+	if err := env.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
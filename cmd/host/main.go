@@ -0,0 +1,97 @@
+// Command host launches an executable as a Scoundrel player speaking the
+// engine protocol and plays it through a series of seeded dungeons. It
+// exits with status 1 if the bot forfeits a game or the host has to stop.
+//
+//	host [flags] path/to/bot [bot args...]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+func main() {
+	seed := flag.Int64("seed", 1, "seed of the first dungeon; each following game uses the next seed")
+	games := flag.Int("games", 1, "number of games to play")
+	moveTime := flag.Duration("move-time", time.Second, "time the bot has for each move (0 for no limit)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] path/to/bot [bot args...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || *games < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Stop between moves on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	p, err := protocol.Launch(flag.Arg(0), flag.Args()[1:]...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error launching bot: %s\n", err)
+		os.Exit(1)
+	}
+	if err := p.Handshake(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting bot: %s\n", err)
+		p.Close()
+		os.Exit(1)
+	}
+	name := p.Name
+	if name == "" {
+		name = flag.Arg(0)
+	}
+	fmt.Printf("Playing %d games with %s\n", *games, name)
+
+	// A forfeit or a stop fails the run once the games are summed up
+	exitCode := 0
+	played, wins, forfeits, total := 0, 0, 0, 0
+	for i := 0; i < *games; i++ {
+		gameSeed := *seed + int64(i)
+		session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: gameSeed, Practice: true})
+
+		result, err := protocol.Host(ctx, session, p.Bot, *moveTime)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Stopped: %s\n", err)
+			exitCode = 1
+			break
+		}
+
+		line := fmt.Sprintf("seed %d: %s with %d in %d moves", gameSeed, result.State, result.Score, result.Moves)
+		if result.Forfeit != "" {
+			line += fmt.Sprintf(" (forfeit: %s)", result.Forfeit)
+			forfeits++
+			exitCode = 1
+		}
+		fmt.Println(line)
+
+		played++
+		if result.State == game.GameStateWon.String() {
+			wins++
+		}
+		total += result.Score
+
+		// A bot that timed out or closed the connection cannot play on
+		if result.Fatal() {
+			break
+		}
+	}
+
+	if played > 0 {
+		fmt.Printf("Won %d of %d, mean score %.1f, %d forfeits\n", wins, played, float64(total)/float64(played), forfeits)
+	}
+
+	p.Close()
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
@@ -0,0 +1,132 @@
+// Command tournament plays several bots on the same seeds and writes a
+// ranked comparison as Markdown and JSON.
+//
+//	tournament -bot greedy -bot random -bot "python3 mybot.py" -seeds 1-500
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/tournament"
+)
+
+func main() {
+	var bots []string
+	flag.Func("bot", "a player: a built-in strategy ("+strings.Join(bot.Names(), ", ")+") or the command line of an engine protocol bot; repeat for each player", func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("empty bot")
+		}
+		bots = append(bots, s)
+		return nil
+	})
+	seedList := flag.String("seeds", "1-100", "seeds to play, e.g. 1-100 or 7,42,1000-1009; negative seeds are allowed, zero is not")
+	seedFile := flag.String("seed-file", "", "read the seeds from this file instead, whitespace or comma separated; # starts a comment")
+	moveTime := flag.Duration("move-time", time.Second, "time an external bot has for each move (0 for no limit)")
+	botSeed := flag.Int64("bot-seed", 1, "seed of built-in strategies that make random choices")
+	mdOut := flag.String("md", "-", "write the Markdown report to this file (- for stdout, empty to skip)")
+	jsonOut := flag.String("json", "", "write the JSON report to this file (- for stdout)")
+	flag.Parse()
+
+	if len(bots) < 2 {
+		fmt.Fprintln(os.Stderr, "A tournament needs at least two -bot players")
+		os.Exit(2)
+	}
+
+	seeds, err := loadSeeds(*seedList, *seedFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// Stop on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var players []tournament.Player
+	defer func() {
+		for _, p := range players {
+			if closer, ok := p.(io.Closer); ok {
+				closer.Close()
+			}
+		}
+	}()
+	for _, spec := range bots {
+		player, err := newPlayer(ctx, spec, *botSeed, *moveTime)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		players = append(players, player)
+	}
+
+	fmt.Fprintf(os.Stderr, "Playing %d players on %d seeds...\n", len(players), len(seeds))
+	report, err := tournament.Run(ctx, players, seeds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := writeReport(*mdOut, report.WriteMarkdown); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing Markdown report: %s\n", err)
+		os.Exit(1)
+	}
+	if err := writeReport(*jsonOut, report.WriteJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing JSON report: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// newPlayer returns the built-in strategy named spec, or launches spec as
+// the command line of an external bot
+func newPlayer(ctx context.Context, spec string, seed int64, moveTime time.Duration) (tournament.Player, error) {
+	if s, err := bot.New(spec, seed); err == nil {
+		return tournament.NewStrategyPlayer(s), nil
+	}
+	return tournament.NewExternalPlayer(ctx, strings.Fields(spec), moveTime)
+}
+
+// loadSeeds parses the seed list, or the seed file if one is given
+func loadSeeds(list, file string) ([]int64, error) {
+	if file == "" {
+		return tournament.ParseSeeds(list)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		parts = append(parts, strings.Fields(line)...)
+	}
+	return tournament.ParseSeeds(strings.Join(parts, ","))
+}
+
+// writeReport writes a report to a file, or to stdout for "-"
+func writeReport(path string, write func(io.Writer) error) error {
+	switch path {
+	case "":
+		return nil
+	case "-":
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
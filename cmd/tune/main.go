@@ -0,0 +1,98 @@
+// Command tune optimises the weights of the heuristic player with a genetic
+// algorithm over seeded games, and saves the best weights to a file that
+// cmd/bot -weights loads.
+//
+//	tune -seeds 1-2000 -generations 30 -o weights.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/tournament"
+	"github.com/tippi-fifestarr/scoundrel/tune"
+)
+
+func main() {
+	seedList := flag.String("seeds", "1-2000", "training seeds every candidate plays, e.g. 1-2000")
+	validateList := flag.String("validate", "100001-102000", "held-out seeds to compare the start and tuned weights on (empty to skip)")
+	startFile := flag.String("start", "", "weights file to start from (default: the built-in heuristic weights)")
+	output := flag.String("o", "weights.json", "file to save the tuned weights to, after every generation")
+	population := flag.Int("population", 24, "candidates per generation")
+	generations := flag.Int("generations", 30, "generations to breed")
+	elite := flag.Int("elite", 2, "best candidates kept unchanged in the next generation")
+	rate := flag.Float64("mutation-rate", 0.3, "chance of mutating each weight of a child")
+	scale := flag.Float64("mutation-scale", 0.1, "standard deviation of a mutation, as a share of the weight's range")
+	seed := flag.Int64("seed", 1, "seed of the search's random choices")
+	workers := flag.Int("workers", 0, "games played at once (default: one per CPU)")
+	flag.Parse()
+
+	seeds, err := tournament.ParseSeeds(*seedList)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var validation []int64
+	if *validateList != "" {
+		if validation, err = tournament.ParseSeeds(*validateList); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	start := bot.DefaultWeights
+	if *startFile != "" {
+		if start, err = bot.LoadWeights(*startFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Stop on Ctrl-C; the best weights so far are already saved
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Tuning %d weights: %d candidates for %d generations on %d seeds\n",
+		len(tune.Params), *population, *generations, len(seeds))
+	tuned, fitness, err := tune.Run(ctx, start, tune.Options{
+		Seeds:         seeds,
+		Population:    *population,
+		Generations:   *generations,
+		Elite:         *elite,
+		MutationRate:  *rate,
+		MutationScale: *scale,
+		Seed:          *seed,
+		Workers:       *workers,
+		Progress: func(g tune.Generation) {
+			fmt.Fprintf(os.Stderr, "Generation %d: best %.2f, mean %.2f\n", g.Number, g.BestFitness, g.MeanFitness)
+			if err := g.Best.Save(*output); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving weights: %s\n", err)
+			}
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	data, _ := json.MarshalIndent(tuned, "", "  ")
+	fmt.Println(string(data))
+	fmt.Fprintf(os.Stderr, "Saved to %s, mean score %.2f on the training seeds\n", *output, fitness)
+
+	if len(validation) > 0 {
+		before, err := tune.Fitness(ctx, start, validation, *workers)
+		if err == nil {
+			var after float64
+			after, err = tune.Fitness(ctx, tuned, validation, *workers)
+			fmt.Fprintf(os.Stderr, "On %d held-out seeds: start %.2f, tuned %.2f\n", len(validation), before, after)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error validating: %s\n", err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package dataset exports training data for supervised learning: one
+// sample per move, pairing the visible state with the action chosen and the
+// outcome and score the game finally had. Samples are built from replays,
+// so they can come from simulated games, replay files or the games played
+// on a server alike.
+package dataset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/gym"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+// Sources of samples
+const (
+	SourceSimulation = "simulation"
+	SourceReplay     = "replay"
+	SourceLive       = "live"
+)
+
+var (
+	// ErrTutorialReplay is returned for the replay of a tutorial game, whose
+	// hand-crafted dungeon and scripted moves are no use for learning
+	ErrTutorialReplay = errors.New("tutorial games are not exported")
+	// ErrUnfinishedReplay is returned for the replay of a game in progress,
+	// whose outcome is not known
+	ErrUnfinishedReplay = errors.New("game is not finished")
+)
+
+// Sample is one move of a game
+type Sample struct {
+	Source string `json:"source"`
+	// Player is the strategy or player ID that made the move, if known
+	Player string `json:"player,omitempty"`
+	GameID string `json:"game_id"`
+	Seed   int64  `json:"seed"`
+	Rules  string `json:"rules"`
+	// Step is the number of moves made before this one
+	Step int `json:"step"`
+	// StateHash identifies the visible state, for deduplication
+	StateHash string    `json:"state_hash"`
+	State     game.View `json:"state"`
+	// Observation is the state encoded as the gym environment does
+	Observation []float32   `json:"observation"`
+	Action      game.Action `json:"action"`
+	// ActionID is the action numbered as in the gym environment
+	ActionID int `json:"action_id"`
+	// Outcome is the final state of the game, Won or Lost
+	Outcome string `json:"outcome"`
+	Score   int    `json:"score"`
+}
+
+// FromReplay replays a finished game and returns one sample per move
+func FromReplay(rep game.Replay, source, player string) ([]Sample, error) {
+	if rep.Tutorial != "" {
+		return nil, ErrTutorialReplay
+	}
+	if rep.State != game.GameStateWon.String() && rep.State != game.GameStateLost.String() {
+		return nil, ErrUnfinishedReplay
+	}
+
+	env := gym.New(gym.Options{})
+	step := env.Reset(rep.Seed)
+	samples := make([]Sample, 0, len(rep.Actions))
+	for i, action := range rep.Actions {
+		view := env.View()
+		hash, err := StateHash(view)
+		if err != nil {
+			return nil, fmt.Errorf("move %d of game %s: %w", i+1, rep.GameID, err)
+		}
+		samples = append(samples, Sample{
+			Source:      source,
+			Player:      player,
+			GameID:      rep.GameID,
+			Seed:        rep.Seed,
+			Rules:       game.StandardRules,
+			Step:        i,
+			StateHash:   hash,
+			State:       view,
+			Observation: step.Observation,
+			Action:      action,
+			ActionID:    gym.Encode(action),
+		})
+
+		if step, err = env.Step(gym.Encode(action)); err != nil {
+			return nil, fmt.Errorf("replaying move %d (%s) of game %s: %w", i+1, action, rep.GameID, err)
+		}
+	}
+	if !step.Done {
+		return nil, ErrUnfinishedReplay
+	}
+
+	for i := range samples {
+		samples[i].Outcome = step.Info.State
+		samples[i].Score = step.Info.Score
+	}
+	return samples, nil
+}
+
+// Simulate plays a strategy on a seed and returns the replay of the game
+func Simulate(s bot.Strategy, seed int64) (game.Replay, error) {
+	session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed, Practice: true})
+	if err := bot.Play(session, s); err != nil {
+		return game.Replay{}, err
+	}
+	return session.Replay(), nil
+}
+
+// StateHash returns a short hash of everything a player sees: health, the
+// weapon and the last monster it defeated, the cards left, the room and the
+// skip and potion flags. Games reaching the same visible state by
+// different routes share the hash.
+func StateHash(view game.View) (string, error) {
+	weapon, last, room, err := protocol.FormatVisible(view, "none")
+	if err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("%d/%d %s %s %d [%s] %t %t",
+		view.Player.Health, view.Player.MaxHealth, weapon, last, view.Deck.RemainingCards,
+		strings.Join(room, " "), bot.CanSkip(view), view.Player.UsedPotion)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// Options selects the samples an Exporter writes
+type Options struct {
+	// Outcome keeps only the moves of games with this outcome, Won or Lost
+	// (case-insensitive); empty keeps all
+	Outcome string
+	// Rules keeps only games played under this rule set; empty keeps all
+	Rules string
+	// Dedup writes only the first sample of each visible state
+	Dedup bool
+}
+
+// Exporter filters samples and writes them
+type Exporter struct {
+	w    Writer
+	opts Options
+	seen map[string]bool
+
+	// Games counts the games whose samples were written, and Samples the
+	// samples. Duplicates counts the samples skipped as duplicates.
+	Games, Samples, Duplicates int
+}
+
+// NewExporter returns an exporter writing to w
+func NewExporter(w Writer, opts Options) *Exporter {
+	return &Exporter{w: w, opts: opts, seen: make(map[string]bool)}
+}
+
+// AddReplay writes the samples of a replay that passes the filters.
+// Tutorial and unfinished games are skipped without an error.
+func (e *Exporter) AddReplay(rep game.Replay, source, player string) error {
+	samples, err := FromReplay(rep, source, player)
+	if errors.Is(err, ErrTutorialReplay) || errors.Is(err, ErrUnfinishedReplay) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	if e.opts.Outcome != "" && !strings.EqualFold(samples[0].Outcome, e.opts.Outcome) {
+		return nil
+	}
+	if e.opts.Rules != "" && samples[0].Rules != e.opts.Rules {
+		return nil
+	}
+
+	e.Games++
+	for _, sample := range samples {
+		if e.opts.Dedup {
+			if e.seen[sample.StateHash] {
+				e.Duplicates++
+				continue
+			}
+			e.seen[sample.StateHash] = true
+		}
+		if err := e.w.Write(sample); err != nil {
+			return err
+		}
+		e.Samples++
+	}
+	return nil
+}
+
+// Flush writes out buffered samples
+func (e *Exporter) Flush() error {
+	return e.w.Flush()
+}
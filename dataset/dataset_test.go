@@ -0,0 +1,155 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/gym"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+func TestFromReplay(t *testing.T) {
+	rep, err := Simulate(bot.NewGreedy(), 3)
+	if err != nil {
+		t.Fatalf("Error simulating: %v", err)
+	}
+
+	samples, err := FromReplay(rep, SourceSimulation, "greedy")
+	if err != nil {
+		t.Fatalf("Error building samples: %v", err)
+	}
+	if len(samples) != len(rep.Actions) {
+		t.Fatalf("Expected %d samples, got %d", len(rep.Actions), len(samples))
+	}
+
+	for i, sample := range samples {
+		// Each sample holds the state the move was made in
+		session, err := rep.StateAt(i)
+		if err != nil {
+			t.Fatalf("Error rebuilding step %d: %v", i, err)
+		}
+		hash, err := StateHash(session.View())
+		if err != nil {
+			t.Fatalf("Error hashing step %d: %v", i, err)
+		}
+		if sample.StateHash != hash {
+			t.Errorf("Step %d: expected the state before the move", i)
+		}
+		if sample.Action != rep.Actions[i] || sample.ActionID != gym.Encode(rep.Actions[i]) {
+			t.Errorf("Step %d: expected action %v, got %v (%d)", i, rep.Actions[i], sample.Action, sample.ActionID)
+		}
+		if sample.Outcome != rep.State || sample.Score != rep.Score || sample.Rules != game.StandardRules {
+			t.Errorf("Step %d: expected outcome %s with %d, got %+v", i, rep.State, rep.Score, sample)
+		}
+		if len(sample.Observation) != gym.ObservationSize {
+			t.Errorf("Step %d: expected %d observations, got %d", i, gym.ObservationSize, len(sample.Observation))
+		}
+	}
+
+	tutorial := game.NewGameSessionWithOptions(game.SessionOptions{Tutorial: "scoring"})
+	if _, err := FromReplay(tutorial.Replay(), SourceReplay, ""); !errors.Is(err, ErrTutorialReplay) {
+		t.Errorf("Expected ErrTutorialReplay, got %v", err)
+	}
+	unfinished := game.NewGameSessionWithOptions(game.SessionOptions{Seed: 3})
+	if _, err := FromReplay(unfinished.Replay(), SourceReplay, ""); !errors.Is(err, ErrUnfinishedReplay) {
+		t.Errorf("Expected ErrUnfinishedReplay, got %v", err)
+	}
+}
+
+func TestExporterFiltersAndDedups(t *testing.T) {
+	var replays []game.Replay
+	for seed := int64(1); seed <= 30; seed++ {
+		rep, err := Simulate(bot.NewGreedy(), seed)
+		if err != nil {
+			t.Fatalf("Error simulating: %v", err)
+		}
+		replays = append(replays, rep)
+	}
+	won := 0
+	for _, rep := range replays {
+		if rep.State == game.GameStateWon.String() {
+			won++
+		}
+	}
+
+	var out bytes.Buffer
+	e := NewExporter(NewJSONLWriter(&out), Options{Outcome: "won", Rules: game.StandardRules})
+	for _, rep := range replays {
+		if err := e.AddReplay(rep, SourceSimulation, "greedy"); err != nil {
+			t.Fatalf("Error exporting: %v", err)
+		}
+	}
+	e.Flush()
+	if e.Games != won {
+		t.Errorf("Expected %d won games, got %d", won, e.Games)
+	}
+	scanner := bufio.NewScanner(&out)
+	scanner.Buffer(nil, 1<<20)
+	lines := 0
+	for scanner.Scan() {
+		var sample Sample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			t.Fatalf("Error decoding sample: %v", err)
+		}
+		if sample.Outcome != game.GameStateWon.String() {
+			t.Errorf("Expected only won games, got %s", sample.Outcome)
+		}
+		lines++
+	}
+	if lines != e.Samples {
+		t.Errorf("Expected %d lines, got %d", e.Samples, lines)
+	}
+
+	// The same game twice is all duplicates the second time
+	e = NewExporter(NewJSONLWriter(&bytes.Buffer{}), Options{Dedup: true, Rules: "variant"})
+	e.AddReplay(replays[0], SourceSimulation, "")
+	if e.Games != 0 {
+		t.Errorf("Expected no games under another rule set, got %d", e.Games)
+	}
+	e = NewExporter(NewJSONLWriter(&bytes.Buffer{}), Options{Dedup: true})
+	e.AddReplay(replays[0], SourceSimulation, "")
+	first := e.Samples
+	e.AddReplay(replays[0], SourceSimulation, "")
+	if e.Samples != first || e.Duplicates < first {
+		t.Errorf("Expected the repeated game to be skipped as duplicates, got %d samples and %d duplicates", e.Samples, e.Duplicates)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	rep, _ := Simulate(bot.NewGreedy(), 3)
+	samples, _ := FromReplay(rep, SourceSimulation, "greedy")
+
+	var out bytes.Buffer
+	w, err := NewWriter(&out, "csv")
+	if err != nil {
+		t.Fatalf("Error creating writer: %v", err)
+	}
+	for _, sample := range samples {
+		w.Write(sample)
+	}
+	w.Flush()
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("Error reading CSV: %v", err)
+	}
+	if len(rows) != len(samples)+1 {
+		t.Fatalf("Expected a header and %d rows, got %d rows", len(samples), len(rows))
+	}
+	if len(rows[0]) != len(csvColumns)+gym.ObservationSize || rows[0][0] != "source" {
+		t.Errorf("Unexpected header %v", rows[0])
+	}
+	if rows[1][7] != "20" || rows[1][15] != protocol.FormatMove(samples[0].Action) {
+		t.Errorf("Unexpected first row %v", rows[1])
+	}
+
+	if _, err := NewWriter(&out, "xml"); err == nil {
+		t.Errorf("Expected error for an unknown format")
+	}
+}
@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/gym"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+// Writer writes samples in a file format
+type Writer interface {
+	Write(s Sample) error
+	// Flush writes out buffered samples
+	Flush() error
+}
+
+// NewWriter returns a writer for a format: jsonl or csv
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case "jsonl":
+		return NewJSONLWriter(w), nil
+	case "csv":
+		return NewCSVWriter(w), nil
+	}
+	return nil, fmt.Errorf("unknown format %q (want jsonl or csv)", format)
+}
+
+// jsonlWriter writes a sample per line as JSON
+type jsonlWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSONLWriter returns a writer of JSON Lines, one sample per line
+func NewJSONLWriter(w io.Writer) Writer {
+	buf := bufio.NewWriter(w)
+	return &jsonlWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+// Write writes a sample as a line of JSON
+func (w *jsonlWriter) Write(s Sample) error {
+	return w.enc.Encode(s)
+}
+
+// Flush writes out buffered samples
+func (w *jsonlWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+// csvWriter writes a sample per row, with the state flattened to columns
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVWriter returns a writer of CSV with a header row. The state is
+// flattened to columns, with cards in engine protocol notation, followed
+// by the observation as columns obs_0 to obs_30.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+// csvColumns are the columns before the observation
+var csvColumns = []string{
+	"source", "player", "game_id", "seed", "rules", "step", "state_hash",
+	"health", "max_health", "weapon", "last_defeated", "deck", "room", "can_skip", "potion_used",
+	"action", "action_id", "outcome", "score",
+}
+
+// Write writes a sample as a row, after the header for the first one
+func (w *csvWriter) Write(s Sample) error {
+	if !w.header {
+		header := append([]string(nil), csvColumns...)
+		for i := 0; i < gym.ObservationSize; i++ {
+			header = append(header, fmt.Sprintf("obs_%d", i))
+		}
+		if err := w.w.Write(header); err != nil {
+			return err
+		}
+		w.header = true
+	}
+
+	player := s.State.Player
+	weapon, last, room, err := protocol.FormatVisible(s.State, "")
+	if err != nil {
+		return err
+	}
+
+	row := []string{
+		s.Source, s.Player, s.GameID, strconv.FormatInt(s.Seed, 10), s.Rules, strconv.Itoa(s.Step), s.StateHash,
+		strconv.Itoa(player.Health), strconv.Itoa(player.MaxHealth), weapon, last,
+		strconv.Itoa(s.State.Deck.RemainingCards), strings.Join(room, " "),
+		strconv.FormatBool(bot.CanSkip(s.State)), strconv.FormatBool(player.UsedPotion),
+		protocol.FormatMove(s.Action), strconv.Itoa(s.ActionID), s.Outcome, strconv.Itoa(s.Score),
+	}
+	for _, x := range s.Observation {
+		row = append(row, strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	return w.w.Write(row)
+}
+
+// Flush writes out buffered samples
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
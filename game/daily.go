@@ -0,0 +1,26 @@
+package game
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// DailyDate returns the UTC calendar date of the daily challenge running at t, as YYYY-MM-DD
+func DailyDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// DailySeed derives the seed of the daily challenge running at t. Every
+// client computes the same seed for the same UTC day, so the server and the
+// offline CLI deal the same dungeon.
+func DailySeed(t time.Time) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scoundrel-daily-" + DailyDate(t)))
+
+	// Keep the seed positive and non-zero, since zero means "pick a random seed"
+	seed := int64(h.Sum64() & 0x7fffffffffffffff)
+	if seed == 0 {
+		seed = 1
+	}
+	return seed
+}
@@ -0,0 +1,31 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailySeed(t *testing.T) {
+	morning := time.Date(2026, 3, 14, 0, 5, 0, 0, time.UTC)
+	evening := time.Date(2026, 3, 14, 23, 55, 0, 0, time.UTC)
+	nextDay := time.Date(2026, 3, 15, 0, 5, 0, 0, time.UTC)
+
+	if DailySeed(morning) != DailySeed(evening) {
+		t.Errorf("Expected the same seed throughout a UTC day")
+	}
+	if DailySeed(morning) == DailySeed(nextDay) {
+		t.Errorf("Expected a different seed on the next day")
+	}
+
+	// The date is taken in UTC regardless of the caller's time zone
+	tokyo := time.FixedZone("JST", 9*60*60)
+	if DailySeed(time.Date(2026, 3, 15, 8, 0, 0, 0, tokyo)) != DailySeed(evening) {
+		t.Errorf("Expected the daily seed to follow the UTC date")
+	}
+	if DailyDate(morning) != "2026-03-14" {
+		t.Errorf("Expected daily date 2026-03-14, got %s", DailyDate(morning))
+	}
+	if DailySeed(morning) <= 0 {
+		t.Errorf("Expected a positive daily seed, got %d", DailySeed(morning))
+	}
+}
@@ -1,7 +1,10 @@
 package game
 
 import (
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -16,11 +19,19 @@ const (
 	GameStateLost
 )
 
+var gameStateNames = [...]string{"Initial", "InProgress", "Won", "Lost"}
+
 // String returns a string representation of the game state
 func (gs GameState) String() string {
-	return [...]string{"Initial", "InProgress", "Won", "Lost"}[gs]
+	if gs < 0 || int(gs) >= len(gameStateNames) {
+		return fmt.Sprintf("GameState(%d)", int(gs))
+	}
+	return gameStateNames[gs]
 }
 
+// StandardRules names the official rule set, currently the only one supported
+const StandardRules = "standard"
+
 // GameSession represents an active game session
 type GameSession struct {
 	ID             string
@@ -30,21 +41,81 @@ type GameSession struct {
 	playHistory    []*Card
 	state          GameState
 	lastCardPlayed *Card
+	public         bool
+	spectatorToken string
+	ownerID        string
+	seed           int64
+	practice       bool
+	challenge      string
+	actions        []Action
+	roomsCleared   int
+	lesson         *Lesson
+	lessonStep     int
+	startedAt      time.Time
+	finishedAt     time.Time
+	lastActiveAt   time.Time
+}
+
+// SessionOptions configures a new game session
+type SessionOptions struct {
+	// Public games are listed as live games and can be watched without a
+	// spectator token
+	Public bool
+	// OwnerID is the player allowed to make moves. Games without an owner
+	// can be played by anyone holding the game ID.
+	OwnerID string
+	// Seed determines the deal. Zero picks a random seed, which ranked
+	// games must use; only practice and daily games choose their seed.
+	Seed int64
+	// Practice games are not eligible for leaderboards
+	Practice bool
+	// Challenge is the date (YYYY-MM-DD) of the daily challenge the game
+	// belongs to, or "" for a regular game
+	Challenge string
+	// Tutorial names the lesson whose hand-crafted dungeon is dealt instead
+	// of a shuffled deck. Tutorial games are always practice games, and
+	// only accept the moves their lesson asks for.
+	Tutorial string
 }
 
 // NewGameSession creates a new game session
 func NewGameSession() *GameSession {
+	return NewGameSessionWithOptions(SessionOptions{})
+}
+
+// NewGameSessionWithOptions creates a new game session with the given options
+func NewGameSessionWithOptions(opts SessionOptions) *GameSession {
 	id := uuid.New().String()
 	player := NewPlayer(20) // Start with 20 health
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = randomSeed()
+	}
 	deck := NewDeck()
-	deck.Shuffle()
+	deck.ShuffleWithSeed(seed)
+
+	// Tutorial games deal their lesson's dungeon
+	lesson, isTutorial := LessonByName(opts.Tutorial)
+	if isTutorial {
+		deck = lesson.newDeck()
+	}
 
 	session := &GameSession{
-		ID:          id,
-		player:      player,
-		deck:        deck,
-		playHistory: make([]*Card, 0),
-		state:       GameStateInitial,
+		ID:             id,
+		player:         player,
+		deck:           deck,
+		playHistory:    make([]*Card, 0),
+		state:          GameStateInitial,
+		public:         opts.Public,
+		spectatorToken: uuid.New().String(),
+		ownerID:        opts.OwnerID,
+		seed:           seed,
+		practice:       opts.Practice || isTutorial,
+		challenge:      opts.Challenge,
+		lesson:         lesson,
+		startedAt:      time.Now(),
+		lastActiveAt:   time.Now(),
 	}
 
 	// Create initial room
@@ -58,6 +129,77 @@ func (g *GameSession) GetID() string {
 	return g.ID
 }
 
+// IsPublic returns whether the game can be watched without a spectator token
+func (g *GameSession) IsPublic() bool {
+	return g.public
+}
+
+// GetSpectatorToken returns the token that grants read-only access to the game
+func (g *GameSession) GetSpectatorToken() string {
+	return g.spectatorToken
+}
+
+// CanSpectate returns whether the given token grants read-only access to the game
+func (g *GameSession) CanSpectate(token string) bool {
+	if g.public {
+		return true
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(g.spectatorToken)) == 1
+}
+
+// GetOwnerID returns the ID of the player who owns the game, or "" for anonymous games
+func (g *GameSession) GetOwnerID() string {
+	return g.ownerID
+}
+
+// CanPlay returns whether the given player may make moves in the game
+func (g *GameSession) CanPlay(playerID string) bool {
+	return g.ownerID == "" || g.ownerID == playerID
+}
+
+// GetSeed returns the seed the deck was shuffled with
+func (g *GameSession) GetSeed() int64 {
+	return g.seed
+}
+
+// IsPractice returns whether the game is a practice game, which is not eligible for leaderboards
+func (g *GameSession) IsPractice() bool {
+	return g.practice
+}
+
+// GetChallenge returns the date of the daily challenge the game belongs to, or "" for a regular game
+func (g *GameSession) GetChallenge() string {
+	return g.challenge
+}
+
+// RoomsCleared returns the number of rooms in which three cards were played
+func (g *GameSession) RoomsCleared() int {
+	return g.roomsCleared
+}
+
+// StartedAt returns when the game was created
+func (g *GameSession) StartedAt() time.Time {
+	return g.startedAt
+}
+
+// FinishedAt returns when the game ended, or the zero time if it is not over
+func (g *GameSession) FinishedAt() time.Time {
+	return g.finishedAt
+}
+
+// LastActiveAt returns when the last move was made through the session manager, or the creation time
+func (g *GameSession) LastActiveAt() time.Time {
+	return g.lastActiveAt
+}
+
+// Duration returns how long the game took, or how long it has been running if it is not over
+func (g *GameSession) Duration() time.Duration {
+	if g.finishedAt.IsZero() {
+		return time.Since(g.startedAt)
+	}
+	return g.finishedAt.Sub(g.startedAt)
+}
+
 // GetPlayer returns the player in this session
 func (g *GameSession) GetPlayer() *Player {
 	return g.player
@@ -97,7 +239,7 @@ func (g *GameSession) CreateRoom() error {
 		if err != nil {
 			// No more cards to draw, but we have a remaining card
 			// This means the player has won by exhausting the deck
-			g.state = GameStateWon
+			g.finish(GameStateWon)
 			return nil
 		}
 
@@ -107,7 +249,7 @@ func (g *GameSession) CreateRoom() error {
 		cards, err = g.deck.Draw(4)
 		if err != nil {
 			// Can't draw enough cards, game is won
-			g.state = GameStateWon
+			g.finish(GameStateWon)
 			return nil
 		}
 	}
@@ -153,6 +295,8 @@ func (g *GameSession) PlayCard(index int) error {
 
 	// Check if room is completed (3 cards played)
 	if g.currentRoom.Completed() {
+		g.roomsCleared++
+
 		// Set up next room
 		err = g.CreateRoom()
 		if err != nil {
@@ -162,9 +306,11 @@ func (g *GameSession) PlayCard(index int) error {
 
 	// Check if player is dead
 	if g.player.Health() <= 0 {
-		g.state = GameStateLost
+		g.finish(GameStateLost)
 	}
 
+	g.actions = append(g.actions, Action{Type: ActionPlay, Index: index})
+
 	return nil
 }
 
@@ -202,6 +348,8 @@ func (g *GameSession) PlayCardWithoutWeapon(index int) error {
 
 	// Check if room is completed (3 cards played)
 	if g.currentRoom.Completed() {
+		g.roomsCleared++
+
 		// Set up next room
 		err = g.CreateRoom()
 		if err != nil {
@@ -211,9 +359,11 @@ func (g *GameSession) PlayCardWithoutWeapon(index int) error {
 
 	// Check if player is dead
 	if g.player.Health() <= 0 {
-		g.state = GameStateLost
+		g.finish(GameStateLost)
 	}
 
+	g.actions = append(g.actions, Action{Type: ActionPlayBarehanded, Index: index})
+
 	return nil
 }
 
@@ -237,7 +387,59 @@ func (g *GameSession) SkipRoom() error {
 	g.deck.SetPrevRoomSkipped(true)
 
 	// Create a new room
-	return g.CreateRoom()
+	if err := g.CreateRoom(); err != nil {
+		return err
+	}
+
+	g.actions = append(g.actions, Action{Type: ActionSkip})
+
+	return nil
+}
+
+// Resign gives up a game in progress, which then counts as lost and scores
+// the monsters left in the dungeon. Resigning is not a move, so a replay of
+// the game stops just before it.
+func (g *GameSession) Resign() error {
+	if g.state != GameStateInProgress {
+		return errors.New("game is not in progress")
+	}
+	g.finish(GameStateLost)
+	return nil
+}
+
+// finish ends the game in the given state
+func (g *GameSession) finish(state GameState) {
+	g.state = state
+	g.finishedAt = time.Now()
+}
+
+// Score returns the official score of the game. A lost game scores the
+// negative sum of the monsters left in the dungeon; a won game scores the
+// remaining health, plus the value of the last card if it was a potion
+// played at full health.
+func (g *GameSession) Score() int {
+	if g.state == GameStateLost {
+		score := 0
+		for _, card := range g.deck.cards {
+			if card.Type() == Monster {
+				score -= card.Value()
+			}
+		}
+		if g.currentRoom != nil {
+			for _, card := range g.currentRoom.Cards() {
+				if card.Type() == Monster {
+					score -= card.Value()
+				}
+			}
+		}
+		return score
+	}
+
+	score := g.player.Health()
+	if score == g.player.MaxHealth() && g.lastCardPlayed != nil && g.lastCardPlayed.Type() == Potion {
+		score += g.lastCardPlayed.Value()
+	}
+	return score
 }
 
 // IsGameOver returns true if the game is over (won or lost)
@@ -330,6 +532,7 @@ func (g *GameSession) GetGameState() map[string]interface{} {
 			"suit":    int(weapon.Suit),
 			"rank":    int(weapon.Rank),
 			"value":   weapon.Value(),
+			"type":    int(weapon.Type()),
 			"display": weapon.String(),
 		}
 	}
@@ -341,12 +544,13 @@ func (g *GameSession) GetGameState() map[string]interface{} {
 			"suit":    int(monster.Suit),
 			"rank":    int(monster.Rank),
 			"value":   monster.Value(),
+			"type":    int(monster.Type()),
 			"display": monster.String(),
 		})
 	}
 
 	// Build and return complete game state
-	return map[string]interface{}{
+	state := map[string]interface{}{
 		"game_id": g.ID,
 		"state":   g.state.String(),
 		"player": map[string]interface{}{
@@ -365,4 +569,11 @@ func (g *GameSession) GetGameState() map[string]interface{} {
 			"previous_room_skipped": g.deck.PrevRoomSkipped(),
 		},
 	}
+
+	// Add lesson progress for tutorial games
+	if tutorial := g.tutorialView(); tutorial != nil {
+		state["tutorial"] = tutorial
+	}
+
+	return state
 }
@@ -190,3 +190,22 @@ func TestRoomProgression(t *testing.T) {
 			len(session.currentRoom.Cards()))
 	}
 }
+
+func TestResign(t *testing.T) {
+	session := NewGameSessionWithOptions(SessionOptions{Tutorial: "scoring"})
+
+	if err := session.Resign(); err != nil {
+		t.Fatalf("Error resigning: %v", err)
+	}
+	if session.GetState() != GameStateLost {
+		t.Errorf("Expected resigned game to be Lost, got %v", session.GetState())
+	}
+	// Only the 9♠ is left
+	if session.Score() != -9 {
+		t.Errorf("Expected score -9, got %d", session.Score())
+	}
+
+	if err := session.Resign(); err == nil {
+		t.Errorf("Expected error resigning a finished game")
+	}
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrGameOver is returned when ending a game that is already over
+var ErrGameOver = errors.New("game is already over")
+
+// SessionSummary is a one-line description of a session for operators
+type SessionSummary struct {
+	GameID         string    `json:"game_id"`
+	State          string    `json:"state"`
+	OwnerID        string    `json:"owner_id,omitempty"`
+	Public         bool      `json:"public"`
+	Practice       bool      `json:"practice"`
+	Challenge      string    `json:"challenge,omitempty"`
+	Health         int       `json:"health"`
+	RemainingCards int       `json:"remaining_cards"`
+	Moves          int       `json:"moves"`
+	StartedAt      time.Time `json:"started_at"`
+	LastActiveAt   time.Time `json:"last_active_at"`
+}
+
+// Inspection is the full internal state of a game, including the order of
+// the dungeon deck. It reveals the hidden cards, so it must never be shown
+// to players or spectators.
+type Inspection struct {
+	View
+	OwnerID        string     `json:"owner_id,omitempty"`
+	Public         bool       `json:"public"`
+	SpectatorToken string     `json:"spectator_token"`
+	Seed           int64      `json:"seed"`
+	Practice       bool       `json:"practice"`
+	Challenge      string     `json:"challenge,omitempty"`
+	Score          int        `json:"score"`
+	RoomsCleared   int        `json:"rooms_cleared"`
+	DeckOrder      []CardView `json:"deck_order"`
+	PlayHistory    []CardView `json:"play_history"`
+	LastCardPlayed *CardView  `json:"last_card_played"`
+	Actions        []Action   `json:"actions"`
+	StartedAt      time.Time  `json:"started_at"`
+	LastActiveAt   time.Time  `json:"last_active_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// SessionFilter selects sessions by state, owner and age. Zero fields match everything.
+type SessionFilter struct {
+	// State is a game state name such as "InProgress"
+	State   string
+	OwnerID string
+	// MinAge and MaxAge bound the time since the game started
+	MinAge time.Duration
+	MaxAge time.Duration
+	// Now is the reference time for ages; zero means time.Now
+	Now time.Time
+}
+
+// Summary returns a one-line description of the session
+func (g *GameSession) Summary() SessionSummary {
+	return SessionSummary{
+		GameID:         g.ID,
+		State:          g.state.String(),
+		OwnerID:        g.ownerID,
+		Public:         g.public,
+		Practice:       g.practice,
+		Challenge:      g.challenge,
+		Health:         g.player.Health(),
+		RemainingCards: g.deck.Remaining(),
+		Moves:          len(g.actions),
+		StartedAt:      g.startedAt,
+		LastActiveAt:   g.lastActiveAt,
+	}
+}
+
+// Inspect returns the full internal state of the session
+func (g *GameSession) Inspect() Inspection {
+	deckOrder := make([]CardView, 0, len(g.deck.cards))
+	for _, card := range g.deck.cards {
+		deckOrder = append(deckOrder, NewCardView(card))
+	}
+
+	playHistory := make([]CardView, 0, len(g.playHistory))
+	for _, card := range g.playHistory {
+		playHistory = append(playHistory, NewCardView(card))
+	}
+
+	var lastCardPlayed *CardView
+	if g.lastCardPlayed != nil {
+		cv := NewCardView(g.lastCardPlayed)
+		lastCardPlayed = &cv
+	}
+
+	var finishedAt *time.Time
+	if !g.finishedAt.IsZero() {
+		t := g.finishedAt
+		finishedAt = &t
+	}
+
+	return Inspection{
+		View:           g.View(),
+		OwnerID:        g.ownerID,
+		Public:         g.public,
+		SpectatorToken: g.spectatorToken,
+		Seed:           g.seed,
+		Practice:       g.practice,
+		Challenge:      g.challenge,
+		Score:          g.Score(),
+		RoomsCleared:   g.roomsCleared,
+		DeckOrder:      deckOrder,
+		PlayHistory:    playHistory,
+		LastCardPlayed: lastCardPlayed,
+		Actions:        g.Actions(),
+		StartedAt:      g.startedAt,
+		LastActiveAt:   g.lastActiveAt,
+		FinishedAt:     finishedAt,
+	}
+}
+
+// ListSessions returns a page of the sessions matching filter, newest first,
+// along with the total number of matches
+func (sm *SessionManager) ListSessions(filter SessionFilter, offset, limit int) ([]SessionSummary, int) {
+	now := filter.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	sm.mutex.RLock()
+	matches := make([]SessionSummary, 0)
+	for _, session := range sm.sessions {
+		age := now.Sub(session.startedAt)
+		switch {
+		case filter.State != "" && session.state.String() != filter.State,
+			filter.OwnerID != "" && session.ownerID != filter.OwnerID,
+			filter.MinAge > 0 && age < filter.MinAge,
+			filter.MaxAge > 0 && age > filter.MaxAge:
+			continue
+		}
+		matches = append(matches, session.Summary())
+	}
+	sm.mutex.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].StartedAt.Equal(matches[j].StartedAt) {
+			return matches[i].StartedAt.After(matches[j].StartedAt)
+		}
+		return matches[i].GameID < matches[j].GameID
+	})
+
+	total := len(matches)
+	if offset > total {
+		offset = total
+	}
+	matches = matches[offset:]
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, total
+}
+
+// Inspect returns the full internal state of a session
+func (sm *SessionManager) Inspect(id string) (Inspection, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	session, exists := sm.sessions[id]
+	if !exists {
+		return Inspection{}, ErrSessionNotFound
+	}
+
+	return session.Inspect(), nil
+}
+
+// EndSession forces a game in progress to end as lost. Listeners are not
+// notified, so a forced end is never ranked or counted as a finished game.
+func (sm *SessionManager) EndSession(id string) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	session, exists := sm.sessions[id]
+	if !exists {
+		return ErrSessionNotFound
+	}
+	if session.IsGameOver() {
+		return ErrGameOver
+	}
+
+	session.finish(GameStateLost)
+	sm.notifyWatchers(id)
+
+	return nil
+}
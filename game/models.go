@@ -1,9 +1,11 @@
 package game
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"math/rand"
-	"time"
 )
 
 // Suit represents the card suit
@@ -16,9 +18,14 @@ const (
 	Spades
 )
 
+var suitSymbols = [...]string{"♣", "♦", "♥", "♠"}
+
 // String returns the string representation of a suit
 func (s Suit) String() string {
-	return [...]string{"♣", "♦", "♥", "♠"}[s]
+	if s < 0 || int(s) >= len(suitSymbols) {
+		return fmt.Sprintf("Suit(%d)", int(s))
+	}
+	return suitSymbols[s]
 }
 
 // Rank represents the card rank
@@ -40,9 +47,14 @@ const (
 	Ace
 )
 
+var rankNames = [...]string{"", "", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K", "A"}
+
 // String returns the string representation of a rank
 func (r Rank) String() string {
-	return [...]string{"", "", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K", "A"}[r]
+	if r < Two || r > Ace {
+		return fmt.Sprintf("Rank(%d)", int(r))
+	}
+	return rankNames[r]
 }
 
 // CardType represents the functional type of a card
@@ -262,9 +274,35 @@ func NewDeck() *Deck {
 	return d
 }
 
+// NewDeckFromCards creates a deck holding exactly the given cards, the first
+// card on top. It is used for hand-crafted dungeons such as tutorial lessons.
+func NewDeckFromCards(cards []*Card) *Deck {
+	return &Deck{
+		cards: append([]*Card(nil), cards...),
+	}
+}
+
 // Shuffle randomizes the order of cards in the deck
 func (d *Deck) Shuffle() {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	d.ShuffleWithSeed(randomSeed())
+}
+
+// randomSeed returns a nonzero seed that cannot be guessed, unlike the
+// clock, so a player who knows when a game started cannot work out its deal
+func randomSeed() int64 {
+	var b [8]byte
+	for {
+		cryptorand.Read(b[:])
+		if seed := int64(binary.LittleEndian.Uint64(b[:])); seed != 0 {
+			return seed
+		}
+	}
+}
+
+// ShuffleWithSeed orders the cards in the deck deterministically from a seed,
+// so the same seed always deals the same dungeon
+func (d *Deck) ShuffleWithSeed(seed int64) {
+	r := rand.New(rand.NewSource(seed))
 	r.Shuffle(len(d.cards), func(i, j int) {
 		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
 	})
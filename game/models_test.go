@@ -78,3 +78,62 @@ func TestInitialGameState(t *testing.T) {
 		t.Errorf("Expected 4 cards in initial room, got %d", len(session.GetCurrentRoom().Cards()))
 	}
 }
+
+func TestSeededShuffle(t *testing.T) {
+	a := NewDeck()
+	b := NewDeck()
+	a.ShuffleWithSeed(42)
+	b.ShuffleWithSeed(42)
+
+	for i := range a.cards {
+		if *a.cards[i] != *b.cards[i] {
+			t.Fatalf("Expected the same seed to deal the same deck, differs at card %d: %s vs %s",
+				i, a.cards[i], b.cards[i])
+		}
+	}
+
+	// Sessions with the same seed start in the same room
+	s1 := NewGameSessionWithOptions(SessionOptions{Seed: 7})
+	s2 := NewGameSessionWithOptions(SessionOptions{Seed: 7})
+	if s1.GetSeed() != 7 || s2.GetSeed() != 7 {
+		t.Errorf("Expected sessions to keep seed 7, got %d and %d", s1.GetSeed(), s2.GetSeed())
+	}
+	for i, card := range s1.GetCurrentRoom().Cards() {
+		if *card != *s2.GetCurrentRoom().Cards()[i] {
+			t.Errorf("Expected identical first rooms for the same seed")
+		}
+	}
+}
+
+func TestUnseededGamesDrawRandomSeeds(t *testing.T) {
+	// Games started at the same moment must not share a deal
+	seeds := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		seed := NewGameSession().GetSeed()
+		if seed == 0 || seeds[seed] {
+			t.Fatalf("Expected a fresh nonzero seed, got %d", seed)
+		}
+		seeds[seed] = true
+	}
+}
+
+func TestStringOutOfRange(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{Suit(7).String(), "Suit(7)"},
+		{Suit(-1).String(), "Suit(-1)"},
+		{Rank(1).String(), "Rank(1)"},
+		{Rank(15).String(), "Rank(15)"},
+		{GameState(9).String(), "GameState(9)"},
+		{Spades.String(), "♠"},
+		{Ace.String(), "A"},
+		{GameStateLost.String(), "Lost"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, tt.got)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ActionType identifies a kind of move
+type ActionType int
+
+const (
+	// ActionPlay plays a card, using the equipped weapon against a monster when allowed
+	ActionPlay ActionType = iota
+	// ActionPlayBarehanded plays a card, fighting a monster without the weapon
+	ActionPlayBarehanded
+	// ActionSkip skips the current room
+	ActionSkip
+)
+
+var actionTypeNames = [...]string{"play", "play-barehanded", "skip"}
+
+// String returns the string representation of an action type
+func (t ActionType) String() string {
+	if t < 0 || int(t) >= len(actionTypeNames) {
+		return fmt.Sprintf("ActionType(%d)", int(t))
+	}
+	return actionTypeNames[t]
+}
+
+// MarshalText encodes an action type by name
+func (t ActionType) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(actionTypeNames) {
+		return nil, fmt.Errorf("unknown action type %d", int(t))
+	}
+	return []byte(actionTypeNames[t]), nil
+}
+
+// UnmarshalText decodes an action type from its name
+func (t *ActionType) UnmarshalText(text []byte) error {
+	for i, name := range actionTypeNames {
+		if string(text) == name {
+			*t = ActionType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action type %q", text)
+}
+
+// Action is a single move made by the player
+type Action struct {
+	Type ActionType `json:"type"`
+	// Index is the room card played; unused for skips
+	Index int `json:"index"`
+}
+
+// String returns the string representation of an action
+func (a Action) String() string {
+	if a.Type == ActionSkip {
+		return a.Type.String()
+	}
+	return fmt.Sprintf("%s %d", a.Type, a.Index)
+}
+
+// Apply performs an action on the game. In a tutorial game, only the moves
+// the current step of the lesson asks for are allowed.
+func (g *GameSession) Apply(a Action) error {
+	if g.lesson != nil {
+		if err := g.lesson.check(g.lessonStep, a); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch a.Type {
+	case ActionPlay:
+		err = g.PlayCard(a.Index)
+	case ActionPlayBarehanded:
+		err = g.PlayCardWithoutWeapon(a.Index)
+	case ActionSkip:
+		err = g.SkipRoom()
+	default:
+		err = fmt.Errorf("unknown action type %d", int(a.Type))
+	}
+
+	if err == nil && g.lesson != nil {
+		g.lessonStep++
+	}
+	return err
+}
+
+// Actions returns the moves made so far, in order
+func (g *GameSession) Actions() []Action {
+	return append([]Action(nil), g.actions...)
+}
+
+// Replay is the record of a game: its seed and every move made, from which
+// any intermediate state can be rebuilt
+type Replay struct {
+	GameID     string    `json:"game_id"`
+	OwnerID    string    `json:"owner_id,omitempty"`
+	Seed       int64     `json:"seed"`
+	Actions    []Action  `json:"actions"`
+	State      string    `json:"state"`
+	Score      int       `json:"score"`
+	Challenge  string    `json:"challenge,omitempty"`
+	Tutorial   string    `json:"tutorial,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	// Public and SpectatorToken decide who may watch the game, as they do
+	// while it is played
+	Public         bool   `json:"public,omitempty"`
+	SpectatorToken string `json:"spectator_token,omitempty"`
+}
+
+// Replay returns the record of the game so far
+func (g *GameSession) Replay() Replay {
+	r := Replay{
+		GameID:    g.ID,
+		OwnerID:   g.ownerID,
+		Seed:      g.seed,
+		Actions:   g.Actions(),
+		State:     g.state.String(),
+		Score:     g.Score(),
+		Challenge: g.challenge,
+
+		Public:         g.public,
+		SpectatorToken: g.spectatorToken,
+	}
+	if g.lesson != nil {
+		r.Tutorial = g.lesson.Name
+	}
+	if !g.finishedAt.IsZero() {
+		r.FinishedAt = g.finishedAt.UTC()
+	}
+	return r
+}
+
+// CanView returns whether a player, or a spectator holding token, may see
+// the game: anyone may see anonymous and public games, and owned games are
+// shown to their owner and their spectators
+func (r Replay) CanView(playerID, token string) bool {
+	if r.OwnerID == "" || r.OwnerID == playerID || r.Public {
+		return true
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(r.SpectatorToken)) == 1
+}
+
+// StateAt rebuilds the game as it was after the first n moves. Rebuilt games
+// are marked as practice so they are never ranked a second time.
+func (r Replay) StateAt(n int) (*GameSession, error) {
+	if n < 0 || n > len(r.Actions) {
+		return nil, fmt.Errorf("step %d out of range 0-%d", n, len(r.Actions))
+	}
+
+	return rebuild(r.GameID, SessionOptions{
+		OwnerID:   r.OwnerID,
+		Seed:      r.Seed,
+		Challenge: r.Challenge,
+		Tutorial:  r.Tutorial,
+		Practice:  true,
+	}, r.Actions[:n])
+}
+
+// rebuild deals a game from its options and replays moves on it
+func rebuild(id string, opts SessionOptions, actions []Action) (*GameSession, error) {
+	if opts.Seed == 0 {
+		return nil, errors.New("replay has no seed")
+	}
+	if _, ok := LessonByName(opts.Tutorial); opts.Tutorial != "" && !ok {
+		return nil, fmt.Errorf("unknown tutorial lesson %q", opts.Tutorial)
+	}
+
+	session := NewGameSessionWithOptions(opts)
+	session.ID = id
+
+	for i, action := range actions {
+		if err := session.Apply(action); err != nil {
+			return nil, fmt.Errorf("replaying move %d (%s): %w", i+1, action, err)
+		}
+	}
+
+	return session, nil
+}
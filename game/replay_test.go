@@ -0,0 +1,74 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// playUntilOver makes the first legal move each turn until the game ends
+func playUntilOver(t *testing.T, session *GameSession) {
+	t.Helper()
+
+	for i := 0; !session.IsGameOver() && i < 200; i++ {
+		if err := session.PlayCard(0); err != nil {
+			t.Fatalf("Error playing card: %v", err)
+		}
+	}
+}
+
+func TestReplayReconstruction(t *testing.T) {
+	session := NewGameSessionWithOptions(SessionOptions{Seed: 99})
+	if err := session.SkipRoom(); err != nil {
+		t.Fatalf("Error skipping room: %v", err)
+	}
+	if err := session.PlayCardWithoutWeapon(1); err != nil {
+		t.Fatalf("Error playing card barehanded: %v", err)
+	}
+	playUntilOver(t, session)
+
+	rep := session.Replay()
+	if rep.Actions[0].Type != ActionSkip || rep.Actions[1] != (Action{Type: ActionPlayBarehanded, Index: 1}) {
+		t.Errorf("Expected the action log to start with skip, play-barehanded 1, got %v", rep.Actions[:2])
+	}
+
+	// The final step matches the finished game
+	final, err := rep.StateAt(len(rep.Actions))
+	if err != nil {
+		t.Fatalf("Error rebuilding final state: %v", err)
+	}
+	if final.GetState() != session.GetState() || final.Score() != session.Score() {
+		t.Errorf("Expected rebuilt game to end %v with score %d, got %v with %d",
+			session.GetState(), session.Score(), final.GetState(), final.Score())
+	}
+
+	// Step 0 is the initial deal
+	initial, _ := rep.StateAt(0)
+	fresh := NewGameSessionWithOptions(SessionOptions{Seed: 99})
+	for i, card := range fresh.GetCurrentRoom().Cards() {
+		if *initial.GetCurrentRoom().Cards()[i] != *card {
+			t.Errorf("Expected step 0 to be the initial deal")
+		}
+	}
+
+	if _, err := rep.StateAt(len(rep.Actions) + 1); err == nil {
+		t.Errorf("Expected an error for a step past the end")
+	}
+}
+
+func TestActionJSON(t *testing.T) {
+	data, err := json.Marshal(Action{Type: ActionPlayBarehanded, Index: 2})
+	if err != nil {
+		t.Fatalf("Error encoding action: %v", err)
+	}
+	if string(data) != `{"type":"play-barehanded","index":2}` {
+		t.Errorf("Unexpected action encoding: %s", data)
+	}
+
+	var a Action
+	if err := json.Unmarshal([]byte(`{"type":"skip"}`), &a); err != nil || a.Type != ActionSkip {
+		t.Errorf("Expected to decode a skip action, got %v (%v)", a, err)
+	}
+	if err := json.Unmarshal([]byte(`{"type":"fly"}`), &a); err == nil {
+		t.Errorf("Expected an error decoding an unknown action type")
+	}
+}
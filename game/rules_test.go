@@ -94,6 +94,33 @@ func TestWeaponAccordionRule(t *testing.T) {
 	}
 }
 
+// TestViewWeaponRule verifies that the visible state applies the same weapon
+// rule as the game
+func TestViewWeaponRule(t *testing.T) {
+	session := NewGameSession()
+	player := session.GetPlayer()
+
+	check := func(stage string) {
+		t.Helper()
+		view := session.View().Player
+		for rank := Two; rank <= Ace; rank++ {
+			monster := &Card{Suit: Clubs, Rank: rank}
+			want := player.EquippedWeapon() != nil && player.CanUseWeaponAgainst(monster)
+			if got := view.CanUseWeaponAgainst(NewCardView(monster)); got != want {
+				t.Errorf("%s: expected CanUseWeaponAgainst(%s) %v, got %v", stage, monster, want, got)
+			}
+		}
+	}
+
+	check("no weapon")
+	player.EquipWeapon(&Card{Suit: Diamonds, Rank: Seven})
+	check("fresh weapon")
+	session.handleMonster(&Card{Suit: Spades, Rank: Jack})
+	check("after a jack")
+	session.handleMonster(&Card{Suit: Clubs, Rank: Four})
+	check("after a four")
+}
+
 // TestWeaponReplacement verifies that equipping a new weapon resets the defeated monsters history
 func TestWeaponReplacement(t *testing.T) {
 	session := NewGameSession()
@@ -252,3 +279,37 @@ func TestLossCondition(t *testing.T) {
 		t.Errorf("Expected game state to be Lost after health reaches 0, but got %v", session.GetState())
 	}
 }
+
+// TestScoring verifies the official scoring rules
+func TestScoring(t *testing.T) {
+	// Losing score is the negative sum of monsters left in the dungeon
+	session := NewGameSession()
+	session.deck = &Deck{cards: []*Card{
+		{Suit: Clubs, Rank: Five},
+		{Suit: Hearts, Rank: Four}, // Potions don't count
+		{Suit: Spades, Rank: Ten},
+	}}
+	session.currentRoom = NewRoom([]*Card{{Suit: Clubs, Rank: King}})
+	session.state = GameStateLost
+
+	if score := session.Score(); score != -(5 + 10 + 13) {
+		t.Errorf("Expected losing score of %d, got %d", -(5 + 10 + 13), score)
+	}
+
+	// Winning score is the remaining health
+	session = NewGameSession()
+	session.player.health = 15
+	session.state = GameStateWon
+
+	if score := session.Score(); score != 15 {
+		t.Errorf("Expected winning score of 15, got %d", score)
+	}
+
+	// At full health, a final potion adds its value
+	session.player.health = 20
+	session.lastCardPlayed = &Card{Suit: Hearts, Rank: Seven}
+
+	if score := session.Score(); score != 27 {
+		t.Errorf("Expected winning score of 27, got %d", score)
+	}
+}
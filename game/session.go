@@ -1,31 +1,56 @@
 package game
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
 
+var (
+	// ErrSessionNotFound is returned when no session exists for an ID
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSpectateForbidden is returned when a spectator token does not grant access to a game
+	ErrSpectateForbidden = errors.New("game is private")
+)
+
 // SessionManager manages active game sessions
 type SessionManager struct {
 	sessions map[string]*GameSession
+	watchers map[string][]chan struct{}
 	mutex    sync.RWMutex
+
+	createListeners   []func(*GameSession)
+	moveListeners     []func(*GameSession, Action)
+	gameOverListeners []func(*GameSession)
 }
 
 // NewSessionManager creates a new session manager
 func NewSessionManager() *SessionManager {
 	return &SessionManager{
 		sessions: make(map[string]*GameSession),
+		watchers: make(map[string][]chan struct{}),
 	}
 }
 
 // CreateSession creates a new game session
 func (sm *SessionManager) CreateSession() string {
-	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
+	return sm.CreateSessionWithOptions(SessionOptions{})
+}
+
+// CreateSessionWithOptions creates a new game session with the given options
+func (sm *SessionManager) CreateSessionWithOptions(opts SessionOptions) string {
+	session := NewGameSessionWithOptions(opts)
 
-	session := NewGameSession()
+	sm.mutex.Lock()
 	sm.sessions[session.GetID()] = session
+	listeners := sm.createListeners
+	sm.mutex.Unlock()
+
+	for _, listener := range listeners {
+		listener(session)
+	}
 
 	return session.GetID()
 }
@@ -37,7 +62,7 @@ func (sm *SessionManager) GetSession(id string) (*GameSession, error) {
 
 	session, exists := sm.sessions[id]
 	if !exists {
-		return nil, errors.New("session not found")
+		return nil, ErrSessionNotFound
 	}
 
 	return session, nil
@@ -49,74 +74,189 @@ func (sm *SessionManager) DeleteSession(id string) {
 	defer sm.mutex.Unlock()
 
 	delete(sm.sessions, id)
+	sm.closeWatchers(id)
 }
 
-// PlayCard plays a card in the specified session
-func (sm *SessionManager) PlayCard(sessionID string, cardIndex int) (*GameSession, error) {
-	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
+// GetGameState returns the state of a session, taken under the read lock so
+// that it cannot race with a concurrent move
+func (sm *SessionManager) GetGameState(id string) (map[string]interface{}, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
 
-	session, exists := sm.sessions[sessionID]
+	session, exists := sm.sessions[id]
 	if !exists {
-		return nil, errors.New("session not found")
+		return nil, ErrSessionNotFound
 	}
 
-	err := session.PlayCard(cardIndex)
-	if err != nil {
-		return nil, err
-	}
+	return session.GetGameState(), nil
+}
 
-	return session, nil
+// Position is a copy of a game as its player knows it, for work such as a
+// search that should not hold the session lock
+type Position struct {
+	View     View
+	Replay   Replay
+	OwnerID  string
+	Practice bool
+	Over     bool
 }
 
-// PlayCardWithoutWeapon plays a card without using a weapon
-func (sm *SessionManager) PlayCardWithoutWeapon(sessionID string, cardIndex int) (*GameSession, error) {
-	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
+// GetPosition returns the position of a session, taken under the read lock
+// so that it cannot race with a concurrent move
+func (sm *SessionManager) GetPosition(id string) (Position, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
 
-	session, exists := sm.sessions[sessionID]
+	session, exists := sm.sessions[id]
 	if !exists {
-		return nil, errors.New("session not found")
+		return Position{}, ErrSessionNotFound
 	}
 
-	err := session.PlayCardWithoutWeapon(cardIndex)
+	return Position{
+		View:     session.View(),
+		Replay:   session.Replay(),
+		OwnerID:  session.GetOwnerID(),
+		Practice: session.IsPractice(),
+		Over:     session.IsGameOver(),
+	}, nil
+}
+
+// PlayCard plays a card in the specified session and returns the resulting state
+func (sm *SessionManager) PlayCard(sessionID string, cardIndex int) (map[string]interface{}, error) {
+	return sm.applyForState(sessionID, Action{Type: ActionPlay, Index: cardIndex})
+}
+
+// PlayCardWithoutWeapon plays a card without using a weapon and returns the resulting state
+func (sm *SessionManager) PlayCardWithoutWeapon(sessionID string, cardIndex int) (map[string]interface{}, error) {
+	return sm.applyForState(sessionID, Action{Type: ActionPlayBarehanded, Index: cardIndex})
+}
+
+// SkipRoom skips the current room in the specified session and returns the resulting state
+func (sm *SessionManager) SkipRoom(sessionID string) (map[string]interface{}, error) {
+	return sm.applyForState(sessionID, Action{Type: ActionSkip})
+}
+
+// applyForState performs an action and returns the state of the session
+// right after it, taken before the write lock is released
+func (sm *SessionManager) applyForState(sessionID string, action Action) (map[string]interface{}, error) {
+	var state map[string]interface{}
+	_, err := sm.apply(sessionID, action, func(session *GameSession) {
+		state = session.GetGameState()
+	})
 	if err != nil {
 		return nil, err
 	}
+	return state, nil
+}
 
-	return session, nil
+// OnCreate registers a listener called for every session created through
+// the session manager
+func (sm *SessionManager) OnCreate(listener func(*GameSession)) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.createListeners = append(sm.createListeners, listener)
+}
+
+// OnMove registers a listener called after every successful move made
+// through the session manager. Listeners run after the session lock is
+// released, on the goroutine that made the move.
+func (sm *SessionManager) OnMove(listener func(*GameSession, Action)) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.moveListeners = append(sm.moveListeners, listener)
 }
 
-// SkipRoom skips the current room in the specified session
-func (sm *SessionManager) SkipRoom(sessionID string) (*GameSession, error) {
+// OnGameOver registers a listener called once for every game that ends
+// through the session manager. Listeners run after the session lock is
+// released, on the goroutine that made the final move.
+func (sm *SessionManager) OnGameOver(listener func(*GameSession)) {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
+	sm.gameOverListeners = append(sm.gameOverListeners, listener)
+}
+
+// Apply performs an action in the specified session under the write lock,
+// then notifies watchers and move listeners, and game over listeners if
+// the move ended the game
+func (sm *SessionManager) Apply(sessionID string, action Action) (*GameSession, error) {
+	return sm.apply(sessionID, action, nil)
+}
+
+// apply implements Apply, calling applied with the write lock still held
+// after a successful move if it is not nil
+func (sm *SessionManager) apply(sessionID string, action Action, applied func(*GameSession)) (*GameSession, error) {
+	sm.mutex.Lock()
+
 	session, exists := sm.sessions[sessionID]
 	if !exists {
-		return nil, errors.New("session not found")
+		sm.mutex.Unlock()
+		return nil, ErrSessionNotFound
 	}
 
-	err := session.SkipRoom()
-	if err != nil {
+	wasOver := session.IsGameOver()
+	if err := session.Apply(action); err != nil {
+		sm.mutex.Unlock()
 		return nil, err
 	}
+	session.lastActiveAt = time.Now()
+	if applied != nil {
+		applied(session)
+	}
+	sm.notifyWatchers(sessionID)
+
+	finished := !wasOver && session.IsGameOver()
+	moveListeners := sm.moveListeners
+	gameOverListeners := sm.gameOverListeners
+	sm.mutex.Unlock()
+
+	for _, listener := range moveListeners {
+		listener(session, action)
+	}
+	if finished {
+		for _, listener := range gameOverListeners {
+			listener(session)
+		}
+	}
 
 	return session, nil
 }
 
-// CleanupSessions removes completed or stale sessions
-func (sm *SessionManager) CleanupSessions(maxAge time.Duration) {
+// CleanupSessions removes completed sessions and sessions with no move for
+// longer than maxAge, and returns the number removed
+func (sm *SessionManager) CleanupSessions(maxAge time.Duration) int {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
 
-	// In a real implementation, we would track creation time and
-	// last access time for sessions and remove old ones
-
-	// For now, just remove completed games
+	removed := 0
 	for id, session := range sm.sessions {
-		if session.IsGameOver() {
+		if session.IsGameOver() || time.Since(session.lastActiveAt) > maxAge {
 			delete(sm.sessions, id)
+			sm.closeWatchers(id)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// RunReaper calls CleanupSessions every interval until ctx is cancelled,
+// passing the number of sessions removed by each sweep to onEvict if it is not nil
+func (sm *SessionManager) RunReaper(ctx context.Context, interval, maxAge time.Duration, onEvict func(int)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed := sm.CleanupSessions(maxAge)
+			if onEvict != nil && removed > 0 {
+				onEvict(removed)
+			}
 		}
 	}
 }
@@ -129,6 +269,22 @@ func (sm *SessionManager) ActiveSessionCount() int {
 	return len(sm.sessions)
 }
 
+// TryActiveSessionCount returns the number of active sessions, or false if
+// the lock could not be taken within timeout. Unlike ActiveSessionCount it
+// never waits on a stuck lock, and leaves nothing behind when it gives up.
+func (sm *SessionManager) TryActiveSessionCount(timeout time.Duration) (int, bool) {
+	deadline := time.Now().Add(timeout)
+	for !sm.mutex.TryRLock() {
+		if time.Now().After(deadline) {
+			return 0, false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	defer sm.mutex.RUnlock()
+
+	return len(sm.sessions), true
+}
+
 // GetAllSessions returns all active sessions (for monitoring/debugging)
 func (sm *SessionManager) GetAllSessions() []*GameSession {
 	sm.mutex.RLock()
@@ -141,3 +297,163 @@ func (sm *SessionManager) GetAllSessions() []*GameSession {
 
 	return sessions
 }
+
+// Spectate returns the visible state of a game for a spectator holding the given token.
+// Public games can be watched with any token.
+func (sm *SessionManager) Spectate(id, token string) (View, error) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	session, exists := sm.sessions[id]
+	if !exists {
+		return View{}, ErrSessionNotFound
+	}
+	if !session.CanSpectate(token) {
+		return View{}, ErrSpectateForbidden
+	}
+
+	return session.View(), nil
+}
+
+// LiveGames returns the visible state of every public game still in progress
+func (sm *SessionManager) LiveGames() []View {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	games := make([]View, 0)
+	for _, session := range sm.sessions {
+		if session.IsPublic() && !session.IsGameOver() {
+			games = append(games, session.View())
+		}
+	}
+
+	return games
+}
+
+// SessionsByOwner returns the visible state of every game owned by a player
+func (sm *SessionManager) SessionsByOwner(ownerID string) []View {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	games := make([]View, 0)
+	for _, session := range sm.sessions {
+		if ownerID != "" && session.GetOwnerID() == ownerID {
+			games = append(games, session.View())
+		}
+	}
+
+	return games
+}
+
+// Watch registers for notifications about changes to a game. A value is sent on
+// the returned channel after every move; the channel is closed when the session
+// is removed. The returned function must be called to stop watching.
+func (sm *SessionManager) Watch(id string) (<-chan struct{}, func(), error) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if _, exists := sm.sessions[id]; !exists {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	ch := make(chan struct{}, 1)
+	sm.watchers[id] = append(sm.watchers[id], ch)
+
+	stop := func() {
+		sm.mutex.Lock()
+		defer sm.mutex.Unlock()
+
+		watchers := sm.watchers[id]
+		for i, w := range watchers {
+			if w == ch {
+				sm.watchers[id] = append(watchers[:i], watchers[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+		if len(sm.watchers[id]) == 0 {
+			delete(sm.watchers, id)
+		}
+	}
+
+	return ch, stop, nil
+}
+
+// notifyWatchers signals every watcher of a game without blocking.
+// Must be called with the write lock held.
+func (sm *SessionManager) notifyWatchers(id string) {
+	for _, ch := range sm.watchers[id] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A notification is already pending
+		}
+	}
+}
+
+// closeWatchers closes every watcher channel of a game.
+// Must be called with the write lock held.
+func (sm *SessionManager) closeWatchers(id string) {
+	for _, ch := range sm.watchers[id] {
+		close(ch)
+	}
+	delete(sm.watchers, id)
+}
+
+// SessionSnapshot is the persistent form of a session still in progress,
+// from which it can be rebuilt after a restart
+type SessionSnapshot struct {
+	Replay
+	Practice  bool      `json:"practice,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Snapshot returns the persistent form of every session still in progress
+func (sm *SessionManager) Snapshot() []SessionSnapshot {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	snapshots := make([]SessionSnapshot, 0, len(sm.sessions))
+	for _, session := range sm.sessions {
+		if session.IsGameOver() {
+			continue
+		}
+		snapshots = append(snapshots, SessionSnapshot{
+			Replay:    session.Replay(),
+			Practice:  session.practice,
+			StartedAt: session.startedAt,
+		})
+	}
+
+	return snapshots
+}
+
+// Restore rebuilds sessions from snapshots and adds them to the manager.
+// Snapshots that cannot be rebuilt are skipped and reported in the error.
+func (sm *SessionManager) Restore(snapshots []SessionSnapshot) error {
+	var errs []error
+	for _, snap := range snapshots {
+		session, err := rebuild(snap.GameID, SessionOptions{
+			Public:    snap.Public,
+			OwnerID:   snap.OwnerID,
+			Seed:      snap.Seed,
+			Practice:  snap.Practice,
+			Challenge: snap.Challenge,
+			Tutorial:  snap.Tutorial,
+		}, snap.Actions)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session %s: %w", snap.GameID, err))
+			continue
+		}
+		session.spectatorToken = snap.SpectatorToken
+		if !snap.StartedAt.IsZero() {
+			session.startedAt = snap.StartedAt
+		}
+
+		sm.mutex.Lock()
+		sm.sessions[session.GetID()] = session
+		sm.mutex.Unlock()
+	}
+
+	return errors.Join(errs...)
+}
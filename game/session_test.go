@@ -0,0 +1,202 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSpectatorAccess verifies that private games require the spectator token
+func TestSpectatorAccess(t *testing.T) {
+	sm := NewSessionManager()
+
+	privateID := sm.CreateSession()
+	publicID := sm.CreateSessionWithOptions(SessionOptions{Public: true})
+
+	private, _ := sm.GetSession(privateID)
+
+	// Private game without token should be rejected
+	if _, err := sm.Spectate(privateID, ""); !errors.Is(err, ErrSpectateForbidden) {
+		t.Errorf("Expected ErrSpectateForbidden for private game without token, got %v", err)
+	}
+
+	// Private game with the right token should be visible
+	view, err := sm.Spectate(privateID, private.GetSpectatorToken())
+	if err != nil {
+		t.Fatalf("Expected to spectate private game with token, got %v", err)
+	}
+	if view.GameID != privateID {
+		t.Errorf("Expected view of game %s, got %s", privateID, view.GameID)
+	}
+
+	// Public game without token should be visible
+	if _, err := sm.Spectate(publicID, ""); err != nil {
+		t.Errorf("Expected to spectate public game without token, got %v", err)
+	}
+
+	// Unknown game
+	if _, err := sm.Spectate("missing", ""); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound for unknown game, got %v", err)
+	}
+
+	// Only the public game is listed as live
+	live := sm.LiveGames()
+	if len(live) != 1 || live[0].GameID != publicID {
+		t.Errorf("Expected only the public game to be live, got %d games", len(live))
+	}
+}
+
+// TestSpectatorView verifies that the spectator view matches the player's view
+func TestSpectatorView(t *testing.T) {
+	session := NewGameSession()
+	view := session.View()
+
+	if len(view.Room.Cards) != len(session.GetCurrentRoom().Cards()) {
+		t.Errorf("Expected %d room cards in view, got %d", len(session.GetCurrentRoom().Cards()), len(view.Room.Cards))
+	}
+	for i, card := range session.GetCurrentRoom().Cards() {
+		if view.Room.Cards[i].Display != card.String() || view.Room.Cards[i].Index != i {
+			t.Errorf("Expected room card %d to be %s, got %s", i, card.String(), view.Room.Cards[i].Display)
+		}
+	}
+	if view.Deck.RemainingCards != session.GetDeck().Remaining() {
+		t.Errorf("Expected %d remaining cards in view, got %d", session.GetDeck().Remaining(), view.Deck.RemainingCards)
+	}
+}
+
+// TestWatchNotifications verifies that watchers are notified of moves and closed on removal
+func TestWatchNotifications(t *testing.T) {
+	sm := NewSessionManager()
+	id := sm.CreateSession()
+
+	updates, stop, err := sm.Watch(id)
+	if err != nil {
+		t.Fatalf("Expected to watch game, got %v", err)
+	}
+	defer stop()
+
+	if _, err := sm.SkipRoom(id); err != nil {
+		t.Fatalf("Error skipping room: %v", err)
+	}
+
+	select {
+	case <-updates:
+	default:
+		t.Errorf("Expected a notification after a move")
+	}
+
+	sm.DeleteSession(id)
+	if _, open := <-updates; open {
+		t.Errorf("Expected watcher channel to be closed after session removal")
+	}
+}
+
+// TestListAndEndSessions verifies the operator view of sessions
+func TestListAndEndSessions(t *testing.T) {
+	sm := NewSessionManager()
+
+	aliceID := sm.CreateSessionWithOptions(SessionOptions{OwnerID: "alice", Seed: 42})
+	for i := 0; i < 3; i++ {
+		sm.CreateSessionWithOptions(SessionOptions{OwnerID: "bob"})
+	}
+
+	summaries, total := sm.ListSessions(SessionFilter{OwnerID: "bob"}, 1, 1)
+	if total != 3 || len(summaries) != 1 {
+		t.Errorf("Expected page of 1 out of 3 sessions for bob, got %d of %d", len(summaries), total)
+	}
+
+	if _, total := sm.ListSessions(SessionFilter{MinAge: time.Hour}, 0, 0); total != 0 {
+		t.Errorf("Expected no sessions older than an hour, got %d", total)
+	}
+
+	// The inspection reveals the deck in draw order
+	inspection, err := sm.Inspect(aliceID)
+	if err != nil {
+		t.Fatalf("Error inspecting session: %v", err)
+	}
+	session, _ := sm.GetSession(aliceID)
+	if len(inspection.DeckOrder) != session.GetDeck().Remaining() {
+		t.Errorf("Expected %d cards in deck order, got %d", session.GetDeck().Remaining(), len(inspection.DeckOrder))
+	}
+	if inspection.Seed != 42 {
+		t.Errorf("Expected seed 42, got %d", inspection.Seed)
+	}
+
+	if err := sm.EndSession(aliceID); err != nil {
+		t.Fatalf("Error ending session: %v", err)
+	}
+	if session.GetState() != GameStateLost {
+		t.Errorf("Expected forced end to lose the game, got %s", session.GetState())
+	}
+	if err := sm.EndSession(aliceID); !errors.Is(err, ErrGameOver) {
+		t.Errorf("Expected ErrGameOver ending a finished game, got %v", err)
+	}
+
+	if _, total := sm.ListSessions(SessionFilter{State: "Lost"}, 0, 0); total != 1 {
+		t.Errorf("Expected 1 lost session, got %d", total)
+	}
+}
+
+// TestGameStateSnapshots verifies that moves return the state right after
+// them and that reading the state does not race with concurrent moves
+func TestGameStateSnapshots(t *testing.T) {
+	sm := NewSessionManager()
+	id := sm.CreateSession()
+
+	state, err := sm.SkipRoom(id)
+	if err != nil {
+		t.Fatalf("Error skipping room: %v", err)
+	}
+	deck := state["deck"].(map[string]interface{})
+	if deck["previous_room_skipped"] != true {
+		t.Errorf("Expected the returned state to show the skip, got %v", deck["previous_room_skipped"])
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			sm.PlayCard(id, 0)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if _, err := sm.GetGameState(id); err != nil {
+			t.Fatalf("Error reading game state: %v", err)
+		}
+		if _, err := sm.GetPosition(id); err != nil {
+			t.Fatalf("Error reading position: %v", err)
+		}
+	}
+	<-done
+
+	pos, err := sm.GetPosition(id)
+	if err != nil {
+		t.Fatalf("Error reading position: %v", err)
+	}
+	if len(pos.Replay.Actions) == 0 || pos.Replay.Actions[0].Type != ActionSkip || pos.View.GameID != id {
+		t.Errorf("Expected the position to hold the moves made, got %+v", pos.Replay)
+	}
+
+	if _, err := sm.GetGameState("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound for unknown game, got %v", err)
+	}
+	if _, err := sm.GetPosition("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound for unknown position, got %v", err)
+	}
+}
+
+// TestTryActiveSessionCount verifies that counting gives up on a stuck lock
+func TestTryActiveSessionCount(t *testing.T) {
+	sm := NewSessionManager()
+	sm.CreateSession()
+
+	if n, ok := sm.TryActiveSessionCount(time.Millisecond); !ok || n != 1 {
+		t.Errorf("Expected 1 session, got %d (%v)", n, ok)
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	if _, ok := sm.TryActiveSessionCount(10 * time.Millisecond); ok {
+		t.Errorf("Expected counting to give up while the lock is held")
+	}
+}
@@ -0,0 +1,390 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// simCard is a card packed in a byte: the suit in the high bits and the
+// rank in the low four. The zero value is no card.
+type simCard uint8
+
+// packCard packs a card
+func packCard(card *Card) simCard {
+	if card == nil {
+		return 0
+	}
+	return simCard(card.Suit)<<4 | simCard(card.Rank)
+}
+
+// card unpacks a card
+func (c simCard) card() Card {
+	return Card{Suit: Suit(c >> 4), Rank: Rank(c & 0xf)}
+}
+
+// value returns the numerical value of the card
+func (c simCard) value() int {
+	return int(c & 0xf)
+}
+
+// cardType returns the functional type of the card
+func (c simCard) cardType() CardType {
+	switch Suit(c >> 4) {
+	case Diamonds:
+		return Weapon
+	case Hearts:
+		return Potion
+	default:
+		return Monster
+	}
+}
+
+const (
+	// simDeckSize is the capacity of a simulated deck, a power of two above
+	// the 44 cards of a dungeon, so ring positions wrap cheaply
+	simDeckSize = 64
+	// simMaxDefeated is the most monsters one weapon can defeat: every
+	// monster of the dungeon
+	simMaxDefeated = 26
+)
+
+// simDungeon is every card of a full dungeon, in the order NewDeck deals them
+var simDungeon = func() [44]simCard {
+	var cards [44]simCard
+	for i, card := range NewDeck().cards {
+		cards[i] = packCard(card)
+	}
+	return cards
+}()
+
+// Errors of Sim moves, made once so that Apply does not allocate
+var (
+	errNotInProgress = errors.New("game is not in progress")
+	errInvalidIndex  = errors.New("invalid card index")
+	errSkipTwice     = errors.New("cannot skip two rooms in a row")
+	errSkipAfterPlay = errors.New("cannot skip a room after playing cards")
+	errSimTooLarge   = errors.New("game too large to simulate")
+)
+
+// Sim is a compact copy of a game for simulations and search. It follows
+// the rules of GameSession move for move, but holds its cards as bytes in
+// fixed-size arrays, so it is copied by assignment, and Clone and Apply
+// never allocate. A Sim has no ID, owner or history, and does not enforce
+// tutorial lessons.
+type Sim struct {
+	// deck is a ring of cards, the top at deckTop
+	deck      [simDeckSize]simCard
+	deckTop   uint8
+	deckCount uint8
+	room      [4]simCard
+	roomSize  uint8
+	// roomPlayed is the number of cards played in the current room
+	roomPlayed   uint8
+	defeated     [simMaxDefeated]simCard
+	defeatedLen  uint8
+	weapon       simCard
+	lastPlayed   simCard
+	health       int8
+	maxHealth    int8
+	usedPotion   bool
+	prevSkipped  bool
+	state        GameState
+	roomsCleared uint8
+}
+
+// NewSim deals a game from a seed, the same dungeon as a session with that
+// seed. Zero picks a random seed.
+func NewSim(seed int64) Sim {
+	if seed == 0 {
+		seed = randomSeed()
+	}
+	cards := simDungeon
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+
+	s := Sim{health: 20, maxHealth: 20}
+	copy(s.deck[:], cards[:])
+	s.deckCount = uint8(len(cards))
+	s.createRoom()
+	return s
+}
+
+// SimOf returns a simulation of a game session in its current state
+func SimOf(g *GameSession) (Sim, error) {
+	if len(g.deck.cards)+4 > simDeckSize || len(g.player.defeatedMonsters) > simMaxDefeated ||
+		(g.currentRoom != nil && len(g.currentRoom.cards) > 4) {
+		return Sim{}, errSimTooLarge
+	}
+	s := Sim{
+		health:       int8(g.player.health),
+		maxHealth:    int8(g.player.maxHealth),
+		weapon:       packCard(g.player.equippedWeapon),
+		lastPlayed:   packCard(g.lastCardPlayed),
+		usedPotion:   g.player.usedPotionThisRoom,
+		prevSkipped:  g.deck.prevRoomSkipped,
+		state:        g.state,
+		roomsCleared: uint8(g.roomsCleared),
+	}
+	for i, card := range g.deck.cards {
+		s.deck[i] = packCard(card)
+	}
+	s.deckCount = uint8(len(g.deck.cards))
+	if g.currentRoom != nil {
+		for i, card := range g.currentRoom.cards {
+			s.room[i] = packCard(card)
+		}
+		s.roomSize = uint8(len(g.currentRoom.cards))
+		s.roomPlayed = uint8(len(g.currentRoom.playedCards))
+	}
+	for i, card := range g.player.defeatedMonsters {
+		s.defeated[i] = packCard(card)
+	}
+	s.defeatedLen = uint8(len(g.player.defeatedMonsters))
+	return s, nil
+}
+
+// Clone returns an independent copy of the game
+func (s *Sim) Clone() Sim {
+	return *s
+}
+
+// GetState returns the current game state
+func (s *Sim) GetState() GameState {
+	return s.state
+}
+
+// IsGameOver returns true if the game is over (won or lost)
+func (s *Sim) IsGameOver() bool {
+	return s.state == GameStateWon || s.state == GameStateLost
+}
+
+// Health returns the current health of the player
+func (s *Sim) Health() int {
+	return int(s.health)
+}
+
+// RoomSize returns the number of cards in the current room
+func (s *Sim) RoomSize() int {
+	return int(s.roomSize)
+}
+
+// RoomCard returns the card at an index of the current room
+func (s *Sim) RoomCard(index int) Card {
+	return s.room[index].card()
+}
+
+// RoomsCleared returns the number of rooms in which three cards were played
+func (s *Sim) RoomsCleared() int {
+	return int(s.roomsCleared)
+}
+
+// RemainingCards returns the number of cards left in the deck
+func (s *Sim) RemainingCards() int {
+	return int(s.deckCount)
+}
+
+// Moves appends every move allowed in the current state to moves and
+// returns it, in the order of bot.LegalMoves. With a slice of capacity 9,
+// it does not allocate.
+func (s *Sim) Moves(moves []Action) []Action {
+	if s.state != GameStateInProgress {
+		return moves
+	}
+	for i := 0; i < int(s.roomSize); i++ {
+		moves = append(moves, Action{Type: ActionPlay, Index: i})
+		if s.room[i].cardType() == Monster && s.weapon != 0 {
+			moves = append(moves, Action{Type: ActionPlayBarehanded, Index: i})
+		}
+	}
+	if !s.prevSkipped && s.roomPlayed == 0 && s.roomSize == 4 {
+		moves = append(moves, Action{Type: ActionSkip})
+	}
+	return moves
+}
+
+// Apply performs an action on the game, following the same rules and
+// returning the same errors as GameSession.Apply
+func (s *Sim) Apply(a Action) error {
+	switch a.Type {
+	case ActionPlay:
+		return s.play(a.Index, true)
+	case ActionPlayBarehanded:
+		return s.play(a.Index, false)
+	case ActionSkip:
+		return s.skip()
+	default:
+		return fmt.Errorf("unknown action type %d", int(a.Type))
+	}
+}
+
+// play plays a room card, using the weapon against a monster if allowed
+// and armed is set
+func (s *Sim) play(index int, armed bool) error {
+	if s.state != GameStateInProgress {
+		return errNotInProgress
+	}
+	if index < 0 || index >= int(s.roomSize) {
+		return errInvalidIndex
+	}
+
+	// Take the card out of the room, keeping the others in order
+	card := s.room[index]
+	copy(s.room[index:s.roomSize], s.room[index+1:s.roomSize])
+	s.roomSize--
+	s.room[s.roomSize] = 0
+	s.roomPlayed++
+	s.lastPlayed = card
+
+	switch card.cardType() {
+	case Monster:
+		canUseWeapon := s.weapon != 0 &&
+			(s.defeatedLen == 0 || card.value() <= s.defeated[s.defeatedLen-1].value())
+		if armed && canUseWeapon {
+			s.health -= int8(max(0, card.value()-s.weapon.value()))
+			s.defeated[s.defeatedLen] = card
+			s.defeatedLen++
+		} else {
+			s.health -= int8(card.value())
+		}
+	case Weapon:
+		s.weapon = card
+		s.defeated = [simMaxDefeated]simCard{}
+		s.defeatedLen = 0
+	case Potion:
+		// Only the first potion in a room has effect
+		if !s.usedPotion {
+			s.health = min(s.maxHealth, s.health+int8(card.value()))
+			s.usedPotion = true
+		}
+	}
+
+	// Check if room is completed (3 cards played)
+	if s.roomPlayed == 3 {
+		s.roomsCleared++
+		s.createRoom()
+	}
+
+	// Check if player is dead
+	if s.health <= 0 {
+		s.state = GameStateLost
+	}
+	return nil
+}
+
+// skip puts the room at the bottom of the deck and deals a new one
+func (s *Sim) skip() error {
+	if s.state != GameStateInProgress {
+		return errNotInProgress
+	}
+	if s.prevSkipped {
+		return errSkipTwice
+	}
+	if s.roomPlayed > 0 {
+		return errSkipAfterPlay
+	}
+
+	for i := 0; i < int(s.roomSize); i++ {
+		s.deck[(int(s.deckTop)+int(s.deckCount))%simDeckSize] = s.room[i]
+		s.deckCount++
+	}
+	s.roomSize = 0
+	s.prevSkipped = true
+	s.createRoom()
+	return nil
+}
+
+// createRoom deals the next room: the card left in the current room and
+// three from the deck, or four from the deck. The game is won when the deck
+// runs out.
+func (s *Sim) createRoom() {
+	need := uint8(4)
+	if s.roomSize == 1 {
+		need = 3
+	}
+	if s.deckCount < need {
+		s.state = GameStateWon
+		return
+	}
+
+	if need == 4 {
+		s.roomSize = 0
+	}
+	for s.roomSize < 4 {
+		s.room[s.roomSize] = s.deck[s.deckTop%simDeckSize]
+		s.deckTop = (s.deckTop + 1) % simDeckSize
+		s.deckCount--
+		s.roomSize++
+	}
+	s.roomPlayed = 0
+	s.state = GameStateInProgress
+	s.usedPotion = false
+}
+
+// Score returns the official score of the game, as GameSession.Score does
+func (s *Sim) Score() int {
+	if s.state == GameStateLost {
+		score := 0
+		for i := 0; i < int(s.deckCount); i++ {
+			if card := s.deck[(int(s.deckTop)+i)%simDeckSize]; card.cardType() == Monster {
+				score -= card.value()
+			}
+		}
+		for i := 0; i < int(s.roomSize); i++ {
+			if card := s.room[i]; card.cardType() == Monster {
+				score -= card.value()
+			}
+		}
+		return score
+	}
+
+	score := int(s.health)
+	if s.health == s.maxHealth && s.lastPlayed != 0 && s.lastPlayed.cardType() == Potion {
+		score += s.lastPlayed.value()
+	}
+	return score
+}
+
+// View returns the visible state of the game, as GameSession.View does
+// apart from the game ID and tutorial progress, which a Sim does not have
+func (s *Sim) View() View {
+	roomCards := make([]RoomCardView, 0)
+	for i := 0; i < int(s.roomSize); i++ {
+		card := s.room[i].card()
+		roomCards = append(roomCards, RoomCardView{Index: i, CardView: NewCardView(&card)})
+	}
+
+	var equippedWeapon *CardView
+	if s.weapon != 0 {
+		card := s.weapon.card()
+		cv := NewCardView(&card)
+		equippedWeapon = &cv
+	}
+
+	defeatedMonsters := make([]CardView, 0)
+	for i := 0; i < int(s.defeatedLen); i++ {
+		card := s.defeated[i].card()
+		defeatedMonsters = append(defeatedMonsters, NewCardView(&card))
+	}
+
+	return View{
+		State: s.state.String(),
+		Player: PlayerView{
+			Health:           int(s.health),
+			MaxHealth:        int(s.maxHealth),
+			EquippedWeapon:   equippedWeapon,
+			DefeatedMonsters: defeatedMonsters,
+			UsedPotion:       s.usedPotion,
+		},
+		Room: RoomView{
+			Cards:     roomCards,
+			Completed: s.roomPlayed == 3,
+		},
+		Deck: DeckView{
+			RemainingCards:      int(s.deckCount),
+			PreviousRoomSkipped: s.prevSkipped,
+		},
+	}
+}
@@ -0,0 +1,271 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// randomAction returns the next action of a differential game. Careful
+// play picks the move with the best plan for the rest of the room, which
+// wins games random play does not. Random play makes a legal move most of
+// the time, and otherwise any action, legal or not, so errors are compared
+// too.
+func randomAction(rng *rand.Rand, s *Sim, careful bool) Action {
+	moves := s.Moves(nil)
+	if len(moves) == 0 || (!careful && rng.Intn(10) == 0) {
+		return Action{Type: ActionType(rng.Intn(4)), Index: rng.Intn(6) - 1}
+	}
+	if !careful {
+		return moves[rng.Intn(len(moves))]
+	}
+	best, _ := planRoom(s)
+	return best
+}
+
+// planRoom searches the plays left in the room and returns the best first
+// move and the value it leads to: health plus the weapon's strength, less
+// a monster left for the next room
+func planRoom(s *Sim) (Action, int) {
+	var best Action
+	bestValue := math.MinInt
+	for _, move := range s.Moves(nil) {
+		next := s.Clone()
+		next.Apply(move)
+		value := int(next.health)
+		switch {
+		case next.IsGameOver() && next.GetState() == GameStateLost:
+			value -= 100
+		case next.IsGameOver() || move.Type == ActionSkip:
+			value += next.weapon.value()
+		case next.roomsCleared != s.roomsCleared:
+			value += next.weapon.value()
+			if leftover := next.room[0]; leftover.cardType() == Monster {
+				value -= leftover.value()
+			}
+		default:
+			_, value = planRoom(&next)
+		}
+		if value > bestValue {
+			best, bestValue = move, value
+		}
+	}
+	return best, bestValue
+}
+
+// checkSim fails the test if the simulation differs from the session
+func checkSim(t *testing.T, session *GameSession, s *Sim, context string) {
+	t.Helper()
+
+	expected := session.View()
+	expected.GameID = ""
+	expected.Tutorial = nil
+	if view := s.View(); !reflect.DeepEqual(view, expected) {
+		t.Fatalf("%s: expected view %+v, got %+v", context, expected, view)
+	}
+	if s.Score() != session.Score() {
+		t.Fatalf("%s: expected score %d, got %d", context, session.Score(), s.Score())
+	}
+	if s.RoomsCleared() != session.RoomsCleared() {
+		t.Fatalf("%s: expected %d rooms cleared, got %d", context, session.RoomsCleared(), s.RoomsCleared())
+	}
+}
+
+// playBoth makes the same random actions on a session and its simulation
+// until the game is over, checking they agree after every action
+func playBoth(t *testing.T, rng *rand.Rand, session *GameSession, s *Sim, careful bool, name string) {
+	t.Helper()
+
+	// A few actions after the end check that both refuse them
+	for extra := 0; extra < 3; {
+		if s.IsGameOver() {
+			extra++
+		}
+		action := randomAction(rng, s, careful)
+		sessionErr := session.Apply(action)
+		simErr := s.Apply(action)
+		if (sessionErr == nil) != (simErr == nil) || (sessionErr != nil && sessionErr.Error() != simErr.Error()) {
+			t.Fatalf("%s, %s: expected error %v, got %v", name, action, sessionErr, simErr)
+		}
+		checkSim(t, session, s, name+", "+action.String())
+	}
+}
+
+func TestSimMatchesSession(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	states := make(map[GameState]int)
+	for seed := int64(1); seed <= 500; seed++ {
+		session := NewGameSessionWithOptions(SessionOptions{Seed: seed})
+		s := NewSim(seed)
+		checkSim(t, session, &s, "new game")
+		playBoth(t, rng, session, &s, seed%2 == 0, fmt.Sprintf("seed %d", seed))
+		states[s.GetState()]++
+	}
+
+	// The games should cover both ends
+	if states[GameStateLost] == 0 || states[GameStateWon] == 0 {
+		t.Errorf("Expected both wins and losses, got %v", states)
+	}
+}
+
+func TestSimOf(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	// A game part way through
+	session := NewGameSessionWithOptions(SessionOptions{Seed: 7})
+	session.SkipRoom()
+	session.PlayCard(1)
+	s, err := SimOf(session)
+	if err != nil {
+		t.Fatalf("Error simulating game: %v", err)
+	}
+	checkSim(t, session, &s, "game in progress")
+	playBoth(t, rng, session, &s, false, "game in progress")
+
+	// Tutorial dungeons, with their lessons' moves
+	for _, lesson := range Lessons {
+		session = NewGameSessionWithOptions(SessionOptions{Tutorial: lesson.Name})
+		s, err = SimOf(session)
+		if err != nil {
+			t.Fatalf("Error simulating lesson %s: %v", lesson.Name, err)
+		}
+		for _, step := range lesson.Steps {
+			if err := session.Apply(step.Accept[0]); err != nil {
+				t.Fatalf("Error playing lesson %s: %v", lesson.Name, err)
+			}
+			if err := s.Apply(step.Accept[0]); err != nil {
+				t.Fatalf("Error simulating lesson %s: %v", lesson.Name, err)
+			}
+			checkSim(t, session, &s, "lesson "+lesson.Name)
+		}
+	}
+
+	// The last room of a hand-made dungeon wins the game
+	session = NewGameSession()
+	session.deck = &Deck{}
+	session.currentRoom = NewRoom([]*Card{NewCard(Diamonds, Five), NewCard(Hearts, Seven), NewCard(Clubs, Three), NewCard(Spades, Four)})
+	s, err = SimOf(session)
+	if err != nil {
+		t.Fatalf("Error simulating hand-made game: %v", err)
+	}
+	playBoth(t, rng, session, &s, true, "hand-made game")
+	if s.GetState() != GameStateWon {
+		t.Errorf("Expected the hand-made game to be won, got %v", s.GetState())
+	}
+}
+
+func TestSimClone(t *testing.T) {
+	s := NewSim(3)
+	before := s.View()
+
+	clone := s.Clone()
+	for !clone.IsGameOver() {
+		clone.Apply(Action{Type: ActionPlayBarehanded, Index: 0})
+		clone.Apply(Action{Type: ActionPlay, Index: 0})
+	}
+
+	if !reflect.DeepEqual(s.View(), before) {
+		t.Errorf("Expected playing a clone to leave the original unchanged")
+	}
+	if clone.RemainingCards() >= s.RemainingCards() {
+		t.Errorf("Expected the clone to have drawn cards, got %d left of %d", clone.RemainingCards(), s.RemainingCards())
+	}
+}
+
+func TestSimDoesNotAllocate(t *testing.T) {
+	start := NewSim(4)
+	rng := rand.New(rand.NewSource(4))
+	buf := make([]Action, 0, 9)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		s := start.Clone()
+		for !s.IsGameOver() {
+			moves := s.Moves(buf[:0])
+			s.Apply(moves[rng.Intn(len(moves))])
+		}
+		s.Apply(Action{Type: ActionPlay, Index: 9})
+		s.Score()
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations per game, got %v", allocs)
+	}
+}
+
+// dealSims deals simulations of the first seeds, for benchmarks
+func dealSims(n int) []Sim {
+	sims := make([]Sim, n)
+	for i := range sims {
+		sims[i] = NewSim(int64(i + 1))
+	}
+	return sims
+}
+
+func BenchmarkSimGame(b *testing.B) {
+	sims := dealSims(64)
+	rng := rand.New(rand.NewSource(1))
+	buf := make([]Action, 0, 9)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s := sims[i%len(sims)].Clone()
+		for !s.IsGameOver() {
+			moves := s.Moves(buf[:0])
+			s.Apply(moves[rng.Intn(len(moves))])
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "games/s")
+}
+
+// sessionMoves lists the legal moves of a session in the order of Sim.Moves
+func sessionMoves(g *GameSession) []Action {
+	var moves []Action
+	cards := g.currentRoom.Cards()
+	for i, card := range cards {
+		moves = append(moves, Action{Type: ActionPlay, Index: i})
+		if card.Type() == Monster && g.player.EquippedWeapon() != nil {
+			moves = append(moves, Action{Type: ActionPlayBarehanded, Index: i})
+		}
+	}
+	if !g.deck.PrevRoomSkipped() && len(cards) == 4 && len(g.currentRoom.playedCards) == 0 {
+		moves = append(moves, Action{Type: ActionSkip})
+	}
+	return moves
+}
+
+// BenchmarkSessionGame plays the same random games as BenchmarkSimGame on
+// game sessions. A session cannot be copied, so every game deals a new one.
+func BenchmarkSessionGame(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		session := NewGameSessionWithOptions(SessionOptions{Seed: int64(i%64 + 1)})
+		for !session.IsGameOver() {
+			moves := sessionMoves(session)
+			session.Apply(moves[rng.Intn(len(moves))])
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "games/s")
+}
+
+// BenchmarkSimCloneApply measures a step of a search: copying a state and
+// making a move on the copy
+func BenchmarkSimCloneApply(b *testing.B) {
+	s := NewSim(1)
+	health := 0
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		next := s.Clone()
+		next.Apply(Action{Type: ActionPlay, Index: i % 4})
+		health += next.Health()
+	}
+	if health == 0 {
+		b.Fatal("Expected the moves to leave some health")
+	}
+}
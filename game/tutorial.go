@@ -0,0 +1,282 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedMove is returned when a move in a tutorial game is not the
+// one the current step asks for
+var ErrUnexpectedMove = errors.New("that is not the move this step teaches")
+
+// TutorialStep is one move of a tutorial lesson
+type TutorialStep struct {
+	// Prompt tells the player what to do
+	Prompt string
+	// Accept lists the moves that complete the step
+	Accept []Action
+	// Outcome explains the result of the move
+	Outcome string
+}
+
+// Lesson is a hand-crafted dungeon teaching one rule, played one checked step at a time
+type Lesson struct {
+	Name  string
+	Title string
+	Intro string
+	Outro string
+	// Deck is the dungeon from top to bottom
+	Deck  []Card
+	Steps []TutorialStep
+}
+
+// TutorialView is the progress of a tutorial game
+type TutorialView struct {
+	Lesson string `json:"lesson"`
+	Title  string `json:"title"`
+	Intro  string `json:"intro"`
+	// Step is the number of steps completed
+	Step  int `json:"step"`
+	Steps int `json:"steps"`
+	// Prompt tells the player what to do next; empty once the lesson is done
+	Prompt string `json:"prompt,omitempty"`
+	// Outcome explains the result of the last move
+	Outcome string `json:"outcome,omitempty"`
+	// Outro wraps up the lesson once it is done
+	Outro string `json:"outro,omitempty"`
+}
+
+// play, barehanded and skip are shorthands for the moves of a lesson
+func play(index int) Action       { return Action{Type: ActionPlay, Index: index} }
+func barehanded(index int) Action { return Action{Type: ActionPlayBarehanded, Index: index} }
+func skip() Action                { return Action{Type: ActionSkip} }
+
+// Lessons are the tutorial lessons, in teaching order
+var Lessons = []Lesson{
+	{
+		Name:  "weapons",
+		Title: "Monsters and weapons",
+		Intro: "Clubs and spades are monsters: fighting one costs as much health as its value. Diamonds are weapons, which soften the blow.",
+		Outro: "Weapons turn big hits into small ones. Next: why the order you fight in matters.",
+		Deck:  []Card{{Diamonds, Five}, {Clubs, Eight}, {Spades, Two}, {Hearts, Three}},
+		Steps: []TutorialStep{
+			{
+				Prompt:  "Play the 5♦ (card 0) to equip it.",
+				Accept:  []Action{play(0)},
+				Outcome: "You equipped the 5♦. While equipped, a weapon takes its value off every monster you fight with it.",
+			},
+			{
+				Prompt:  "Fight the 8♣ (card 0) with your weapon.",
+				Accept:  []Action{play(0)},
+				Outcome: "The 8♣ hit for 8 and your 5♦ blocked 5, so you took 3 damage.",
+			},
+			{
+				Prompt:  "Fight the 2♠ (card 0) with your weapon.",
+				Accept:  []Action{play(0)},
+				Outcome: "The 2♠ is weaker than your weapon, so you took no damage. Playing three of a room's four cards clears it, and with the dungeon empty you escaped!",
+			},
+		},
+	},
+	{
+		Name:  "degradation",
+		Title: "Weapons wear down",
+		Intro: "Every monster a weapon defeats is stacked on it, and from then on the weapon can only fight monsters no stronger than the last one on the stack.",
+		Outro: "Fight the strongest monsters first, or pick up a fresh weapon, to keep your weapon useful.",
+		Deck:  []Card{{Diamonds, Seven}, {Clubs, Four}, {Spades, Nine}, {Hearts, Five}, {Diamonds, Eight}, {Clubs, Ten}, {Spades, Six}},
+		Steps: []TutorialStep{
+			{
+				Prompt:  "Play the 7♦ (card 0) to equip it.",
+				Accept:  []Action{play(0)},
+				Outcome: "You equipped the 7♦.",
+			},
+			{
+				Prompt:  "Fight the 4♣ (card 0) with your weapon.",
+				Accept:  []Action{play(0)},
+				Outcome: "The 4♣ is weaker than your 7♦, so you took no damage. It is now stacked on your weapon.",
+			},
+			{
+				Prompt:  "Now face the 9♠ (card 0). Your weapon last defeated a 4, so it cannot be used against a 9.",
+				Accept:  []Action{play(0), barehanded(0)},
+				Outcome: "You took the full 9 damage. Had you fought the 9♠ first, the 7♦ would have blocked 7 of it and could still have beaten the 4♣ afterwards.",
+			},
+			{
+				Prompt:  "A new room. Equip the 8♦ (card 1).",
+				Accept:  []Action{play(1)},
+				Outcome: "A new weapon replaces the old one along with its stack, so it can fight any monster again.",
+			},
+			{
+				Prompt:  "Fight the strongest monster first: the 10♣ (card 1), with your weapon.",
+				Accept:  []Action{play(1)},
+				Outcome: "The 8♦ blocked 8 of the 10, so you took 2 damage. Your weapon can now fight monsters up to 10.",
+			},
+			{
+				Prompt:  "Fight the 6♠ (card 1) with your weapon.",
+				Accept:  []Action{play(1)},
+				Outcome: "The 6 is below the 10 your weapon last defeated, so it still works and you took no damage.",
+			},
+		},
+	},
+	{
+		Name:  "potions",
+		Title: "One potion per room",
+		Intro: "Hearts are potions that restore health, up to the starting 20. Only the first potion you drink in each room has any effect.",
+		Outro: "Spread your potions across rooms: a second potion in the same room is wasted.",
+		Deck:  []Card{{Hearts, Five}, {Hearts, Four}, {Clubs, Nine}, {Spades, Three}, {Hearts, Six}, {Clubs, Two}, {Spades, Seven}},
+		Steps: []TutorialStep{
+			{
+				Prompt:  "Health never goes above 20, so take a hit first: fight the 9♣ (card 2).",
+				Accept:  []Action{play(2)},
+				Outcome: "Without a weapon, the 9♣ dealt its full 9 damage.",
+			},
+			{
+				Prompt:  "Drink the 5♥ (card 0).",
+				Accept:  []Action{play(0)},
+				Outcome: "The 5♥ healed 5.",
+			},
+			{
+				Prompt:  "Drink the 4♥ (card 0).",
+				Accept:  []Action{play(0)},
+				Outcome: "Nothing happened: you already drank a potion in this room, so the 4♥ was wasted.",
+			},
+			{
+				Prompt:  "A new room. Fight the 3♠ (card 0).",
+				Accept:  []Action{play(0)},
+				Outcome: "The 3♠ dealt 3 damage.",
+			},
+			{
+				Prompt:  "Drink the 6♥ (card 0).",
+				Accept:  []Action{play(0)},
+				Outcome: "This is the first potion in this room, so the 6♥ healed 6.",
+			},
+			{
+				Prompt:  "Fight the 2♣ (card 0).",
+				Accept:  []Action{play(0)},
+				Outcome: "The 2♣ dealt 2 damage, and with the dungeon empty you escaped.",
+			},
+		},
+	},
+	{
+		Name:  "skipping",
+		Title: "Skipping rooms",
+		Intro: "Before playing any card, you may skip a room: its cards go to the bottom of the dungeon. You cannot skip two rooms in a row.",
+		Outro: "Skipping buys time to find a weapon, but the skipped monsters always come back.",
+		Deck:  []Card{{Spades, Ten}, {Clubs, Nine}, {Spades, Eight}, {Clubs, Seven}, {Diamonds, Nine}, {Clubs, Two}, {Spades, Three}, {Hearts, Five}},
+		Steps: []TutorialStep{
+			{
+				Prompt:  "This room holds 34 damage of monsters and no weapon. Skip it.",
+				Accept:  []Action{skip()},
+				Outcome: "The four monsters went to the bottom of the dungeon. You will meet them again, with a weapon in hand this time.",
+			},
+			{
+				Prompt:  "You cannot skip two rooms in a row, so this one must be played. Equip the 9♦ (card 0).",
+				Accept:  []Action{play(0)},
+				Outcome: "You equipped the 9♦.",
+			},
+			{
+				Prompt:  "Fight the 2♣ (card 0) barehanded, to keep your weapon fresh for the monsters you skipped.",
+				Accept:  []Action{barehanded(0)},
+				Outcome: "You took 2 damage. Had you used the 9♦, it could only have fought monsters up to 2 from then on.",
+			},
+			{
+				Prompt:  "Drink the 5♥ (card 1).",
+				Accept:  []Action{play(1)},
+				Outcome: "You are back to 20 health.",
+			},
+			{
+				Prompt:  "The skipped monsters are back. Fight the 10♠ (card 1) with your weapon.",
+				Accept:  []Action{play(1)},
+				Outcome: "The 9♦ blocked 9 of the 10, so you took 1 damage.",
+			},
+			{
+				Prompt:  "Fight the 9♣ (card 1) with your weapon.",
+				Accept:  []Action{play(1)},
+				Outcome: "No damage: the 9 is below the 10 your weapon last defeated.",
+			},
+			{
+				Prompt:  "Fight the 8♠ (card 1) with your weapon.",
+				Accept:  []Action{play(1)},
+				Outcome: "No damage, and with only one card left in the dungeon you escaped.",
+			},
+		},
+	},
+	{
+		Name:  "scoring",
+		Title: "Scoring",
+		Intro: "The game ends when you die or when the dungeon runs out of cards for a new room. Escaping scores your remaining health. Dying scores minus the total of the monsters left in the dungeon.",
+		Outro: "That's Scoundrel. Try a full dungeon, or the daily challenge.",
+		Deck:  []Card{{Diamonds, Seven}, {Spades, Nine}, {Hearts, Four}, {Hearts, Two}},
+		Steps: []TutorialStep{
+			{
+				Prompt:  "Play the 7♦ (card 0) to equip it.",
+				Accept:  []Action{play(0)},
+				Outcome: "You equipped the 7♦.",
+			},
+			{
+				Prompt:  "Fight the 9♠ (card 0) with your weapon.",
+				Accept:  []Action{play(0)},
+				Outcome: "You took 2 damage and have 18 health.",
+			},
+			{
+				Prompt:  "Finish with the 2♥ (card 1).",
+				Accept:  []Action{play(1)},
+				Outcome: "You escaped with 20 health. Your last card was a potion played at full health, so its value is added too: 20 + 2 = 22.",
+			},
+		},
+	},
+}
+
+// LessonByName returns the tutorial lesson with the given name
+func LessonByName(name string) (*Lesson, bool) {
+	for i := range Lessons {
+		if Lessons[i].Name == name {
+			return &Lessons[i], true
+		}
+	}
+	return nil, false
+}
+
+// newDeck deals a fresh copy of the lesson's dungeon
+func (l *Lesson) newDeck() *Deck {
+	cards := make([]*Card, len(l.Deck))
+	for i, card := range l.Deck {
+		cards[i] = NewCard(card.Suit, card.Rank)
+	}
+	return NewDeckFromCards(cards)
+}
+
+// check returns an error unless the move completes the given step
+func (l *Lesson) check(step int, a Action) error {
+	if step >= len(l.Steps) {
+		return nil
+	}
+	for _, accepted := range l.Steps[step].Accept {
+		if a == accepted || (a.Type == ActionSkip && accepted.Type == ActionSkip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnexpectedMove, l.Steps[step].Prompt)
+}
+
+// tutorialView returns the lesson progress, or nil if this is not a tutorial game
+func (g *GameSession) tutorialView() *TutorialView {
+	if g.lesson == nil {
+		return nil
+	}
+
+	tv := &TutorialView{
+		Lesson: g.lesson.Name,
+		Title:  g.lesson.Title,
+		Intro:  g.lesson.Intro,
+		Step:   g.lessonStep,
+		Steps:  len(g.lesson.Steps),
+	}
+	if g.lessonStep > 0 {
+		tv.Outcome = g.lesson.Steps[g.lessonStep-1].Outcome
+	}
+	if g.lessonStep < len(g.lesson.Steps) {
+		tv.Prompt = g.lesson.Steps[g.lessonStep].Prompt
+	} else {
+		tv.Outro = g.lesson.Outro
+	}
+	return tv
+}
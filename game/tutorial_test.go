@@ -0,0 +1,98 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLessonsPlayThrough(t *testing.T) {
+	// The outcomes each lesson explains depend on these results
+	expectedScores := map[string]int{
+		"weapons":     17,
+		"degradation": 9,
+		"potions":     17,
+		"skipping":    19,
+		"scoring":     22,
+	}
+
+	for _, lesson := range Lessons {
+		session := NewGameSessionWithOptions(SessionOptions{Tutorial: lesson.Name})
+		if !session.IsPractice() {
+			t.Errorf("Expected %s tutorial to be a practice game", lesson.Name)
+		}
+
+		for i, step := range lesson.Steps {
+			if session.IsGameOver() {
+				t.Fatalf("Expected %s lesson to last until step %d, game ended", lesson.Name, i+1)
+			}
+			if err := session.Apply(step.Accept[0]); err != nil {
+				t.Fatalf("Error playing %s step %d (%s): %v", lesson.Name, i+1, step.Accept[0], err)
+			}
+		}
+
+		if session.GetState() != GameStateWon {
+			t.Errorf("Expected %s lesson to end won, got %v", lesson.Name, session.GetState())
+		}
+		if score := session.Score(); score != expectedScores[lesson.Name] {
+			t.Errorf("Expected %s lesson to score %d, got %d", lesson.Name, expectedScores[lesson.Name], score)
+		}
+
+		tv := session.View().Tutorial
+		if tv == nil || tv.Step != len(lesson.Steps) || tv.Prompt != "" || tv.Outro != lesson.Outro {
+			t.Errorf("Expected %s lesson to be complete, got %+v", lesson.Name, tv)
+		}
+	}
+}
+
+func TestTutorialRejectsOtherMoves(t *testing.T) {
+	session := NewGameSessionWithOptions(SessionOptions{Tutorial: "weapons"})
+
+	// The lesson asks to equip the weapon first
+	err := session.Apply(Action{Type: ActionPlay, Index: 1})
+	if !errors.Is(err, ErrUnexpectedMove) {
+		t.Errorf("Expected ErrUnexpectedMove, got %v", err)
+	}
+	if len(session.Actions()) != 0 || session.GetPlayer().Health() != 20 {
+		t.Errorf("Expected a rejected move to leave the game unchanged")
+	}
+
+	tv := session.View().Tutorial
+	if tv.Step != 0 || tv.Prompt != Lessons[0].Steps[0].Prompt || tv.Outcome != "" {
+		t.Errorf("Expected to still be on the first step, got %+v", tv)
+	}
+
+	if err := session.Apply(Action{Type: ActionPlay, Index: 0}); err != nil {
+		t.Fatalf("Error playing expected move: %v", err)
+	}
+	tv = session.View().Tutorial
+	if tv.Step != 1 || tv.Outcome != Lessons[0].Steps[0].Outcome {
+		t.Errorf("Expected the first step's outcome, got %+v", tv)
+	}
+}
+
+func TestTutorialReplay(t *testing.T) {
+	session := NewGameSessionWithOptions(SessionOptions{Tutorial: "skipping"})
+	for _, step := range Lessons[3].Steps[:3] {
+		if err := session.Apply(step.Accept[0]); err != nil {
+			t.Fatalf("Error playing step: %v", err)
+		}
+	}
+
+	rep := session.Replay()
+	if rep.Tutorial != "skipping" {
+		t.Errorf("Expected replay to name the lesson, got %q", rep.Tutorial)
+	}
+
+	rebuilt, err := rep.StateAt(len(rep.Actions))
+	if err != nil {
+		t.Fatalf("Error rebuilding tutorial game: %v", err)
+	}
+	if rebuilt.GetPlayer().Health() != session.GetPlayer().Health() || rebuilt.View().Tutorial.Step != 3 {
+		t.Errorf("Expected rebuilt tutorial to match the original")
+	}
+
+	rep.Tutorial = "missing"
+	if _, err := rep.StateAt(0); err == nil {
+		t.Errorf("Expected an error for an unknown lesson")
+	}
+}
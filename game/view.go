@@ -0,0 +1,122 @@
+package game
+
+// CardView is the public representation of a card
+type CardView struct {
+	Suit    int    `json:"suit"`
+	Rank    int    `json:"rank"`
+	Value   int    `json:"value"`
+	Type    int    `json:"type"`
+	Display string `json:"display"`
+}
+
+// RoomCardView is a card in the current room along with its play index
+type RoomCardView struct {
+	Index int `json:"index"`
+	CardView
+}
+
+// PlayerView is the visible state of the player
+type PlayerView struct {
+	Health           int        `json:"health"`
+	MaxHealth        int        `json:"max_health"`
+	EquippedWeapon   *CardView  `json:"equipped_weapon"`
+	DefeatedMonsters []CardView `json:"defeated_monsters"`
+	UsedPotion       bool       `json:"used_potion"`
+}
+
+// WeaponLimit returns the strongest monster the equipped weapon may fight:
+// the last monster it defeated, or any monster if it has defeated none.
+// It returns 0 without a weapon.
+func (p PlayerView) WeaponLimit() int {
+	if p.EquippedWeapon == nil {
+		return 0
+	}
+	if n := len(p.DefeatedMonsters); n > 0 {
+		return p.DefeatedMonsters[n-1].Value
+	}
+	return int(Ace)
+}
+
+// CanUseWeaponAgainst reports whether the equipped weapon may fight a
+// monster, the rule Player.CanUseWeaponAgainst applies in the game
+func (p PlayerView) CanUseWeaponAgainst(monster CardView) bool {
+	return p.EquippedWeapon != nil && monster.Value <= p.WeaponLimit()
+}
+
+// RoomView is the visible state of the current room
+type RoomView struct {
+	Cards     []RoomCardView `json:"cards"`
+	Completed bool           `json:"completed"`
+}
+
+// DeckView is the visible state of the dungeon deck. Only the number of
+// remaining cards is exposed, never their order.
+type DeckView struct {
+	RemainingCards      int  `json:"remaining_cards"`
+	PreviousRoomSkipped bool `json:"previous_room_skipped"`
+}
+
+// View is the game as the player sees it. It carries no hidden
+// information, so it is safe to hand to spectators.
+type View struct {
+	GameID string     `json:"game_id"`
+	State  string     `json:"state"`
+	Player PlayerView `json:"player"`
+	Room   RoomView   `json:"room"`
+	Deck   DeckView   `json:"deck"`
+	// Tutorial is the lesson progress of a tutorial game
+	Tutorial *TutorialView `json:"tutorial,omitempty"`
+}
+
+// NewCardView converts a card to its public representation
+func NewCardView(card *Card) CardView {
+	return CardView{
+		Suit:    int(card.Suit),
+		Rank:    int(card.Rank),
+		Value:   card.Value(),
+		Type:    int(card.Type()),
+		Display: card.String(),
+	}
+}
+
+// View returns the visible state of the game
+func (g *GameSession) View() View {
+	roomCards := make([]RoomCardView, 0)
+	if g.currentRoom != nil {
+		for i, card := range g.currentRoom.Cards() {
+			roomCards = append(roomCards, RoomCardView{Index: i, CardView: NewCardView(card)})
+		}
+	}
+
+	var equippedWeapon *CardView
+	if weapon := g.player.EquippedWeapon(); weapon != nil {
+		cv := NewCardView(weapon)
+		equippedWeapon = &cv
+	}
+
+	defeatedMonsters := make([]CardView, 0)
+	for _, monster := range g.player.DefeatedMonsters() {
+		defeatedMonsters = append(defeatedMonsters, NewCardView(monster))
+	}
+
+	return View{
+		GameID: g.ID,
+		State:  g.state.String(),
+		Player: PlayerView{
+			Health:           g.player.Health(),
+			MaxHealth:        g.player.MaxHealth(),
+			EquippedWeapon:   equippedWeapon,
+			DefeatedMonsters: defeatedMonsters,
+			UsedPotion:       g.player.UsedPotionThisRoom(),
+		},
+		Room: RoomView{
+			Cards:     roomCards,
+			Completed: g.currentRoom != nil && g.currentRoom.Completed(),
+		},
+		Deck: DeckView{
+			RemainingCards:      g.deck.Remaining(),
+			PreviousRoomSkipped: g.deck.PrevRoomSkipped(),
+		},
+		Tutorial: g.tutorialView(),
+	}
+}
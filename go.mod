@@ -5,4 +5,7 @@ go 1.24.0
 require (
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
+	golang.org/x/term v0.37.0
 )
+
+require golang.org/x/sys v0.38.0 // indirect
@@ -0,0 +1,203 @@
+// Package gym wraps the game engine as a reinforcement learning environment
+// in the style of OpenAI Gym: Reset deals a seeded dungeon, Step makes a
+// move and returns the next observation, a reward and whether the game is
+// over, and ActionMask lists the legal moves. Observations are fixed-size
+// numeric vectors and actions are integers, so the environment can be
+// driven by any trainer, in process or over the JSON-lines server.
+package gym
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Actions are integers in [0, NumActions): playing room card i is action
+// i, fighting monster i barehanded is BarehandedOffset+i, and skipping the
+// room is ActionSkip
+const (
+	RoomSize         = 4
+	BarehandedOffset = RoomSize
+	ActionSkip       = 2 * RoomSize
+	NumActions       = ActionSkip + 1
+)
+
+var (
+	// ErrNotStarted is returned by Step before the first Reset
+	ErrNotStarted = errors.New("environment has not been reset")
+	// ErrGameOver is returned by Step once the game is over
+	ErrGameOver = errors.New("game is over, call reset")
+	// ErrIllegalAction is returned by Step for an action the mask forbids
+	ErrIllegalAction = errors.New("illegal action")
+)
+
+// Options configures an environment
+type Options struct {
+	// Sparse gives the whole reward, the official score, at the end of the
+	// game instead of shaping it over every step
+	Sparse bool
+}
+
+// Info describes the game behind an observation
+type Info struct {
+	Seed  int64  `json:"seed"`
+	State string `json:"state"`
+	Moves int    `json:"moves"`
+	// Score is the official score; it is final once the game is over
+	Score int `json:"score"`
+}
+
+// Step is the outcome of a move
+type Step struct {
+	Observation []float32 `json:"observation"`
+	Reward      float64   `json:"reward"`
+	Done        bool      `json:"done"`
+	ActionMask  []bool    `json:"action_mask"`
+	Info        Info      `json:"info"`
+}
+
+// Env is a Scoundrel environment. It is not safe for concurrent use.
+type Env struct {
+	opts    Options
+	session *game.GameSession
+	// played counts the cards that have left the dungeon, to tell what is
+	// still in it
+	played deckCounts
+}
+
+// New creates an environment. Call Reset before the first Step.
+func New(opts Options) *Env {
+	return &Env{opts: opts}
+}
+
+// Reset starts a new game dealt from seed, or from a random seed if seed is
+// zero, and returns its first observation
+func (e *Env) Reset(seed int64) Step {
+	e.session = game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed, Practice: true})
+	e.played = deckCounts{}
+	return e.step(0)
+}
+
+// Step makes a move and returns the next observation. With shaped rewards
+// (the default), each step is rewarded by the change of health minus the
+// monster value left in the dungeon, and the last step closes the gap to
+// the official score, so the rewards of a game always add up to its score
+// plus a constant: the score of the empty dungeon, 188.
+func (e *Env) Step(action int) (Step, error) {
+	if e.session == nil {
+		return Step{}, ErrNotStarted
+	}
+	if e.session.IsGameOver() {
+		return Step{}, ErrGameOver
+	}
+	view := e.session.View()
+	if action < 0 || action >= NumActions || !e.ActionMask()[action] {
+		return Step{}, fmt.Errorf("%w %d", ErrIllegalAction, action)
+	}
+
+	before := e.potential(view)
+	move := Decode(action)
+	if err := e.session.Apply(move); err != nil {
+		return Step{}, fmt.Errorf("%w %d: %v", ErrIllegalAction, action, err)
+	}
+	if move.Type != game.ActionSkip {
+		e.played.add(view.Room.Cards[move.Index].CardView)
+	}
+
+	reward := 0.0
+	switch {
+	case e.session.IsGameOver():
+		reward = float64(e.session.Score())
+		if !e.opts.Sparse {
+			reward -= before
+		}
+	case !e.opts.Sparse:
+		reward = e.potential(e.session.View()) - before
+	}
+	return e.step(reward), nil
+}
+
+// ActionMask reports which actions are legal in the current state
+func (e *Env) ActionMask() []bool {
+	mask := make([]bool, NumActions)
+	if e.session == nil || e.session.IsGameOver() {
+		return mask
+	}
+	for _, move := range bot.LegalMoves(e.session.View()) {
+		mask[Encode(move)] = true
+	}
+	return mask
+}
+
+// Observation encodes the current state; see ObservationSize for the layout
+func (e *Env) Observation() []float32 {
+	if e.session == nil {
+		return make([]float32, ObservationSize)
+	}
+	return encode(e.session.View(), e.remaining())
+}
+
+// View returns the state of the game as a player sees it
+func (e *Env) View() game.View {
+	if e.session == nil {
+		return game.View{}
+	}
+	return e.session.View()
+}
+
+// step builds the result of the current state
+func (e *Env) step(reward float64) Step {
+	return Step{
+		Observation: e.Observation(),
+		Reward:      reward,
+		Done:        e.session.IsGameOver(),
+		ActionMask:  e.ActionMask(),
+		Info: Info{
+			Seed:  e.session.GetSeed(),
+			State: e.session.GetState().String(),
+			Moves: len(e.session.Actions()),
+			Score: e.session.Score(),
+		},
+	}
+}
+
+// potential is the health minus the monster value still to be faced, the
+// score the game would have if it ended now with nothing else happening
+func (e *Env) potential(view game.View) float64 {
+	return float64(view.Player.Health - (fullDeck.monsterValue - e.played.monsterValue))
+}
+
+// remaining counts the cards left in the dungeon, not counting the room
+func (e *Env) remaining() deckCounts {
+	left := fullDeck.minus(e.played)
+	for _, card := range e.session.View().Room.Cards {
+		left = left.minus(countOf(card.CardView))
+	}
+	return left
+}
+
+// Encode returns the action number of a move
+func Encode(move game.Action) int {
+	switch move.Type {
+	case game.ActionPlayBarehanded:
+		return BarehandedOffset + move.Index
+	case game.ActionSkip:
+		return ActionSkip
+	default:
+		return move.Index
+	}
+}
+
+// Decode returns the move of an action number
+func Decode(action int) game.Action {
+	switch {
+	case action == ActionSkip:
+		return game.Action{Type: game.ActionSkip}
+	case action >= BarehandedOffset:
+		return game.Action{Type: game.ActionPlayBarehanded, Index: action - BarehandedOffset}
+	default:
+		return game.Action{Type: game.ActionPlay, Index: action}
+	}
+}
@@ -0,0 +1,144 @@
+package gym
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+)
+
+func TestActionEncoding(t *testing.T) {
+	for action := 0; action < NumActions; action++ {
+		if got := Encode(Decode(action)); got != action {
+			t.Errorf("Expected action %d to round trip, got %d", action, got)
+		}
+	}
+}
+
+// playGreedy plays a seeded game with the greedy strategy, checking every
+// step, and returns the total reward and the final step
+func playGreedy(t *testing.T, env *Env, seed int64) (float64, Step) {
+	t.Helper()
+	greedy := bot.NewGreedy()
+
+	step := env.Reset(seed)
+	total := 0.0
+	for !step.Done {
+		if len(step.Observation) != ObservationSize || len(step.ActionMask) != NumActions {
+			t.Fatalf("Expected %d observations and %d actions, got %d and %d", ObservationSize, NumActions, len(step.Observation), len(step.ActionMask))
+		}
+		view := env.View()
+		legal := bot.LegalMoves(view)
+		allowed := 0
+		for _, ok := range step.ActionMask {
+			if ok {
+				allowed++
+			}
+		}
+		if allowed != len(legal) {
+			t.Fatalf("Expected %d legal actions, mask allows %d", len(legal), allowed)
+		}
+
+		// The dungeon count is tracked from the cards played
+		left := step.Observation[RoomSize*slotSize+4] * float32(fullDeck.cards)
+		if int(math.Round(float64(left))) != view.Deck.RemainingCards {
+			t.Fatalf("Expected %d cards left, observation has %.1f", view.Deck.RemainingCards, left)
+		}
+
+		var err error
+		step, err = env.Step(Encode(greedy.Move(view)))
+		if err != nil {
+			t.Fatalf("Error stepping: %v", err)
+		}
+		total += step.Reward
+	}
+	return total, step
+}
+
+func TestRewardsAddUpToScore(t *testing.T) {
+	shaped, sparse := New(Options{}), New(Options{Sparse: true})
+	for seed := int64(1); seed <= 20; seed++ {
+		total, last := playGreedy(t, shaped, seed)
+		if total != float64(last.Info.Score+188) {
+			t.Errorf("Seed %d: expected shaped rewards to add up to %d, got %.0f", seed, last.Info.Score+188, total)
+		}
+
+		total, last = playGreedy(t, sparse, seed)
+		if total != float64(last.Info.Score) || last.Reward != total {
+			t.Errorf("Seed %d: expected a single reward of %d, got %.0f in total", seed, last.Info.Score, total)
+		}
+	}
+}
+
+func TestStepErrors(t *testing.T) {
+	env := New(Options{})
+	if _, err := env.Step(0); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("Expected ErrNotStarted, got %v", err)
+	}
+
+	env.Reset(2)
+	// No weapon is equipped, so nothing can be fought barehanded
+	if _, err := env.Step(BarehandedOffset); !errors.Is(err, ErrIllegalAction) {
+		t.Errorf("Expected ErrIllegalAction, got %v", err)
+	}
+	if _, err := env.Step(NumActions); !errors.Is(err, ErrIllegalAction) {
+		t.Errorf("Expected ErrIllegalAction, got %v", err)
+	}
+
+	playGreedy(t, env, 2)
+	if _, err := env.Step(0); !errors.Is(err, ErrGameOver) {
+		t.Errorf("Expected ErrGameOver, got %v", err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	input := strings.Join([]string{
+		`{"cmd":"spec"}`,
+		`{"cmd":"reset","seed":2}`,
+		`{"cmd":"step","action":8}`,
+		`{"cmd":"step","action":8}`,
+		`{"cmd":"dance"}`,
+		`{"cmd":"close"}`,
+		`{"cmd":"spec"}`,
+	}, "\n")
+
+	var out strings.Builder
+	if err := New(Options{}).Serve(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Error serving: %v", err)
+	}
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Error decoding %s: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	// Nothing is answered after close
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 responses, got %d:\n%s", len(lines), out.String())
+	}
+	if lines[0]["num_actions"] != float64(NumActions) {
+		t.Errorf("Expected spec with %d actions, got %v", NumActions, lines[0])
+	}
+	if info := lines[1]["info"].(map[string]any); info["seed"] != float64(2) {
+		t.Errorf("Expected reset to seed 2, got %v", info)
+	}
+	if _, ok := lines[2]["observation"]; !ok {
+		t.Errorf("Expected a step, got %v", lines[2])
+	}
+	// Two rooms in a row cannot be skipped
+	if !strings.Contains(lines[3]["error"].(string), "illegal action") {
+		t.Errorf("Expected an illegal action error, got %v", lines[3])
+	}
+	if lines[4]["error"] == nil {
+		t.Errorf("Expected an unknown command error, got %v", lines[4])
+	}
+}
@@ -0,0 +1,123 @@
+package gym
+
+import (
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Observation layout. Every value is scaled to [0, 1]: card values and
+// weapon limits by 14 (an Ace), health by the maximum health and card
+// counts by the counts in a full dungeon.
+//
+//	[0, 20)  room slots 0-3, 5 values each: present, monster, weapon,
+//	         potion (one-hot type) and card value; all zero for an empty slot
+//	20       health
+//	21       equipped weapon value, 0 without a weapon
+//	22       weapon limit: the strongest monster the weapon may fight,
+//	         1 for a fresh weapon, 0 without a weapon
+//	23       value of the last monster the weapon defeated, 0 if none
+//	24       cards left in the dungeon, not counting the room
+//	25       monsters left in the dungeon
+//	26       weapons left in the dungeon
+//	27       potions left in the dungeon
+//	28       monster value left in the dungeon
+//	29       1 if the room may be skipped
+//	30       1 if a potion was already drunk in this room
+const (
+	slotSize        = 5
+	ObservationSize = RoomSize*slotSize + 11
+)
+
+// maxValue scales card values
+const maxValue = float32(game.Ace)
+
+// deckCounts counts cards by type
+type deckCounts struct {
+	cards, monsters, weapons, potions int
+	monsterValue                      int
+}
+
+// fullDeck counts the cards of a full dungeon
+var fullDeck = func() deckCounts {
+	var counts deckCounts
+	for suit := game.Clubs; suit <= game.Spades; suit++ {
+		for rank := game.Two; rank <= game.Ace; rank++ {
+			card := game.NewCard(suit, rank)
+			if !card.IsRedFaceOrAce() {
+				counts.add(game.NewCardView(card))
+			}
+		}
+	}
+	return counts
+}()
+
+// countOf counts a single card
+func countOf(card game.CardView) deckCounts {
+	var counts deckCounts
+	counts.add(card)
+	return counts
+}
+
+// add counts a card
+func (c *deckCounts) add(card game.CardView) {
+	c.cards++
+	switch game.CardType(card.Type) {
+	case game.Monster:
+		c.monsters++
+		c.monsterValue += card.Value
+	case game.Weapon:
+		c.weapons++
+	case game.Potion:
+		c.potions++
+	}
+}
+
+// minus returns the counts without those of other
+func (c deckCounts) minus(other deckCounts) deckCounts {
+	return deckCounts{
+		cards:        c.cards - other.cards,
+		monsters:     c.monsters - other.monsters,
+		weapons:      c.weapons - other.weapons,
+		potions:      c.potions - other.potions,
+		monsterValue: c.monsterValue - other.monsterValue,
+	}
+}
+
+// encode builds the observation of a state
+func encode(view game.View, left deckCounts) []float32 {
+	obs := make([]float32, ObservationSize)
+
+	for i, card := range view.Room.Cards {
+		if i >= RoomSize {
+			break
+		}
+		slot := obs[i*slotSize : (i+1)*slotSize]
+		slot[0] = 1
+		slot[1+card.Type] = 1
+		slot[4] = float32(card.Value) / maxValue
+	}
+
+	i := RoomSize * slotSize
+	if view.Player.MaxHealth > 0 {
+		obs[i] = float32(view.Player.Health) / float32(view.Player.MaxHealth)
+	}
+	if view.Player.EquippedWeapon != nil {
+		obs[i+1] = float32(view.Player.EquippedWeapon.Value) / maxValue
+		obs[i+2] = float32(view.Player.WeaponLimit()) / maxValue
+		if n := len(view.Player.DefeatedMonsters); n > 0 {
+			obs[i+3] = float32(view.Player.DefeatedMonsters[n-1].Value) / maxValue
+		}
+	}
+	obs[i+4] = float32(left.cards) / float32(fullDeck.cards)
+	obs[i+5] = float32(left.monsters) / float32(fullDeck.monsters)
+	obs[i+6] = float32(left.weapons) / float32(fullDeck.weapons)
+	obs[i+7] = float32(left.potions) / float32(fullDeck.potions)
+	obs[i+8] = float32(left.monsterValue) / float32(fullDeck.monsterValue)
+	if bot.CanSkip(view) {
+		obs[i+9] = 1
+	}
+	if view.Player.UsedPotion {
+		obs[i+10] = 1
+	}
+	return obs
+}
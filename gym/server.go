@@ -0,0 +1,90 @@
+package gym
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Request is a command to the JSON-lines server
+type Request struct {
+	// Cmd is reset, step, spec, view or close
+	Cmd string `json:"cmd"`
+	// Seed is the deal of a reset; zero picks a random seed
+	Seed int64 `json:"seed,omitempty"`
+	// Action is the move of a step
+	Action *int `json:"action,omitempty"`
+}
+
+// Spec describes the observation and action spaces
+type Spec struct {
+	ObservationSize int      `json:"observation_size"`
+	NumActions      int      `json:"num_actions"`
+	Actions         []string `json:"actions"`
+	Sparse          bool     `json:"sparse"`
+}
+
+// errorResponse reports a bad request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// ActionNames names every action, by action number
+func ActionNames() []string {
+	names := make([]string, NumActions)
+	for action := range names {
+		names[action] = Decode(action).String()
+	}
+	return names
+}
+
+// Serve runs the environment as a JSON-lines server: it reads one request
+// per line from r and writes one response per line to w, until a close
+// request or the end of r. reset and step answer with a Step, spec with
+// the Spec, and view with the game as a player sees it. A bad request is
+// answered with {"error": "..."} and the server carries on.
+func (e *Env) Serve(r io.Reader, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var req Request
+		var resp any
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp = errorResponse{Error: "invalid request: " + err.Error()}
+		} else {
+			switch req.Cmd {
+			case "reset":
+				resp = e.Reset(req.Seed)
+			case "step":
+				if req.Action == nil {
+					resp = errorResponse{Error: "step needs an action"}
+					break
+				}
+				step, err := e.Step(*req.Action)
+				if err != nil {
+					resp = errorResponse{Error: err.Error()}
+				} else {
+					resp = step
+				}
+			case "spec":
+				resp = Spec{ObservationSize: ObservationSize, NumActions: NumActions, Actions: ActionNames(), Sparse: e.opts.Sparse}
+			case "view":
+				resp = e.View()
+			case "close":
+				return nil
+			default:
+				resp = errorResponse{Error: fmt.Sprintf("unknown command %q", req.Cmd)}
+			}
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,291 @@
+// Package leaderboard records finished games and ranks them on boards
+package leaderboard
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/internal/jsonfile"
+)
+
+// Board names
+const (
+	BoardAllTime = "all-time"
+	BoardDaily   = "daily"
+	BoardWeekly  = "weekly"
+	BoardSeed    = "seed"
+	// BoardDailyChallenge ranks the games of one daily challenge, all played on the same deal
+	BoardDailyChallenge = "daily-challenge"
+)
+
+var (
+	// ErrPracticeGame is returned when recording a practice game
+	ErrPracticeGame = errors.New("practice games are not eligible for leaderboards")
+	// ErrDuplicateGame is returned when a game has already been recorded
+	ErrDuplicateGame = errors.New("game already recorded")
+	// ErrMissingPlayer is returned when an entry has no player
+	ErrMissingPlayer = errors.New("entry has no player")
+	// ErrUnknownBoard is returned for a board name that does not exist
+	ErrUnknownBoard = errors.New("unknown leaderboard")
+	// ErrAttemptUsed is returned when a player has already attempted a daily challenge
+	ErrAttemptUsed = errors.New("daily challenge already attempted")
+)
+
+// MaxInMemory caps the games a leaderboard without a file keeps. Once it is
+// reached, the oldest game is dropped for each new one.
+const MaxInMemory = 10000
+
+// Entry is a finished game submitted to the leaderboard
+type Entry struct {
+	GameID       string    `json:"game_id"`
+	PlayerID     string    `json:"player_id"`
+	PlayerName   string    `json:"player_name"`
+	Seed         int64     `json:"seed"`
+	Score        int       `json:"score"`
+	Won          bool      `json:"won"`
+	RulePreset   string    `json:"rule_preset"`
+	RoomsCleared int       `json:"rooms_cleared"`
+	DurationMS   int64     `json:"duration_ms"`
+	FinishedAt   time.Time `json:"finished_at"`
+	Practice     bool      `json:"practice,omitempty"`
+	// Challenge is the date (YYYY-MM-DD) of the daily challenge the game was played for
+	Challenge string `json:"challenge,omitempty"`
+}
+
+// Ranked is an entry with its position on a board
+type Ranked struct {
+	Rank int `json:"rank"`
+	Entry
+}
+
+// Query selects and limits the entries of a board
+type Query struct {
+	// Now is the reference time for the daily and weekly boards; zero means time.Now
+	Now time.Time
+	// Seed selects the deal for the per-seed board
+	Seed int64
+	// Challenge selects the date (YYYY-MM-DD) for the daily challenge board; empty means Now's date
+	Challenge string
+	// RulePreset restricts the board to one rule set; empty means all
+	RulePreset string
+	// Limit caps the number of rows; zero means no limit
+	Limit int
+}
+
+// storeData is the on-disk representation of the leaderboard
+type storeData struct {
+	Entries []Entry `json:"entries"`
+	// Attempts maps "date/player ID" to the game of a daily challenge attempt
+	Attempts map[string]string `json:"attempts"`
+}
+
+// Leaderboard stores finished games and ranks them on boards
+type Leaderboard struct {
+	path     string
+	entries  []Entry
+	games    map[string]bool
+	attempts map[string]string
+	mutex    sync.RWMutex
+}
+
+// New opens the leaderboard stored at path. An empty path keeps the
+// leaderboard in memory only, with up to MaxInMemory games and only the
+// daily challenge attempts of the latest date.
+func New(path string) (*Leaderboard, error) {
+	lb := &Leaderboard{
+		path:     path,
+		games:    make(map[string]bool),
+		attempts: make(map[string]string),
+	}
+
+	if path == "" {
+		return lb, nil
+	}
+
+	var data storeData
+	if err := jsonfile.Load(path, &data); err != nil {
+		return nil, err
+	}
+	lb.entries = data.Entries
+	for _, e := range lb.entries {
+		lb.games[e.GameID] = true
+	}
+	for key, gameID := range data.Attempts {
+		lb.attempts[key] = gameID
+	}
+
+	return lb, nil
+}
+
+// Record adds a finished game to the leaderboard. Practice games are rejected.
+func (lb *Leaderboard) Record(e Entry) error {
+	if e.Practice {
+		return ErrPracticeGame
+	}
+	if e.PlayerID == "" {
+		return ErrMissingPlayer
+	}
+
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+
+	if lb.games[e.GameID] {
+		return ErrDuplicateGame
+	}
+
+	lb.entries = append(lb.entries, e)
+	lb.games[e.GameID] = true
+
+	if err := lb.save(); err != nil {
+		lb.entries = lb.entries[:len(lb.entries)-1]
+		delete(lb.games, e.GameID)
+		return err
+	}
+
+	if lb.path == "" {
+		for len(lb.entries) > MaxInMemory {
+			delete(lb.games, lb.entries[0].GameID)
+			lb.entries = lb.entries[1:]
+		}
+	}
+	return nil
+}
+
+// Board returns the ranked rows of a board. Each player appears once, with
+// their best game; ties are broken by the faster game, then the earlier one.
+func (lb *Leaderboard) Board(name string, q Query) ([]Ranked, error) {
+	now := q.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var include func(Entry) bool
+	switch name {
+	case BoardAllTime:
+		include = func(Entry) bool { return true }
+	case BoardDaily:
+		include = func(e Entry) bool { return sameDay(e.FinishedAt, now) }
+	case BoardWeekly:
+		include = func(e Entry) bool { return sameWeek(e.FinishedAt, now) }
+	case BoardSeed:
+		include = func(e Entry) bool { return e.Seed == q.Seed }
+	case BoardDailyChallenge:
+		challenge := q.Challenge
+		if challenge == "" {
+			challenge = now.UTC().Format("2006-01-02")
+		}
+		include = func(e Entry) bool { return e.Challenge == challenge }
+	default:
+		return nil, ErrUnknownBoard
+	}
+
+	lb.mutex.RLock()
+	best := make(map[string]Entry)
+	for _, e := range lb.entries {
+		if !include(e) || (q.RulePreset != "" && e.RulePreset != q.RulePreset) {
+			continue
+		}
+		if current, exists := best[e.PlayerID]; !exists || better(e, current) {
+			best[e.PlayerID] = e
+		}
+	}
+	lb.mutex.RUnlock()
+
+	return rank(best, q.Limit), nil
+}
+
+// ClaimDailyAttempt reserves a player's single scored attempt at the daily
+// challenge of a date for a game. If the player has already attempted that
+// challenge, the existing game ID is returned with ErrAttemptUsed.
+func (lb *Leaderboard) ClaimDailyAttempt(challenge, playerID, gameID string) (string, error) {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+
+	key := challenge + "/" + playerID
+	if existing, exists := lb.attempts[key]; exists {
+		return existing, ErrAttemptUsed
+	}
+
+	lb.attempts[key] = gameID
+	if err := lb.save(); err != nil {
+		delete(lb.attempts, key)
+		return "", err
+	}
+
+	// Earlier challenges can no longer be attempted
+	if lb.path == "" {
+		for k := range lb.attempts {
+			if date, _, _ := strings.Cut(k, "/"); date < challenge {
+				delete(lb.attempts, k)
+			}
+		}
+	}
+	return gameID, nil
+}
+
+// DailyAttempt returns the game of a player's attempt at the daily challenge of a date
+func (lb *Leaderboard) DailyAttempt(challenge, playerID string) (string, bool) {
+	lb.mutex.RLock()
+	defer lb.mutex.RUnlock()
+
+	gameID, exists := lb.attempts[challenge+"/"+playerID]
+	return gameID, exists
+}
+
+// rank orders the best entry of each player and assigns positions
+func rank(best map[string]Entry, limit int) []Ranked {
+	entries := make([]Entry, 0, len(best))
+	for _, e := range best {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return better(entries[i], entries[j])
+	})
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	rows := make([]Ranked, len(entries))
+	for i, e := range entries {
+		rows[i] = Ranked{Rank: i + 1, Entry: e}
+	}
+	return rows
+}
+
+// better reports whether a ranks above b
+func better(a, b Entry) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if a.DurationMS != b.DurationMS {
+		return a.DurationMS < b.DurationMS
+	}
+	return a.FinishedAt.Before(b.FinishedAt)
+}
+
+// sameDay reports whether two times fall on the same UTC day
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// sameWeek reports whether two times fall in the same ISO week
+func sameWeek(a, b time.Time) bool {
+	ay, aw := a.UTC().ISOWeek()
+	by, bw := b.UTC().ISOWeek()
+	return ay == by && aw == bw
+}
+
+// save writes the leaderboard to disk if it has a path.
+// Must be called with the write lock held.
+func (lb *Leaderboard) save() error {
+	if lb.path == "" {
+		return nil
+	}
+	return jsonfile.Save(lb.path, storeData{Entries: lb.entries, Attempts: lb.attempts})
+}
@@ -0,0 +1,174 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBoardRanking(t *testing.T) {
+	lb, _ := New("")
+	now := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{GameID: "g1", PlayerID: "alice", Score: 12, Seed: 1, DurationMS: 5000, FinishedAt: now},
+		{GameID: "g2", PlayerID: "alice", Score: 18, Seed: 2, DurationMS: 9000, FinishedAt: now.Add(-48 * time.Hour)},
+		{GameID: "g3", PlayerID: "bob", Score: 12, Seed: 1, DurationMS: 4000, FinishedAt: now},
+		{GameID: "g4", PlayerID: "carol", Score: -30, Seed: 1, DurationMS: 1000, FinishedAt: now.AddDate(0, -1, 0)},
+	}
+	for _, e := range entries {
+		if err := lb.Record(e); err != nil {
+			t.Fatalf("Error recording %s: %v", e.GameID, err)
+		}
+	}
+
+	// All-time keeps each player's best game
+	rows, _ := lb.Board(BoardAllTime, Query{Now: now})
+	if len(rows) != 3 || rows[0].GameID != "g2" || rows[1].GameID != "g3" || rows[2].GameID != "g4" {
+		t.Errorf("Unexpected all-time board: %+v", rows)
+	}
+
+	// Daily only includes today's games; ties go to the faster game
+	rows, _ = lb.Board(BoardDaily, Query{Now: now})
+	if len(rows) != 2 || rows[0].GameID != "g3" || rows[1].GameID != "g1" {
+		t.Errorf("Unexpected daily board: %+v", rows)
+	}
+
+	// Weekly includes the game from two days ago (same ISO week)
+	rows, _ = lb.Board(BoardWeekly, Query{Now: now})
+	if len(rows) != 2 || rows[0].GameID != "g2" {
+		t.Errorf("Unexpected weekly board: %+v", rows)
+	}
+
+	// Per-seed compares everyone on the same deal
+	rows, _ = lb.Board(BoardSeed, Query{Seed: 1, Limit: 2})
+	if len(rows) != 2 || rows[0].GameID != "g3" || rows[1].Rank != 2 {
+		t.Errorf("Unexpected seed board: %+v", rows)
+	}
+
+	if _, err := lb.Board("monthly", Query{}); !errors.Is(err, ErrUnknownBoard) {
+		t.Errorf("Expected ErrUnknownBoard, got %v", err)
+	}
+}
+
+func TestRecordRejections(t *testing.T) {
+	lb, _ := New("")
+
+	if err := lb.Record(Entry{GameID: "p", PlayerID: "alice", Practice: true}); !errors.Is(err, ErrPracticeGame) {
+		t.Errorf("Expected ErrPracticeGame, got %v", err)
+	}
+	if err := lb.Record(Entry{GameID: "a"}); !errors.Is(err, ErrMissingPlayer) {
+		t.Errorf("Expected ErrMissingPlayer, got %v", err)
+	}
+	if err := lb.Record(Entry{GameID: "g", PlayerID: "alice"}); err != nil {
+		t.Fatalf("Error recording game: %v", err)
+	}
+	if err := lb.Record(Entry{GameID: "g", PlayerID: "alice"}); !errors.Is(err, ErrDuplicateGame) {
+		t.Errorf("Expected ErrDuplicateGame, got %v", err)
+	}
+}
+
+func TestLeaderboardPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leaderboard.json")
+
+	lb, _ := New(path)
+	if err := lb.Record(Entry{GameID: "g", PlayerID: "alice", Score: 20}); err != nil {
+		t.Fatalf("Error recording game: %v", err)
+	}
+
+	reopened, err := New(path)
+	if err != nil {
+		t.Fatalf("Error reopening leaderboard: %v", err)
+	}
+	rows, _ := reopened.Board(BoardAllTime, Query{})
+	if len(rows) != 1 || rows[0].Score != 20 {
+		t.Errorf("Expected recorded game to survive a restart, got %+v", rows)
+	}
+	if err := reopened.Record(Entry{GameID: "g", PlayerID: "alice"}); !errors.Is(err, ErrDuplicateGame) {
+		t.Errorf("Expected duplicate detection after a restart, got %v", err)
+	}
+}
+
+func TestRecordRollsBackFailedSave(t *testing.T) {
+	dir := t.TempDir()
+	lb, err := New(filepath.Join(dir, "leaderboard.json"))
+	if err != nil {
+		t.Fatalf("Error creating leaderboard: %v", err)
+	}
+
+	// A file in place of the data directory makes every save fail
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("Error creating file: %v", err)
+	}
+	lb.path = filepath.Join(blocker, "leaderboard.json")
+
+	if err := lb.Record(Entry{GameID: "g", PlayerID: "alice", Score: 20}); err == nil {
+		t.Fatal("Expected recording to fail when the leaderboard cannot be saved")
+	}
+	if rows, _ := lb.Board(BoardAllTime, Query{}); len(rows) != 0 {
+		t.Errorf("Expected a failed record to leave no entry behind, got %+v", rows)
+	}
+	if _, err := lb.ClaimDailyAttempt("2026-10-18", "alice", "g"); err == nil {
+		t.Fatal("Expected claiming an attempt to fail when the leaderboard cannot be saved")
+	}
+	if _, ok := lb.DailyAttempt("2026-10-18", "alice"); ok {
+		t.Errorf("Expected a failed claim to leave no attempt behind")
+	}
+
+	// The game can be recorded once saving works
+	lb.path = filepath.Join(dir, "leaderboard.json")
+	if err := lb.Record(Entry{GameID: "g", PlayerID: "alice", Score: 20}); err != nil {
+		t.Errorf("Expected recording to succeed after a failed save, got %v", err)
+	}
+}
+
+func TestDailyChallenge(t *testing.T) {
+	lb, _ := New("")
+
+	if _, err := lb.ClaimDailyAttempt("2026-05-20", "alice", "g1"); err != nil {
+		t.Fatalf("Error claiming attempt: %v", err)
+	}
+	existing, err := lb.ClaimDailyAttempt("2026-05-20", "alice", "g2")
+	if !errors.Is(err, ErrAttemptUsed) || existing != "g1" {
+		t.Errorf("Expected second attempt to be rejected with game g1, got %s (%v)", existing, err)
+	}
+	if _, err := lb.ClaimDailyAttempt("2026-05-21", "alice", "g3"); err != nil {
+		t.Errorf("Expected a new attempt on the next day, got %v", err)
+	}
+
+	lb.Record(Entry{GameID: "g1", PlayerID: "alice", Score: 5, Challenge: "2026-05-20"})
+	lb.Record(Entry{GameID: "g4", PlayerID: "bob", Score: 9, Challenge: "2026-05-20"})
+	lb.Record(Entry{GameID: "g5", PlayerID: "bob", Score: 20})
+
+	rows, _ := lb.Board(BoardDailyChallenge, Query{Now: time.Date(2026, 5, 20, 18, 0, 0, 0, time.UTC)})
+	if len(rows) != 2 || rows[0].GameID != "g4" || rows[1].GameID != "g1" {
+		t.Errorf("Unexpected daily challenge board: %+v", rows)
+	}
+}
+
+func TestMemoryLimits(t *testing.T) {
+	lb, _ := New("")
+	for i := 0; i <= MaxInMemory; i++ {
+		if err := lb.Record(Entry{GameID: fmt.Sprintf("g%d", i), PlayerID: fmt.Sprintf("p%d", i), Score: i}); err != nil {
+			t.Fatalf("Error recording game %d: %v", i, err)
+		}
+	}
+	rows, _ := lb.Board(BoardAllTime, Query{})
+	if len(rows) != MaxInMemory || rows[len(rows)-1].GameID != "g1" {
+		t.Errorf("Expected the oldest game to be dropped, got %d rows ending with %+v", len(rows), rows[len(rows)-1])
+	}
+
+	// Attempts at earlier challenges are forgotten
+	lb.ClaimDailyAttempt("2026-05-20", "alice", "a1")
+	lb.ClaimDailyAttempt("2026-05-21", "bob", "b1")
+	if _, ok := lb.DailyAttempt("2026-05-20", "alice"); ok {
+		t.Errorf("Expected the earlier attempt to be forgotten")
+	}
+	if gameID, ok := lb.DailyAttempt("2026-05-21", "bob"); !ok || gameID != "b1" {
+		t.Errorf("Expected today's attempt to be kept, got %q", gameID)
+	}
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextFormat(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounter("requests_total", "Requests.", "code")
+	requests.Inc("200")
+	requests.Add(2, "404")
+	requests.Add(-1, "404") // Counters never decrease
+
+	r.NewGaugeFunc("temperature", "Current temperature.", func() float64 { return 21.5 })
+
+	latency := r.NewHistogram("latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.5, "/a")
+	latency.Observe(3, "/a")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("Error writing metrics: %v", err)
+	}
+	out := b.String()
+
+	expected := []string{
+		"# TYPE requests_total counter",
+		`requests_total{code="200"} 1`,
+		`requests_total{code="404"} 2`,
+		"# TYPE temperature gauge",
+		"temperature 21.5",
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{route="/a",le="0.1"} 1`,
+		`latency_seconds_bucket{route="/a",le="1"} 2`,
+		`latency_seconds_bucket{route="/a",le="+Inf"} 3`,
+		`latency_seconds_sum{route="/a"} 3.55`,
+		`latency_seconds_count{route="/a"} 3`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Expected metrics output to contain %q, got:\n%s", line, out)
+		}
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("odd_total", "Odd labels.", "value").Inc("a \"quoted\"\\path\n")
+
+	var b strings.Builder
+	r.WriteText(&b)
+
+	if !strings.Contains(b.String(), `odd_total{value="a \"quoted\"\\path\n"} 1`) {
+		t.Errorf("Expected label value to be escaped, got:\n%s", b.String())
+	}
+}
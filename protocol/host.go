@@ -0,0 +1,257 @@
+package protocol
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// HandshakeTimeout is how long a bot has to answer the handshake
+const HandshakeTimeout = 10 * time.Second
+
+var (
+	// ErrTimeout is returned when a bot does not answer in time
+	ErrTimeout = errors.New("bot did not answer in time")
+	// ErrClosed is returned when a bot closes its end of the connection
+	ErrClosed = errors.New("bot closed the connection")
+)
+
+// Bot is the host's end of a connection to a bot speaking the protocol
+type Bot struct {
+	// Name is the name the bot gave in the handshake, if any
+	Name string
+
+	w         io.Writer
+	lines     chan string
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBot connects to a bot that reads the host's messages from w and writes
+// its replies to r. Call Close when done with the bot.
+func NewBot(r io.Reader, w io.Writer) *Bot {
+	b := &Bot{w: w, lines: make(chan string), done: make(chan struct{})}
+
+	// Read replies in the background, so that waiting for one can time out
+	go func() {
+		defer close(b.lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case b.lines <- strings.TrimSpace(scanner.Text()):
+			case <-b.done:
+				return
+			}
+		}
+	}()
+
+	return b
+}
+
+// Close stops reading the bot's replies. It does not close r or w, so a
+// reader blocked on r returns only once r does.
+func (b *Bot) Close() error {
+	b.closeOnce.Do(func() { close(b.done) })
+	return nil
+}
+
+// Handshake announces the protocol version and waits for the bot to be ready
+func (b *Bot) Handshake(ctx context.Context) error {
+	if err := b.send(fmt.Sprintf("scoundrel %d", Version)); err != nil {
+		return err
+	}
+
+	for {
+		line, err := b.readLine(ctx, HandshakeTimeout)
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "ready":
+			return nil
+		case strings.HasPrefix(line, "name "):
+			b.Name = strings.TrimSpace(strings.TrimPrefix(line, "name "))
+		}
+	}
+}
+
+// Move sends the visible state and waits up to timeout for the bot's move.
+// A zero timeout waits forever.
+func (b *Bot) Move(ctx context.Context, view game.View, timeout time.Duration) (game.Action, error) {
+	// A bot that stopped reading has closed the connection
+	if err := writeState(b.w, view); err != nil {
+		return game.Action{}, ErrClosed
+	}
+
+	// Start the clock once the state is sent
+	deadline := time.Time{}
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		remaining := time.Duration(0)
+		if !deadline.IsZero() {
+			if remaining = time.Until(deadline); remaining <= 0 {
+				return game.Action{}, ErrTimeout
+			}
+		}
+		line, err := b.readLine(ctx, remaining)
+		if err != nil {
+			return game.Action{}, err
+		}
+		if move, ok := strings.CutPrefix(line, "move "); ok {
+			return ParseMove(move)
+		}
+	}
+}
+
+// Quit tells the bot that no more games follow
+func (b *Bot) Quit() error {
+	return b.send("quit")
+}
+
+// send writes a line to the bot
+func (b *Bot) send(line string) error {
+	_, err := io.WriteString(b.w, line+"\n")
+	return err
+}
+
+// readLine returns the next reply of the bot other than blank and info
+// lines, waiting up to timeout; a zero timeout waits forever
+func (b *Bot) readLine(ctx context.Context, timeout time.Duration) (string, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case line, ok := <-b.lines:
+			if !ok {
+				return "", ErrClosed
+			}
+			if line == "" || line == "info" || strings.HasPrefix(line, "info ") {
+				continue
+			}
+			return line, nil
+		case <-b.done:
+			return "", ErrClosed
+		case <-expired:
+			return "", ErrTimeout
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// Result is the outcome of a hosted game
+type Result struct {
+	// State is the final state of the game, Won or Lost
+	State string `json:"state"`
+	Score int    `json:"score"`
+	Moves int    `json:"moves"`
+	// Forfeit is why the bot lost by forfeit, or "" if it played to the end.
+	// A bot forfeits by making an illegal move, by running out of time or
+	// by closing the connection.
+	Forfeit string `json:"forfeit,omitempty"`
+}
+
+// Fatal reports whether the bot forfeited by timing out or closing the
+// connection, after which it cannot be trusted to be in step with the host
+func (r Result) Fatal() bool {
+	return r.Forfeit == ErrTimeout.Error() || r.Forfeit == ErrClosed.Error()
+}
+
+// Host plays a game with the bot until it is over, giving the bot up to
+// moveTime for each move; zero means no limit. A bot that forfeits loses
+// the game, which scores the monsters left in the dungeon. Host returns an
+// error if the game cannot be announced to the bot or if ctx is done.
+func Host(ctx context.Context, session *game.GameSession, b *Bot, moveTime time.Duration) (Result, error) {
+	result := Result{}
+	if err := b.send("newgame"); err != nil {
+		return result, fmt.Errorf("announcing new game: %w", err)
+	}
+
+	for !session.IsGameOver() {
+		action, err := b.Move(ctx, session.View(), moveTime)
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if err == nil {
+			if err = session.Apply(action); err != nil {
+				b.send("illegal " + err.Error())
+			}
+		}
+		if err != nil {
+			result.Forfeit = err.Error()
+			session.Resign()
+			break
+		}
+		result.Moves++
+	}
+
+	result.State = session.GetState().String()
+	result.Score = session.Score()
+	if result.Forfeit != "" {
+		b.send(fmt.Sprintf("result forfeit %d %s", result.Score, result.Forfeit))
+	} else {
+		b.send(fmt.Sprintf("result %s %d", strings.ToLower(result.State), result.Score))
+	}
+	return result, nil
+}
+
+// Process is a bot running as a child process, speaking the protocol over
+// its stdin and stdout
+type Process struct {
+	*Bot
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// Launch starts the executable at path as a bot. Its stderr is passed
+// through, so bots can log there.
+func Launch(path string, args ...string) (*Process, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &Process{Bot: NewBot(stdout, stdin), cmd: cmd, stdin: stdin}, nil
+}
+
+// Close tells the bot to quit and waits briefly for it to exit, killing it
+// if it does not
+func (p *Process) Close() error {
+	p.Quit()
+	p.Bot.Close()
+	p.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- p.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Second):
+		p.cmd.Process.Kill()
+		return <-done
+	}
+}
@@ -0,0 +1,233 @@
+// Package protocol implements the Scoundrel engine protocol, a line-oriented
+// text protocol over stdin and stdout that lets bots written in any language
+// play against the game engine. The host owns the game: it sends the visible
+// state, and the bot replies with a move. See docs/protocol.md for the spec.
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Version is the protocol version announced by the host
+const Version = 1
+
+var (
+	rankCodes = map[game.Rank]string{
+		game.Jack: "J", game.Queen: "Q", game.King: "K", game.Ace: "A",
+	}
+	suitCodes = [...]string{"C", "D", "H", "S"}
+)
+
+// FormatCard returns the protocol notation of a card: its rank (2-10, J, Q,
+// K or A) followed by its suit (C, D, H or S), e.g. "10S" or "QC"
+func FormatCard(card game.CardView) (string, error) {
+	if card.Suit < 0 || card.Suit >= len(suitCodes) {
+		return "", fmt.Errorf("card %q has unknown suit %d", card.Display, card.Suit)
+	}
+	rank, ok := rankCodes[game.Rank(card.Rank)]
+	if !ok {
+		rank = strconv.Itoa(card.Rank)
+	}
+	return rank + suitCodes[card.Suit], nil
+}
+
+// FormatVisible returns the protocol notation of the cards a player sees:
+// the equipped weapon and the last monster it defeated, or none if there
+// are none, and the cards of the room
+func FormatVisible(view game.View, none string) (weapon, last string, room []string, err error) {
+	weapon, last = none, none
+	if view.Player.EquippedWeapon != nil {
+		if weapon, err = FormatCard(*view.Player.EquippedWeapon); err != nil {
+			return "", "", nil, err
+		}
+		if n := len(view.Player.DefeatedMonsters); n > 0 {
+			if last, err = FormatCard(view.Player.DefeatedMonsters[n-1]); err != nil {
+				return "", "", nil, err
+			}
+		}
+	}
+	room = make([]string, len(view.Room.Cards))
+	for i, card := range view.Room.Cards {
+		if room[i], err = FormatCard(card.CardView); err != nil {
+			return "", "", nil, err
+		}
+	}
+	return weapon, last, room, nil
+}
+
+// ParseCard parses a card in protocol notation
+func ParseCard(s string) (game.CardView, error) {
+	s = strings.ToUpper(s)
+	if len(s) < 2 {
+		return game.CardView{}, fmt.Errorf("invalid card %q", s)
+	}
+	rankCode, suitCode := s[:len(s)-1], s[len(s)-1:]
+
+	suit := -1
+	for i, code := range suitCodes {
+		if code == suitCode {
+			suit = i
+		}
+	}
+	rank := 0
+	for r, code := range rankCodes {
+		if code == rankCode {
+			rank = int(r)
+		}
+	}
+	if rank == 0 {
+		// Only 2 to 10 are written as numbers
+		if n, err := strconv.Atoi(rankCode); err == nil && n <= int(game.Ten) {
+			rank = n
+		}
+	}
+	if suit < 0 || rank < int(game.Two) || rank > int(game.Ace) {
+		return game.CardView{}, fmt.Errorf("invalid card %q", s)
+	}
+	return game.NewCardView(game.NewCard(game.Suit(suit), game.Rank(rank))), nil
+}
+
+// FormatMove returns the protocol notation of a move: "play N",
+// "play N barehanded" or "skip"
+func FormatMove(a game.Action) string {
+	switch a.Type {
+	case game.ActionPlayBarehanded:
+		return fmt.Sprintf("play %d barehanded", a.Index)
+	case game.ActionSkip:
+		return "skip"
+	default:
+		return fmt.Sprintf("play %d", a.Index)
+	}
+}
+
+// ParseMove parses a move in protocol notation
+func ParseMove(s string) (game.Action, error) {
+	fields := strings.Fields(s)
+	switch {
+	case len(fields) == 1 && fields[0] == "skip":
+		return game.Action{Type: game.ActionSkip}, nil
+	case (len(fields) == 2 || len(fields) == 3) && fields[0] == "play":
+		index, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return game.Action{}, fmt.Errorf("invalid card index %q", fields[1])
+		}
+		if len(fields) == 2 {
+			return game.Action{Type: game.ActionPlay, Index: index}, nil
+		}
+		if fields[2] == "barehanded" {
+			return game.Action{Type: game.ActionPlayBarehanded, Index: index}, nil
+		}
+	}
+	return game.Action{}, fmt.Errorf("invalid move %q", s)
+}
+
+// writeState sends the visible state of a game as a state block, ending
+// with "go"
+func writeState(w io.Writer, view game.View) error {
+	weapon, last, room, err := FormatVisible(view, "none")
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("state\n")
+	fmt.Fprintf(&b, "health %d %d\n", view.Player.Health, view.Player.MaxHealth)
+	fmt.Fprintf(&b, "weapon %s\n", weapon)
+	fmt.Fprintf(&b, "last %s\n", last)
+	fmt.Fprintf(&b, "deck %d\n", view.Deck.RemainingCards)
+	fmt.Fprintf(&b, "room %s\n", strings.Join(room, " "))
+	fmt.Fprintf(&b, "canskip %d\n", flag(bot.CanSkip(view)))
+	fmt.Fprintf(&b, "potion %d\n", flag(view.Player.UsedPotion))
+	b.WriteString("go\n")
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// parseState rebuilds the visible state from the lines of a state block,
+// without "state" and "go". Only the last monster the weapon defeated is
+// known.
+func parseState(lines []string) (game.View, error) {
+	view := game.View{
+		State: game.GameStateInProgress.String(),
+		Player: game.PlayerView{
+			DefeatedMonsters: []game.CardView{},
+		},
+		Room: game.RoomView{Cards: []game.RoomCardView{}},
+	}
+	canSkip := false
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		args := fields[1:]
+
+		var err error
+		switch fields[0] {
+		case "health":
+			if len(args) != 2 {
+				return view, fmt.Errorf("invalid line %q", line)
+			}
+			if view.Player.Health, err = strconv.Atoi(args[0]); err == nil {
+				view.Player.MaxHealth, err = strconv.Atoi(args[1])
+			}
+		case "weapon", "last":
+			if len(args) != 1 || args[0] == "none" {
+				break
+			}
+			var card game.CardView
+			if card, err = ParseCard(args[0]); err != nil {
+				break
+			}
+			if fields[0] == "weapon" {
+				view.Player.EquippedWeapon = &card
+			} else {
+				view.Player.DefeatedMonsters = append(view.Player.DefeatedMonsters, card)
+			}
+		case "deck":
+			if len(args) != 1 {
+				return view, fmt.Errorf("invalid line %q", line)
+			}
+			view.Deck.RemainingCards, err = strconv.Atoi(args[0])
+		case "room":
+			for i, arg := range args {
+				var card game.CardView
+				if card, err = ParseCard(arg); err != nil {
+					break
+				}
+				view.Room.Cards = append(view.Room.Cards, game.RoomCardView{Index: i, CardView: card})
+			}
+		case "canskip":
+			canSkip = len(args) == 1 && args[0] == "1"
+		case "potion":
+			view.Player.UsedPotion = len(args) == 1 && args[0] == "1"
+		}
+		// Unknown lines are ignored, so later versions can add fields
+		if err != nil {
+			return view, fmt.Errorf("invalid line %q: %w", line, err)
+		}
+	}
+
+	// A full room that cannot be skipped follows a skipped room
+	view.Deck.PreviousRoomSkipped = !canSkip && len(view.Room.Cards) == 4
+	if len(view.Room.Cards) == 0 {
+		return view, errors.New("state has no room")
+	}
+	return view, nil
+}
+
+// flag encodes a boolean as 0 or 1
+func flag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
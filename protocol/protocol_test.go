@@ -0,0 +1,271 @@
+package protocol
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestCardNotation(t *testing.T) {
+	for suit := game.Clubs; suit <= game.Spades; suit++ {
+		for rank := game.Two; rank <= game.Ace; rank++ {
+			card := game.NewCardView(game.NewCard(suit, rank))
+			s, err := FormatCard(card)
+			if err != nil {
+				t.Fatalf("Error formatting %v: %v", card, err)
+			}
+			parsed, err := ParseCard(s)
+			if err != nil {
+				t.Fatalf("Error parsing %s: %v", s, err)
+			}
+			if parsed != card {
+				t.Errorf("Expected %v, got %v", card, parsed)
+			}
+		}
+	}
+
+	if got, _ := FormatCard(game.NewCardView(game.NewCard(game.Spades, game.Ten))); got != "10S" {
+		t.Errorf("Expected 10S, got %s", got)
+	}
+	for _, suit := range []int{-1, 4} {
+		if _, err := FormatCard(game.CardView{Suit: suit, Rank: 10}); err == nil {
+			t.Errorf("Expected error formatting a card with suit %d", suit)
+		}
+	}
+	for _, bad := range []string{"", "S", "1S", "11S", "KX", "10"} {
+		if _, err := ParseCard(bad); err == nil {
+			t.Errorf("Expected error parsing %q", bad)
+		}
+	}
+}
+
+func TestMoveNotation(t *testing.T) {
+	moves := []game.Action{
+		{Type: game.ActionPlay, Index: 2},
+		{Type: game.ActionPlayBarehanded, Index: 0},
+		{Type: game.ActionSkip},
+	}
+	for _, move := range moves {
+		parsed, err := ParseMove(FormatMove(move))
+		if err != nil {
+			t.Fatalf("Error parsing %s: %v", FormatMove(move), err)
+		}
+		if parsed != move {
+			t.Errorf("Expected %v, got %v", move, parsed)
+		}
+	}
+
+	for _, bad := range []string{"", "play", "play x", "play 1 sideways", "skip 1"} {
+		if _, err := ParseMove(bad); err == nil {
+			t.Errorf("Expected error parsing %q", bad)
+		}
+	}
+}
+
+// connect starts a bot on the other end of a pair of pipes
+func connect(t *testing.T, serve func(r io.Reader, w io.Writer)) *Bot {
+	t.Helper()
+	hostReader, botWriter := io.Pipe()
+	botReader, hostWriter := io.Pipe()
+	go func() {
+		serve(botReader, botWriter)
+		botWriter.Close()
+	}()
+	t.Cleanup(func() { hostWriter.Close() })
+
+	b := NewBot(hostReader, hostWriter)
+	t.Cleanup(func() { b.Close() })
+	if err := b.Handshake(context.Background()); err != nil {
+		t.Fatalf("Error in handshake: %v", err)
+	}
+	return b
+}
+
+func TestHostMatchesInProcessPlay(t *testing.T) {
+	greedy := bot.NewGreedy()
+	b := connect(t, func(r io.Reader, w io.Writer) { Serve(r, w, greedy) })
+	if b.Name != "greedy" {
+		t.Errorf("Expected bot name greedy, got %q", b.Name)
+	}
+
+	// The protocol carries everything the strategy looks at, so the bot
+	// must play exactly as it does in process
+	for seed := int64(1); seed <= 20; seed++ {
+		session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed})
+		result, err := Host(context.Background(), session, b, time.Second)
+		if err != nil {
+			t.Fatalf("Error hosting seed %d: %v", seed, err)
+		}
+		if result.Forfeit != "" {
+			t.Fatalf("Expected no forfeit for seed %d, got %q", seed, result.Forfeit)
+		}
+
+		local := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed})
+		if err := bot.Play(local, greedy); err != nil {
+			t.Fatalf("Error playing seed %d: %v", seed, err)
+		}
+		if result.Score != local.Score() || result.Moves != len(local.Actions()) {
+			t.Errorf("Seed %d: expected score %d in %d moves, got %d in %d",
+				seed, local.Score(), len(local.Actions()), result.Score, result.Moves)
+		}
+	}
+}
+
+// scripted is a bot that answers the handshake and then every state with
+// the same reply, recording the host's messages
+func scripted(reply string, received chan<- string) func(r io.Reader, w io.Writer) {
+	return func(r io.Reader, w io.Writer) {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if received != nil {
+				received <- line
+			}
+			switch {
+			case strings.HasPrefix(line, "scoundrel"):
+				fmt.Fprintln(w, "info starting up")
+				fmt.Fprintln(w, "ready")
+			case line == "go" && reply != "":
+				fmt.Fprintln(w, reply)
+			}
+		}
+	}
+}
+
+func TestHostForfeits(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		forfeit string
+		fatal   bool
+	}{
+		{"timeout", "", ErrTimeout.Error(), true},
+		{"illegal move", "move play 7", "invalid card index", false},
+		{"bad move", "move dance", "invalid move", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := make(chan string, 100)
+			b := connect(t, scripted(tt.reply, received))
+
+			session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: 1})
+			result, err := Host(context.Background(), session, b, 50*time.Millisecond)
+			if err != nil {
+				t.Fatalf("Error hosting: %v", err)
+			}
+			if !strings.Contains(result.Forfeit, tt.forfeit) {
+				t.Errorf("Expected forfeit %q, got %q", tt.forfeit, result.Forfeit)
+			}
+			if result.Fatal() != tt.fatal {
+				t.Errorf("Expected Fatal() %v, got %v", tt.fatal, result.Fatal())
+			}
+			if result.State != game.GameStateLost.String() || result.Score != session.Score() || result.Score >= 0 {
+				t.Errorf("Expected a lost game with its losing score, got %+v", result)
+			}
+
+			// The bot is told it forfeited
+			want := fmt.Sprintf("result forfeit %d ", result.Score)
+			deadline := time.After(time.Second)
+			for {
+				select {
+				case line := <-received:
+					if strings.HasPrefix(line, want) {
+						return
+					}
+				case <-deadline:
+					t.Fatalf("Expected the bot to receive %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestBotCloseStopsReading(t *testing.T) {
+	hostReader, botWriter := io.Pipe()
+	defer hostReader.Close()
+	go func() {
+		// A chatty bot keeps writing after the host has stopped listening
+		for {
+			if _, err := fmt.Fprintln(botWriter, "info thinking"); err != nil {
+				return
+			}
+		}
+	}()
+
+	b := NewBot(hostReader, io.Discard)
+	b.Close()
+	if _, err := b.readLine(context.Background(), time.Second); err != ErrClosed {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+
+	// The reader closes lines once it has stopped
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-b.lines:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Expected the reader to stop after Close")
+		}
+	}
+}
+
+// failingWriter is a connection the host cannot write to
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestHostReportsFailedNewGame(t *testing.T) {
+	b := NewBot(strings.NewReader(""), failingWriter{})
+	defer b.Close()
+
+	session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: 1})
+	if _, err := Host(context.Background(), session, b, time.Second); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Expected the failed newgame write to be returned, got %v", err)
+	}
+}
+
+func TestLaunch(t *testing.T) {
+	t.Setenv("SCOUNDREL_PROTOCOL_BOT", "1")
+
+	p, err := Launch(os.Args[0], "-test.run=TestHelperBot")
+	if err != nil {
+		t.Fatalf("Error launching bot: %v", err)
+	}
+	defer p.Close()
+
+	if err := p.Handshake(context.Background()); err != nil {
+		t.Fatalf("Error in handshake: %v", err)
+	}
+	session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: 7})
+	result, err := Host(context.Background(), session, p.Bot, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Error hosting: %v", err)
+	}
+	if result.Forfeit != "" || !session.IsGameOver() {
+		t.Errorf("Expected the game to be played to the end, got %+v", result)
+	}
+}
+
+// TestHelperBot is the bot launched by TestLaunch
+func TestHelperBot(t *testing.T) {
+	if os.Getenv("SCOUNDREL_PROTOCOL_BOT") != "1" {
+		return
+	}
+	Serve(os.Stdin, os.Stdout, bot.NewGreedy())
+	os.Exit(0)
+}
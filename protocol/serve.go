@@ -0,0 +1,61 @@
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+)
+
+// Serve is the bot's end of the protocol: it reads the host's messages from
+// r and answers on w with the moves chosen by s, until the host quits or
+// closes the connection
+func Serve(r io.Reader, w io.Writer, s bot.Strategy) error {
+	scanner := bufio.NewScanner(r)
+	send := func(format string, args ...any) error {
+		_, err := fmt.Fprintf(w, format+"\n", args...)
+		return err
+	}
+
+	var state []string
+	inState := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[0] == "scoundrel":
+			if line != fmt.Sprintf("scoundrel %d", Version) {
+				return fmt.Errorf("unsupported protocol %q", line)
+			}
+			if err := send("name %s", s.Name()); err != nil {
+				return err
+			}
+			if err := send("ready"); err != nil {
+				return err
+			}
+		case fields[0] == "state":
+			state, inState = state[:0], true
+		case fields[0] == "go" && inState:
+			inState = false
+			view, err := parseState(state)
+			if err != nil {
+				return err
+			}
+			if err := send("move %s", FormatMove(s.Move(view))); err != nil {
+				return err
+			}
+		case inState:
+			state = append(state, line)
+		case fields[0] == "quit":
+			return nil
+		}
+		// newgame, illegal and result need no answer
+	}
+	return scanner.Err()
+}
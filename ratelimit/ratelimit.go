@@ -0,0 +1,88 @@
+// Package ratelimit implements token-bucket rate limiting per key
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often buckets that have refilled completely are dropped
+const pruneInterval = time.Minute
+
+// bucket holds the tokens left for one key
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter allows each key a burst of requests, refilled at a steady rate
+type Limiter struct {
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+// New creates a limiter allowing rate requests per second per key, with
+// bursts of up to burst requests
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token for key. If none is left it reports how long until one is.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill for the time since the last request
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// Len returns the number of keys being tracked
+func (l *Limiter) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.buckets)
+}
+
+// prune drops the buckets that would be full by now, since a fresh bucket
+// behaves the same. Must be called with the lock held.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+	l.lastPrune = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
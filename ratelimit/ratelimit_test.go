@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func newTestLimiter(rate float64, burst int) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := New(rate, burst)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestBurstThenRefill(t *testing.T) {
+	l, clock := newTestLimiter(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("Expected request %d of the burst to be allowed", i+1)
+		}
+	}
+
+	ok, wait := l.Allow("a")
+	if ok {
+		t.Fatal("Expected request beyond the burst to be throttled")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("Expected wait of at most 1s, got %s", wait)
+	}
+
+	// Other keys have their own bucket
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("Expected another key to be allowed")
+	}
+
+	clock.t = clock.t.Add(time.Second)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("Expected a token to be refilled after 1s")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("Expected only one token to be refilled after 1s")
+	}
+}
+
+func TestPruneFullBuckets(t *testing.T) {
+	l, clock := newTestLimiter(1, 2)
+
+	l.Allow("a")
+	l.Allow("b")
+	if n := l.Len(); n != 2 {
+		t.Fatalf("Expected 2 tracked keys, got %d", n)
+	}
+
+	clock.t = clock.t.Add(2 * pruneInterval)
+	l.Allow("c")
+	if n := l.Len(); n != 1 {
+		t.Errorf("Expected refilled buckets to be pruned, got %d keys", n)
+	}
+}
@@ -0,0 +1,143 @@
+// Package replay stores the records of finished games
+package replay
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/internal/jsonfile"
+)
+
+// ErrNotFound is returned when no replay exists for a game
+var ErrNotFound = errors.New("replay not found")
+
+// MaxInMemory caps the replays a store without a directory keeps. Once it
+// is reached, the oldest replay is dropped for each new one.
+const MaxInMemory = 10000
+
+// idPattern restricts game IDs used as file names
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// Store keeps one replay per finished game, as a JSON file per game in a
+// directory, or in memory if it has no directory
+type Store struct {
+	dir     string
+	replays map[string]game.Replay
+	// order holds the IDs of the replays in memory, oldest first
+	order []string
+	mutex sync.RWMutex
+}
+
+// NewStore opens the replay store in dir. An empty dir keeps replays in
+// memory only, up to MaxInMemory of them.
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:     dir,
+		replays: make(map[string]game.Replay),
+	}
+}
+
+// Save stores the replay of a game, replacing any earlier one
+func (s *Store) Save(r game.Replay) error {
+	if !idPattern.MatchString(r.GameID) {
+		return errors.New("invalid game ID")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.dir == "" {
+		if _, exists := s.replays[r.GameID]; !exists {
+			s.order = append(s.order, r.GameID)
+		}
+		s.replays[r.GameID] = r
+		for len(s.order) > MaxInMemory {
+			delete(s.replays, s.order[0])
+			s.order = s.order[1:]
+		}
+		return nil
+	}
+
+	return jsonfile.Save(s.path(r.GameID), r)
+}
+
+// Get returns the replay of a game
+func (s *Store) Get(id string) (game.Replay, error) {
+	if !idPattern.MatchString(id) {
+		return game.Replay{}, ErrNotFound
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.dir == "" {
+		r, exists := s.replays[id]
+		if !exists {
+			return game.Replay{}, ErrNotFound
+		}
+		return r, nil
+	}
+
+	return LoadFile(s.path(id))
+}
+
+// List returns every stored replay, oldest first
+func (s *Store) List() ([]game.Replay, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var replays []game.Replay
+	if s.dir == "" {
+		for _, r := range s.replays {
+			replays = append(replays, r)
+		}
+	} else {
+		paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			r, err := LoadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			replays = append(replays, r)
+		}
+	}
+
+	sort.Slice(replays, func(i, j int) bool {
+		if !replays[i].FinishedAt.Equal(replays[j].FinishedAt) {
+			return replays[i].FinishedAt.Before(replays[j].FinishedAt)
+		}
+		return replays[i].GameID < replays[j].GameID
+	})
+	return replays, nil
+}
+
+// path returns the file holding a game's replay
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// LoadFile reads a replay from a JSON file
+func LoadFile(path string) (game.Replay, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return game.Replay{}, ErrNotFound
+	}
+
+	var r game.Replay
+	if err := jsonfile.Load(path, &r); err != nil {
+		return game.Replay{}, err
+	}
+	return r, nil
+}
+
+// SaveFile writes a replay to a JSON file
+func SaveFile(path string, r game.Replay) error {
+	return jsonfile.Save(path, r)
+}
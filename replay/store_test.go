@@ -0,0 +1,70 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+func TestStore(t *testing.T) {
+	for _, dir := range []string{"", t.TempDir()} {
+		store := NewStore(dir)
+
+		session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: 5})
+		session.SkipRoom()
+		session.PlayCard(0)
+
+		if err := store.Save(session.Replay()); err != nil {
+			t.Fatalf("Error saving replay (dir %q): %v", dir, err)
+		}
+
+		rep, err := store.Get(session.GetID())
+		if err != nil {
+			t.Fatalf("Error loading replay (dir %q): %v", dir, err)
+		}
+		if rep.Seed != 5 || len(rep.Actions) != 2 || rep.Actions[0].Type != game.ActionSkip {
+			t.Errorf("Unexpected replay (dir %q): %+v", dir, rep)
+		}
+
+		replays, err := store.List()
+		if err != nil {
+			t.Fatalf("Error listing replays (dir %q): %v", dir, err)
+		}
+		if len(replays) != 1 || replays[0].GameID != session.GetID() {
+			t.Errorf("Expected the saved replay to be listed (dir %q), got %+v", dir, replays)
+		}
+
+		if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound (dir %q), got %v", dir, err)
+		}
+		if _, err := store.Get("../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected path traversal to be rejected (dir %q), got %v", dir, err)
+		}
+	}
+}
+
+func TestStoreKeepsLatestInMemory(t *testing.T) {
+	store := NewStore("")
+	for i := 0; i <= MaxInMemory; i++ {
+		if err := store.Save(game.Replay{GameID: fmt.Sprintf("g%d", i), Seed: 1}); err != nil {
+			t.Fatalf("Error saving replay %d: %v", i, err)
+		}
+	}
+
+	// Saving a game again does not count twice
+	if err := store.Save(game.Replay{GameID: "g1", Seed: 2}); err != nil {
+		t.Fatalf("Error saving replay: %v", err)
+	}
+
+	if _, err := store.Get("g0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the oldest replay to be dropped, got %v", err)
+	}
+	if rep, err := store.Get("g1"); err != nil || rep.Seed != 2 {
+		t.Errorf("Expected the resaved replay to be kept, got %+v (%v)", rep, err)
+	}
+	if replays, _ := store.List(); len(replays) != MaxInMemory {
+		t.Errorf("Expected %d replays in memory, got %d", MaxInMemory, len(replays))
+	}
+}
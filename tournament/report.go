@@ -0,0 +1,274 @@
+package tournament
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+// z is the normal quantile of the 95% confidence intervals
+const z = 1.96
+
+// Interval is a 95% confidence interval
+type Interval struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// Standing is a player's line in the ranked table
+type Standing struct {
+	Rank     int     `json:"rank"`
+	Player   string  `json:"player"`
+	Games    int     `json:"games"`
+	Wins     int     `json:"wins"`
+	Forfeits int     `json:"forfeits"`
+	WinRate  float64 `json:"win_rate"`
+	// WinRateCI is the Wilson score interval of the win rate
+	WinRateCI   Interval `json:"win_rate_ci"`
+	MeanScore   float64  `json:"mean_score"`
+	MeanScoreCI Interval `json:"mean_score_ci"`
+}
+
+// Matchup compares two players seed by seed. Since both played the same
+// deals, the per-seed score differences are paired, which makes the
+// comparison much sharper than comparing mean scores.
+type Matchup struct {
+	Player   string `json:"player"`
+	Opponent string `json:"opponent"`
+	// Wins, Losses and Ties count the seeds on which Player scored higher
+	// than, lower than or the same as Opponent
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+	// MeanDiff is the mean of Player's score minus Opponent's
+	MeanDiff   float64  `json:"mean_diff"`
+	MeanDiffCI Interval `json:"mean_diff_ci"`
+	// Significant is set when the interval of MeanDiff excludes zero
+	Significant bool `json:"significant"`
+}
+
+// SeedResult is the game every player played on one seed
+type SeedResult struct {
+	Seed    int64                      `json:"seed"`
+	Results map[string]protocol.Result `json:"results"`
+}
+
+// Report is the outcome of a tournament
+type Report struct {
+	Seeds []int64 `json:"seeds"`
+	// Standings are ranked by mean score, then win rate
+	Standings []Standing `json:"standings"`
+	// HeadToHead compares every pair of players, the higher ranked first
+	HeadToHead []Matchup    `json:"head_to_head"`
+	Games      []SeedResult `json:"games"`
+}
+
+// newReport computes the report from results[player][seed]
+func newReport(names []string, seeds []int64, results [][]protocol.Result) *Report {
+	report := &Report{Seeds: seeds}
+
+	for i, name := range names {
+		scores := make([]float64, len(seeds))
+		st := Standing{Player: name, Games: len(seeds)}
+		for j, result := range results[i] {
+			scores[j] = float64(result.Score)
+			if result.State == game.GameStateWon.String() {
+				st.Wins++
+			}
+			if result.Forfeit != "" {
+				st.Forfeits++
+			}
+		}
+		st.WinRate = float64(st.Wins) / float64(st.Games)
+		st.WinRateCI = wilson(st.Wins, st.Games)
+		st.MeanScore, st.MeanScoreCI = meanCI(scores)
+		report.Standings = append(report.Standings, st)
+	}
+
+	// Rank by mean score, then win rate
+	order := make([]int, len(names))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := report.Standings[order[a]], report.Standings[order[b]]
+		if sa.MeanScore != sb.MeanScore {
+			return sa.MeanScore > sb.MeanScore
+		}
+		return sa.WinRate > sb.WinRate
+	})
+	ranked := make([]Standing, len(order))
+	for rank, i := range order {
+		ranked[rank] = report.Standings[i]
+		ranked[rank].Rank = rank + 1
+	}
+	report.Standings = ranked
+
+	for a := 0; a < len(order); a++ {
+		for b := a + 1; b < len(order); b++ {
+			report.HeadToHead = append(report.HeadToHead, matchup(names[order[a]], names[order[b]], results[order[a]], results[order[b]]))
+		}
+	}
+
+	for j, seed := range seeds {
+		sr := SeedResult{Seed: seed, Results: make(map[string]protocol.Result, len(names))}
+		for i, name := range names {
+			sr.Results[name] = results[i][j]
+		}
+		report.Games = append(report.Games, sr)
+	}
+	return report
+}
+
+// matchup compares the results of two players on the same seeds
+func matchup(player, opponent string, a, b []protocol.Result) Matchup {
+	m := Matchup{Player: player, Opponent: opponent}
+	diffs := make([]float64, len(a))
+	for j := range a {
+		diff := a[j].Score - b[j].Score
+		switch {
+		case diff > 0:
+			m.Wins++
+		case diff < 0:
+			m.Losses++
+		default:
+			m.Ties++
+		}
+		diffs[j] = float64(diff)
+	}
+	m.MeanDiff, m.MeanDiffCI = meanCI(diffs)
+	m.Significant = m.MeanDiffCI.Low > 0 || m.MeanDiffCI.High < 0
+	return m
+}
+
+// meanCI returns the mean of xs and its normal-approximation interval
+func meanCI(xs []float64) (float64, Interval) {
+	n := float64(len(xs))
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= n
+
+	if len(xs) < 2 {
+		return mean, Interval{Low: mean, High: mean}
+	}
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= n - 1
+	half := z * math.Sqrt(variance/n)
+	return mean, Interval{Low: mean - half, High: mean + half}
+}
+
+// wilson returns the Wilson score interval of wins out of n
+func wilson(wins, n int) Interval {
+	p, fn := float64(wins)/float64(n), float64(n)
+	denominator := 1 + z*z/fn
+	center := (p + z*z/(2*fn)) / denominator
+	half := z * math.Sqrt(p*(1-p)/fn+z*z/(4*fn*fn)) / denominator
+	return Interval{Low: math.Max(0, center-half), High: math.Min(1, center+half)}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as Markdown tables: the standings, the
+// head-to-head comparisons and every seed's scores
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# Tournament\n\n")
+	fmt.Fprintf(&b, "%d players on %d seeds (%s). Intervals are 95%% confidence intervals.\n\n", len(r.Standings), len(r.Seeds), formatSeeds(r.Seeds))
+
+	b.WriteString("## Standings\n\n")
+	b.WriteString("| Rank | Player | Games | Wins | Win rate | Mean score | Forfeits |\n")
+	b.WriteString("|-----:|--------|------:|-----:|----------|------------|---------:|\n")
+	for _, st := range r.Standings {
+		fmt.Fprintf(&b, "| %d | %s | %d | %d | %.1f%% (%.1f%% to %.1f%%) | %.1f (%.1f to %.1f) | %d |\n",
+			st.Rank, st.Player, st.Games, st.Wins,
+			100*st.WinRate, 100*st.WinRateCI.Low, 100*st.WinRateCI.High,
+			st.MeanScore, st.MeanScoreCI.Low, st.MeanScoreCI.High, st.Forfeits)
+	}
+
+	if len(r.HeadToHead) > 0 {
+		b.WriteString("\n## Head to head\n\n")
+		b.WriteString("Seeds won, lost and tied by the player against the opponent on the same deal, and the mean score difference. Differences marked * are significant.\n\n")
+		b.WriteString("| Player | Opponent | Won | Lost | Tied | Mean difference |\n")
+		b.WriteString("|--------|----------|----:|-----:|-----:|-----------------|\n")
+		for _, m := range r.HeadToHead {
+			mark := ""
+			if m.Significant {
+				mark = " *"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %d | %d | %d | %+.1f (%+.1f to %+.1f)%s |\n",
+				m.Player, m.Opponent, m.Wins, m.Losses, m.Ties, m.MeanDiff, m.MeanDiffCI.Low, m.MeanDiffCI.High, mark)
+		}
+	}
+
+	b.WriteString("\n## Scores by seed\n\n")
+	b.WriteString("The best score on each seed is in bold; W marks a win and F a forfeit.\n\n")
+	b.WriteString("| Seed |")
+	for _, st := range r.Standings {
+		fmt.Fprintf(&b, " %s |", st.Player)
+	}
+	b.WriteString("\n|-----:|")
+	for range r.Standings {
+		b.WriteString("-----:|")
+	}
+	b.WriteString("\n")
+	for _, sr := range r.Games {
+		best := math.MinInt
+		for _, result := range sr.Results {
+			best = max(best, result.Score)
+		}
+		fmt.Fprintf(&b, "| %d |", sr.Seed)
+		for _, st := range r.Standings {
+			result := sr.Results[st.Player]
+			cell := fmt.Sprint(result.Score)
+			if result.Score == best && len(r.Standings) > 1 {
+				cell = "**" + cell + "**"
+			}
+			switch {
+			case result.Forfeit != "":
+				cell += " F"
+			case result.State == game.GameStateWon.String():
+				cell += " W"
+			}
+			fmt.Fprintf(&b, " %s |", cell)
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatSeeds lists seeds compactly, joining consecutive ones into ranges
+func formatSeeds(seeds []int64) string {
+	var parts []string
+	for i := 0; i < len(seeds); {
+		j := i
+		for j+1 < len(seeds) && seeds[j+1] == seeds[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", seeds[i], seeds[j]))
+		} else {
+			parts = append(parts, fmt.Sprint(seeds[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,230 @@
+// Package tournament plays bots on a fixed set of seeds, so every bot gets
+// the same deals, and compares them with confidence intervals and per-seed
+// head-to-head results
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+// Player is a contestant in a tournament
+type Player interface {
+	// Name identifies the player in the report
+	Name() string
+	// Play plays a game until it is over
+	Play(ctx context.Context, session *game.GameSession) (protocol.Result, error)
+}
+
+// strategyPlayer plays a built-in strategy in process
+type strategyPlayer struct {
+	s bot.Strategy
+}
+
+// NewStrategyPlayer returns a player for a built-in strategy
+func NewStrategyPlayer(s bot.Strategy) Player {
+	return &strategyPlayer{s: s}
+}
+
+// Name returns the name of the strategy
+func (p *strategyPlayer) Name() string {
+	return p.s.Name()
+}
+
+// Play makes the strategy's moves. An illegal move forfeits the game, as it
+// would over the engine protocol.
+func (p *strategyPlayer) Play(ctx context.Context, session *game.GameSession) (protocol.Result, error) {
+	result := protocol.Result{}
+	for !session.IsGameOver() {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := session.Apply(p.s.Move(session.View())); err != nil {
+			result.Forfeit = err.Error()
+			session.Resign()
+			break
+		}
+		result.Moves++
+	}
+	result.State = session.GetState().String()
+	result.Score = session.Score()
+	return result, nil
+}
+
+// externalPlayer plays an executable speaking the engine protocol
+type externalPlayer struct {
+	name     string
+	command  []string
+	moveTime time.Duration
+	proc     *protocol.Process
+}
+
+// NewExternalPlayer launches command, an executable and its arguments, as a
+// bot speaking the engine protocol with moveTime for each move. The player
+// is named after the name the bot gives in the handshake, or the command.
+// A bot that times out or crashes forfeits that game and is relaunched for
+// the next one. Close stops it.
+func NewExternalPlayer(ctx context.Context, command []string, moveTime time.Duration) (Player, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("empty bot command")
+	}
+	p := &externalPlayer{command: command, moveTime: moveTime}
+	if err := p.launch(ctx); err != nil {
+		return nil, err
+	}
+	p.name = p.proc.Name
+	if p.name == "" {
+		p.name = strings.Join(command, " ")
+	}
+	return p, nil
+}
+
+// launch starts the bot and waits for its handshake
+func (p *externalPlayer) launch(ctx context.Context) error {
+	proc, err := protocol.Launch(p.command[0], p.command[1:]...)
+	if err != nil {
+		return fmt.Errorf("launching %s: %w", p.command[0], err)
+	}
+	if err := proc.Handshake(ctx); err != nil {
+		proc.Close()
+		return fmt.Errorf("starting %s: %w", p.command[0], err)
+	}
+	p.proc = proc
+	return nil
+}
+
+// Name returns the bot's name
+func (p *externalPlayer) Name() string {
+	return p.name
+}
+
+// Play hosts a game for the bot
+func (p *externalPlayer) Play(ctx context.Context, session *game.GameSession) (protocol.Result, error) {
+	if p.proc == nil {
+		if err := p.launch(ctx); err != nil {
+			return protocol.Result{}, err
+		}
+	}
+	result, err := protocol.Host(ctx, session, p.proc.Bot, p.moveTime)
+	// A bot that could not be reached is restarted for the next game
+	if err != nil || result.Fatal() {
+		p.proc.Close()
+		p.proc = nil
+	}
+	return result, err
+}
+
+// Close stops the bot
+func (p *externalPlayer) Close() error {
+	if p.proc == nil {
+		return nil
+	}
+	err := p.proc.Close()
+	p.proc = nil
+	return err
+}
+
+// Run plays every player on every seed and reports the results. Players
+// play in parallel, each going through the seeds in order.
+func Run(ctx context.Context, players []Player, seeds []int64) (*Report, error) {
+	if len(players) == 0 || len(seeds) == 0 {
+		return nil, fmt.Errorf("a tournament needs players and seeds")
+	}
+
+	results := make([][]protocol.Result, len(players))
+	errs := make([]error, len(players))
+	var wg sync.WaitGroup
+	for i, player := range players {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = make([]protocol.Result, len(seeds))
+			for j, seed := range seeds {
+				session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seed, Practice: true})
+				result, err := player.Play(ctx, session)
+				if err != nil {
+					errs[i] = fmt.Errorf("%s on seed %d: %w", player.Name(), seed, err)
+					return
+				}
+				results[i][j] = result
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Tell apart players with the same name
+	names := make([]string, len(players))
+	seen := make(map[string]int)
+	for i, player := range players {
+		names[i] = player.Name()
+		if seen[names[i]]++; seen[names[i]] > 1 {
+			names[i] = fmt.Sprintf("%s #%d", names[i], seen[names[i]])
+		}
+	}
+	return newReport(names, seeds, results), nil
+}
+
+// MaxSeeds caps the number of seeds ParseSeeds expands a list to
+const MaxSeeds = 1000000
+
+// ParseSeeds parses a list of seeds and seed ranges separated by commas,
+// e.g. "1-100", "7,42,1000-1009" or "-10--1". Seeds may be negative but not
+// zero, and the list may hold at most MaxSeeds seeds.
+func ParseSeeds(s string) ([]int64, error) {
+	var seeds []int64
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		// A leading minus is a sign, any other one separates a range
+		from, to, isRange := part, "", false
+		if i := strings.Index(part[1:], "-"); i >= 0 {
+			from, to, isRange = part[:i+1], part[i+2:], true
+		}
+		first, err := strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed %q", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.ParseInt(to, 10, 64); err != nil || last < first {
+				return nil, fmt.Errorf("invalid seed range %q", part)
+			}
+		}
+
+		// Counted without overflow, since the range may span all of int64
+		if n := uint64(last) - uint64(first); n >= uint64(MaxSeeds-len(seeds)) {
+			return nil, fmt.Errorf("too many seeds in %q, at most %d are allowed", s, MaxSeeds)
+		}
+		for seed := first; ; seed++ {
+			// Zero means a random seed to the engine
+			if seed == 0 {
+				return nil, fmt.Errorf("seed 0 is not a fixed seed")
+			}
+			seeds = append(seeds, seed)
+			if seed == last {
+				break
+			}
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("no seeds in %q", s)
+	}
+	return seeds, nil
+}
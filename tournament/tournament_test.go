@@ -0,0 +1,158 @@
+package tournament
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+	"github.com/tippi-fifestarr/scoundrel/protocol"
+)
+
+func TestParseSeeds(t *testing.T) {
+	seeds, err := ParseSeeds("7, 42,1000-1003")
+	if err != nil {
+		t.Fatalf("Error parsing seeds: %v", err)
+	}
+	want := []int64{7, 42, 1000, 1001, 1002, 1003}
+	if len(seeds) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, seeds)
+	}
+	for i := range want {
+		if seeds[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, seeds)
+		}
+	}
+	if got := formatSeeds(seeds); got != "7, 42, 1000-1003" {
+		t.Errorf("Expected seeds formatted as 7, 42, 1000-1003, got %s", got)
+	}
+
+	seeds, err = ParseSeeds("-3--2,-1,5")
+	if err != nil {
+		t.Fatalf("Error parsing negative seeds: %v", err)
+	}
+	if got := formatSeeds(seeds); got != "-3--1, 5" {
+		t.Errorf("Expected seeds -3--1, 5, got %s", got)
+	}
+	if seeds, err := ParseSeeds("9223372036854775806-9223372036854775807"); err != nil || len(seeds) != 2 {
+		t.Errorf("Expected the last two seeds, got %v (%v)", seeds, err)
+	}
+
+	for _, bad := range []string{"", "x", "5-2", "0-3", "1-x", "-2-2", "--1", "1-1000000000", "1-999999,1-2", "-9223372036854775808-9223372036854775807"} {
+		if _, err := ParseSeeds(bad); err == nil {
+			t.Errorf("Expected error parsing %q", bad)
+		}
+	}
+}
+
+func TestWilson(t *testing.T) {
+	// 10 wins out of 100 has a Wilson interval of about 5.5% to 17.4%
+	ci := wilson(10, 100)
+	if math.Abs(ci.Low-0.0552) > 0.001 || math.Abs(ci.High-0.1744) > 0.001 {
+		t.Errorf("Expected interval 0.0552 to 0.1744, got %.4f to %.4f", ci.Low, ci.High)
+	}
+
+	ci = wilson(0, 20)
+	if ci.Low != 0 || ci.High <= 0 {
+		t.Errorf("Expected interval from 0 with no wins, got %.4f to %.4f", ci.Low, ci.High)
+	}
+}
+
+// forfeiter makes an illegal move every game
+type forfeiter struct{}
+
+func (forfeiter) Name() string { return "forfeiter" }
+func (forfeiter) Move(view game.View) game.Action {
+	return game.Action{Type: game.ActionPlay, Index: 9}
+}
+
+func TestRun(t *testing.T) {
+	seeds, _ := ParseSeeds("1-30")
+	players := []Player{
+		NewStrategyPlayer(bot.NewRandom(1)),
+		NewStrategyPlayer(bot.NewGreedy()),
+		NewStrategyPlayer(forfeiter{}),
+		NewStrategyPlayer(bot.NewGreedy()),
+	}
+
+	report, err := Run(context.Background(), players, seeds)
+	if err != nil {
+		t.Fatalf("Error running tournament: %v", err)
+	}
+
+	if len(report.Standings) != 4 {
+		t.Fatalf("Expected 4 standings, got %d", len(report.Standings))
+	}
+	first, last := report.Standings[0], report.Standings[3]
+	if !strings.HasPrefix(first.Player, "greedy") || first.Rank != 1 {
+		t.Errorf("Expected greedy to rank first, got %+v", first)
+	}
+	if last.Player != "forfeiter" || last.Forfeits != 30 || last.Wins != 0 {
+		t.Errorf("Expected forfeiter last with 30 forfeits, got %+v", last)
+	}
+	for _, st := range report.Standings {
+		if st.MeanScoreCI.Low > st.MeanScore || st.MeanScoreCI.High < st.MeanScore {
+			t.Errorf("Expected %s's mean score inside its interval, got %+v", st.Player, st)
+		}
+	}
+
+	// Identical players tie on every seed
+	if len(report.HeadToHead) != 6 {
+		t.Fatalf("Expected 6 matchups, got %d", len(report.HeadToHead))
+	}
+	m := report.HeadToHead[0]
+	if m.Ties != 30 || m.MeanDiff != 0 || m.Significant {
+		t.Errorf("Expected the two greedy players to tie, got %+v", m)
+	}
+	for _, m := range report.HeadToHead {
+		if m.Wins+m.Losses+m.Ties != 30 {
+			t.Errorf("Expected 30 seeds in %s vs %s, got %+v", m.Player, m.Opponent, m)
+		}
+		if m.Opponent == "forfeiter" && (!m.Significant || m.MeanDiff <= 0) {
+			t.Errorf("Expected %s to beat forfeiter significantly, got %+v", m.Player, m)
+		}
+	}
+
+	// Every player played the same deal on each seed
+	for _, sr := range report.Games {
+		session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: sr.Seed})
+		bot.Play(session, bot.NewGreedy())
+		if sr.Results["greedy"].Score != session.Score() || sr.Results["greedy #2"].Score != session.Score() {
+			t.Errorf("Seed %d: expected greedy to score %d, got %+v", sr.Seed, session.Score(), sr.Results)
+		}
+	}
+}
+
+func TestReportOutput(t *testing.T) {
+	seeds := []int64{1, 2}
+	report := newReport([]string{"a", "b"}, seeds, [][]protocol.Result{
+		{{State: "Won", Score: 5}, {State: "Lost", Score: -30}},
+		{{State: "Lost", Score: -10}, {State: "Lost", Score: -20, Forfeit: "bot did not answer in time"}},
+	})
+
+	var md bytes.Buffer
+	if err := report.WriteMarkdown(&md); err != nil {
+		t.Fatalf("Error writing Markdown: %v", err)
+	}
+	for _, want := range []string{"## Standings", "## Head to head", "| 1 | a | 2 | 1 |", "| 1 | **5** W | -10 |", "| 2 | -30 | **-20** F |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, md.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil {
+		t.Fatalf("Error writing JSON: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Error decoding JSON: %v", err)
+	}
+	if len(decoded.Standings) != 2 || decoded.Games[1].Results["b"].Forfeit == "" {
+		t.Errorf("Expected the report to round trip, got %+v", decoded)
+	}
+}
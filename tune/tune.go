@@ -0,0 +1,262 @@
+// Package tune optimises the weights of the heuristic player with a genetic
+// algorithm. Every candidate plays the same seeded games, so candidates are
+// compared on the same deals, and its fitness is its mean score.
+package tune
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+	"github.com/tippi-fifestarr/scoundrel/game"
+)
+
+// Param is a weight the tuner searches, within its range
+type Param struct {
+	Name     string
+	Min, Max float64
+	field    func(w *bot.Weights) *float64
+}
+
+// Params are the weights the tuner searches
+var Params = []Param{
+	{"weapon_value", 0, 3, func(w *bot.Weights) *float64 { return &w.WeaponValue }},
+	{"degradation", 0, 2, func(w *bot.Weights) *float64 { return &w.Degradation }},
+	{"potion_waste", 0, 2, func(w *bot.Weights) *float64 { return &w.PotionWaste }},
+	{"leftover_monster", 0, 3, func(w *bot.Weights) *float64 { return &w.LeftoverMonster }},
+	{"leftover_weapon", -1, 2, func(w *bot.Weights) *float64 { return &w.LeftoverWeapon }},
+	{"leftover_potion", -1, 2, func(w *bot.Weights) *float64 { return &w.LeftoverPotion }},
+	{"skip_threshold", 0, 20, func(w *bot.Weights) *float64 { return &w.SkipThreshold }},
+}
+
+// vector returns the weights in the order of Params
+func vector(w bot.Weights) []float64 {
+	v := make([]float64, len(Params))
+	for i, p := range Params {
+		v[i] = *p.field(&w)
+	}
+	return v
+}
+
+// weights builds weights from a vector in the order of Params, clamped to
+// their ranges
+func weights(v []float64) bot.Weights {
+	var w bot.Weights
+	for i, p := range Params {
+		*p.field(&w) = min(p.Max, max(p.Min, v[i]))
+	}
+	return w
+}
+
+// Fitness returns the mean score of a heuristic player with the weights
+// over the seeds, playing them on workers goroutines (one per CPU if
+// workers is zero)
+func Fitness(ctx context.Context, w bot.Weights, seeds []int64, workers int) (float64, error) {
+	if len(seeds) == 0 {
+		return 0, errors.New("no seeds to play")
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	scores := make([]int, len(seeds))
+	errs := make([]error, len(seeds))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(seeds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			player := bot.NewHeuristic(w)
+			for i := range next {
+				session := game.NewGameSessionWithOptions(game.SessionOptions{Seed: seeds[i], Practice: true})
+				errs[i] = bot.Play(session, player)
+				scores[i] = session.Score()
+			}
+		}()
+	}
+	for i := range seeds {
+		if ctx.Err() != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, score := range scores {
+		total += score
+	}
+	return float64(total) / float64(len(seeds)), nil
+}
+
+// Options configure a tuning run
+type Options struct {
+	// Seeds are the games every candidate plays
+	Seeds []int64
+	// Population is the number of candidates per generation (default 24)
+	Population int
+	// Generations is the number of generations bred (default 30)
+	Generations int
+	// Elite is the number of best candidates kept unchanged in the next
+	// generation (default 2)
+	Elite int
+	// MutationRate is the chance of mutating each weight of a child
+	// (default 0.3)
+	MutationRate float64
+	// MutationScale is the standard deviation of a mutation, as a share of
+	// the weight's range (default 0.1)
+	MutationScale float64
+	// Seed drives the random choices of the search; runs with the same
+	// options and seed give the same result
+	Seed int64
+	// Workers is the number of games played at once (default: one per CPU)
+	Workers int
+	// Progress, if set, is called after each generation
+	Progress func(Generation)
+}
+
+// withDefaults fills in the options left at zero
+func (o Options) withDefaults() Options {
+	if o.Population <= 0 {
+		o.Population = 24
+	}
+	if o.Generations <= 0 {
+		o.Generations = 30
+	}
+	if o.Elite <= 0 {
+		o.Elite = 2
+	}
+	o.Elite = min(o.Elite, o.Population)
+	if o.MutationRate <= 0 {
+		o.MutationRate = 0.3
+	}
+	if o.MutationScale <= 0 {
+		o.MutationScale = 0.1
+	}
+	return o
+}
+
+// Generation reports the fitness of a generation
+type Generation struct {
+	Number      int
+	Best        bot.Weights
+	BestFitness float64
+	MeanFitness float64
+}
+
+// candidate is a member of the population
+type candidate struct {
+	genes   []float64
+	fitness float64
+}
+
+// Run searches for the weights with the best fitness, starting from a
+// population around start, and returns the best weights found and their
+// fitness. The start weights stay in the first population, so the result
+// is never worse than them on the seeds.
+func Run(ctx context.Context, start bot.Weights, opts Options) (bot.Weights, float64, error) {
+	opts = opts.withDefaults()
+	if len(opts.Seeds) == 0 {
+		return bot.Weights{}, 0, errors.New("no seeds to play")
+	}
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	population := make([]candidate, opts.Population)
+	population[0].genes = vector(weights(vector(start)))
+	for i := 1; i < len(population); i++ {
+		population[i].genes = mutate(rng, population[0].genes, 1, opts.MutationScale*3)
+	}
+	if err := evaluate(ctx, population, opts); err != nil {
+		return bot.Weights{}, 0, err
+	}
+
+	for gen := 1; ; gen++ {
+		sort.SliceStable(population, func(i, j int) bool { return population[i].fitness > population[j].fitness })
+		if opts.Progress != nil {
+			mean := 0.0
+			for _, c := range population {
+				mean += c.fitness
+			}
+			opts.Progress(Generation{
+				Number:      gen,
+				Best:        weights(population[0].genes),
+				BestFitness: population[0].fitness,
+				MeanFitness: mean / float64(len(population)),
+			})
+		}
+		if gen == opts.Generations {
+			break
+		}
+
+		// The elite survive as they are; the rest are bred from the
+		// current generation
+		next := make([]candidate, len(population))
+		copy(next, population[:opts.Elite])
+		for i := opts.Elite; i < len(next); i++ {
+			a, b := selectParent(rng, population), selectParent(rng, population)
+			next[i].genes = mutate(rng, crossover(rng, a.genes, b.genes), opts.MutationRate, opts.MutationScale)
+		}
+		if err := evaluate(ctx, next[opts.Elite:], opts); err != nil {
+			return bot.Weights{}, 0, err
+		}
+		population = next
+	}
+	return weights(population[0].genes), population[0].fitness, nil
+}
+
+// evaluate measures the fitness of candidates
+func evaluate(ctx context.Context, candidates []candidate, opts Options) error {
+	for i := range candidates {
+		fitness, err := Fitness(ctx, weights(candidates[i].genes), opts.Seeds, opts.Workers)
+		if err != nil {
+			return err
+		}
+		candidates[i].fitness = fitness
+	}
+	return nil
+}
+
+// selectParent picks the fittest of three random candidates
+func selectParent(rng *rand.Rand, population []candidate) candidate {
+	best := population[rng.Intn(len(population))]
+	for range 2 {
+		if c := population[rng.Intn(len(population))]; c.fitness > best.fitness {
+			best = c
+		}
+	}
+	return best
+}
+
+// crossover mixes two parents, each weight a random blend of theirs
+func crossover(rng *rand.Rand, a, b []float64) []float64 {
+	child := make([]float64, len(a))
+	for i := range child {
+		child[i] = a[i] + rng.Float64()*(b[i]-a[i])
+	}
+	return child
+}
+
+// mutate returns a copy of genes with each weight, at the given rate,
+// moved by a normal step of the given share of its range, and clamped
+func mutate(rng *rand.Rand, genes []float64, rate, scale float64) []float64 {
+	mutated := make([]float64, len(genes))
+	for i, p := range Params {
+		mutated[i] = genes[i]
+		if rng.Float64() < rate {
+			mutated[i] += rng.NormFloat64() * scale * (p.Max - p.Min)
+		}
+	}
+	return vector(weights(mutated))
+}
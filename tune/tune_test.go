@@ -0,0 +1,72 @@
+package tune
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tippi-fifestarr/scoundrel/bot"
+)
+
+func TestWeightsVector(t *testing.T) {
+	w := bot.Weights{WeaponValue: 1, Degradation: 0.5, LeftoverPotion: -0.5, SkipThreshold: 4}
+	if got := weights(vector(w)); got != w {
+		t.Errorf("Expected %+v to round trip, got %+v", w, got)
+	}
+
+	// Weights are clamped to their ranges
+	v := vector(w)
+	v[0], v[len(v)-1] = -5, 100
+	got := weights(v)
+	if got.WeaponValue != Params[0].Min || got.SkipThreshold != Params[len(Params)-1].Max {
+		t.Errorf("Expected weights clamped to their ranges, got %+v", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	seeds := make([]int64, 60)
+	for i := range seeds {
+		seeds[i] = int64(i + 1)
+	}
+	opts := Options{Seeds: seeds, Population: 6, Generations: 3, Seed: 7, Workers: 2}
+
+	start, err := Fitness(ctx, bot.GreedyWeights, seeds, 2)
+	if err != nil {
+		t.Fatalf("Error measuring fitness: %v", err)
+	}
+
+	generations := 0
+	opts.Progress = func(g Generation) {
+		generations++
+		if g.BestFitness < g.MeanFitness {
+			t.Errorf("Generation %d: expected the best above the mean, got %.2f and %.2f", g.Number, g.BestFitness, g.MeanFitness)
+		}
+	}
+	tuned, fitness, err := Run(ctx, bot.GreedyWeights, opts)
+	if err != nil {
+		t.Fatalf("Error tuning: %v", err)
+	}
+	if generations != 3 {
+		t.Errorf("Expected 3 generations, got %d", generations)
+	}
+	// The start weights are in the first population and the best survive
+	if fitness < start {
+		t.Errorf("Expected at least the start fitness %.2f, got %.2f", start, fitness)
+	}
+	if measured, _ := Fitness(ctx, tuned, seeds, 1); measured != fitness {
+		t.Errorf("Expected the tuned weights to score %.2f again, got %.2f", fitness, measured)
+	}
+
+	// The same seed gives the same result
+	opts.Progress = nil
+	again, _, _ := Run(ctx, bot.GreedyWeights, opts)
+	if again != tuned {
+		t.Errorf("Expected the same weights from the same seed, got %+v and %+v", tuned, again)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := Run(cancelled, bot.GreedyWeights, opts); err == nil {
+		t.Errorf("Expected an error once cancelled")
+	}
+}
@@ -0,0 +1,9 @@
+// Package web holds the browser frontend, embedded into the server binary
+package web
+
+import "embed"
+
+// Files is the frontend: the HTML pages and their css and js directories
+//
+//go:embed *.html css js
+var Files embed.FS
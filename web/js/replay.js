@@ -0,0 +1,148 @@
+// Scoundrel Game - Replay Viewer
+
+// Replay endpoints
+const REPLAY_API = {
+    REPLAY: '/api/replays/{id}',
+    STEP: '/api/replays/{id}/step/{step}'
+};
+
+// Card type icons, indexed by card type
+const CARD_ICONS = ['👾', '⚔️', '⚗️'];
+const CARD_CLASSES = ['card-monster', 'card-weapon', 'card-potion'];
+
+// Replay viewer controller
+class ReplayViewer {
+    constructor(gameId, token) {
+        this.gameId = gameId;
+        this.token = token;
+        this.replay = null;
+        this.step = 0;
+
+        document.getElementById('first-btn').addEventListener('click', () => this.goTo(0));
+        document.getElementById('prev-btn').addEventListener('click', () => this.goTo(this.step - 1));
+        document.getElementById('next-btn').addEventListener('click', () => this.goTo(this.step + 1));
+        document.getElementById('last-btn').addEventListener('click', () => this.goTo(this.total()));
+        document.addEventListener('keydown', (event) => {
+            if (event.key === 'ArrowLeft') this.goTo(this.step - 1);
+            if (event.key === 'ArrowRight') this.goTo(this.step + 1);
+        });
+    }
+
+    // url fills in an endpoint, passing on the spectator token of a private game
+    url(endpoint, step) {
+        let url = endpoint.replace('{id}', encodeURIComponent(this.gameId)).replace('{step}', step);
+        if (this.token) {
+            url += '?token=' + encodeURIComponent(this.token);
+        }
+        return url;
+    }
+
+    total() {
+        return this.replay ? this.replay.actions.length : 0;
+    }
+
+    async load() {
+        const response = await fetch(this.url(REPLAY_API.REPLAY));
+        if (!response.ok) {
+            throw new Error(`Failed to load replay: ${response.status}`);
+        }
+        this.replay = await response.json();
+        this.renderMoveList();
+        await this.goTo(0);
+    }
+
+    async goTo(step) {
+        if (!this.replay || step < 0 || step > this.total()) {
+            return;
+        }
+
+        const response = await fetch(this.url(REPLAY_API.STEP, step));
+        if (!response.ok) {
+            throw new Error(`Failed to load move ${step}: ${response.status}`);
+        }
+        const data = await response.json();
+
+        this.step = step;
+        this.render(data.state);
+    }
+
+    // Rendering
+    render(state) {
+        document.getElementById('step-counter').textContent = `${this.step}/${this.total()}`;
+
+        const player = state.player;
+        const percent = Math.max(0, Math.min(100, (player.health / player.max_health) * 100));
+        document.getElementById('health-fill').style.width = `${percent}%`;
+        document.getElementById('health-value').textContent = `${player.health}/${player.max_health}`;
+
+        const weaponSlot = document.getElementById('weapon-slot');
+        weaponSlot.innerHTML = '';
+        if (player.equipped_weapon) {
+            weaponSlot.appendChild(this.createCardElement(player.equipped_weapon));
+        }
+
+        const monsters = document.getElementById('monsters-container');
+        monsters.innerHTML = '';
+        player.defeated_monsters.forEach(monster => monsters.appendChild(this.createCardElement(monster)));
+
+        document.getElementById('deck-count').textContent = state.deck.remaining_cards;
+
+        for (let i = 0; i < 4; i++) {
+            const slot = document.getElementById(`card-slot-${i}`);
+            slot.innerHTML = '';
+            if (state.room.cards[i]) {
+                slot.appendChild(this.createCardElement(state.room.cards[i]));
+            }
+        }
+
+        document.querySelectorAll('.log-entry').forEach((entry, index) => {
+            entry.style.fontWeight = index === this.step - 1 ? 'bold' : 'normal';
+        });
+    }
+
+    renderMoveList() {
+        const log = document.getElementById('log-container');
+        log.innerHTML = '';
+        this.replay.actions.forEach((action, index) => {
+            const entry = document.createElement('div');
+            entry.classList.add('log-entry');
+            entry.textContent = action.type === 'skip'
+                ? `${index + 1}. Skipped the room`
+                : `${index + 1}. ${action.type === 'play' ? 'Played' : 'Played barehanded'} card ${action.index}`;
+            entry.addEventListener('click', () => this.goTo(index + 1));
+            log.appendChild(entry);
+        });
+
+        const result = document.createElement('div');
+        result.classList.add('log-entry');
+        result.textContent = `Result: ${this.replay.state}, score ${this.replay.score}`;
+        log.appendChild(result);
+    }
+
+    createCardElement(card) {
+        const cardElement = document.createElement('div');
+        cardElement.classList.add('card', CARD_CLASSES[card.type]);
+
+        const cardCenter = document.createElement('div');
+        cardCenter.classList.add('card-center');
+        cardCenter.textContent = `${card.display} ${CARD_ICONS[card.type]}`;
+
+        cardElement.appendChild(cardCenter);
+        return cardElement;
+    }
+}
+
+// Initialize the viewer when the DOM is loaded
+document.addEventListener('DOMContentLoaded', () => {
+    const params = new URLSearchParams(window.location.search);
+    const gameId = params.get('id');
+    if (!gameId) {
+        document.getElementById('log-container').textContent = 'No replay selected. Open this page with ?id=<game id>.';
+        return;
+    }
+
+    const viewer = new ReplayViewer(gameId, params.get('token'));
+    viewer.load().catch(error => {
+        document.getElementById('log-container').textContent = error.message;
+    });
+});
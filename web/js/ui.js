@@ -16,6 +16,7 @@ class ScoundrelUI {
         document.getElementById('use-weapon-btn').addEventListener('click', () => this.resolveCombat(true));
         document.getElementById('fight-barehanded-btn').addEventListener('click', () => this.resolveCombat(false));
         document.getElementById('restart-game-btn').addEventListener('click', () => this.startNewGame());
+        document.getElementById('view-replay-btn').addEventListener('click', () => this.viewReplay());
 
         // Initialize skip room button state
         this.updateSkipRoomButton();
@@ -141,6 +142,13 @@ class ScoundrelUI {
         }
     }
 
+    // Open the replay of the finished game
+    viewReplay() {
+        if (this.game.gameId) {
+            window.location.href = `replay.html?id=${this.game.gameId}`;
+        }
+    }
+
     // Show game over screen
     showGameOverScreen(won) {
         const modal = document.getElementById('gameover-modal');